@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-admin/internal/config"
+
+	"github.com/urfave/cli/v2"
+)
+
+// This function creates a CLI command that prints the hash of a password, e.g. for the root password
+// in the configuration, with the configured password hash algorithm.
+func HashPasswordCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "hash-password",
+		Usage:     "Hash a password with the configured algorithm",
+		ArgsUsage: "<password>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "workdir",
+				Aliases:     []string{"d"},
+				Usage:       "Working directory",
+				DefaultText: "configs",
+				Value:       "configs",
+			},
+			&cli.StringFlag{
+				Name:        "config",
+				Aliases:     []string{"c"},
+				Usage:       "Runtime configuration files or directory (relative to workdir, multiple separated by commas)",
+				DefaultText: "dev",
+				Value:       "dev",
+			},
+			&cli.StringFlag{
+				Name:    "algorithm",
+				Aliases: []string{"a"},
+				Usage:   "Password hash algorithm (bcrypt/argon2id/scrypt), defaults to the configured one",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("password is required")
+			}
+
+			if err := config.Load(c.String("workdir"), strings.Split(c.String("config"), ",")...); err != nil {
+				return err
+			}
+			if v := c.String("algorithm"); v != "" {
+				config.C.General.PasswordHash.Algorithm = v
+			}
+			hasher, err := config.C.PasswordHasher()
+			if err != nil {
+				return err
+			}
+
+			encoded, err := hasher.Hash(c.Args().First())
+			if err != nil {
+				return err
+			}
+			fmt.Println(encoded)
+			return nil
+		},
+	}
+}
@@ -10,7 +10,9 @@ import (
 	"go-admin/internal/config"
 	"go-admin/internal/utility/prom"
 	"go-admin/internal/wirex"
+	"go-admin/pkg/crypto/hash"
 	"go-admin/pkg/logging"
+	"go-admin/pkg/mail"
 	"go-admin/pkg/util"
 	_ "go-admin/third_party/swagger"
 
@@ -57,6 +59,25 @@ func Run(ctx context.Context, runCfg RunConfig) error {
 		zap.String("static", staticDir),
 	)
 
+	// Initialize password hasher.
+	hasher, err := config.C.PasswordHasher()
+	if err != nil {
+		return err
+	}
+	hash.SetDefaultPasswordHasher(hasher)
+
+	// Initialize mail sender.
+	if cfg := config.C.Util.Mail; cfg.SmtpHost != "" {
+		mail.SetSender(&mail.SmtpSender{
+			SmtpHost: cfg.SmtpHost,
+			Port:     cfg.Port,
+			FromName: cfg.FromName,
+			FromMail: cfg.FromMail,
+			UserName: cfg.UserName,
+			AuthCode: cfg.AuthCode,
+		})
+	}
+
 	// Start pprof server.
 	if addr := config.C.General.PprofAddr; addr != "" {
 		logging.Context(ctx).Info("pprof server is listening on " + addr)
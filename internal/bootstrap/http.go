@@ -139,6 +139,12 @@ func useHTTPMiddlewares(_ context.Context, e *gin.Engine, injector *wirex.Inject
 		MaxContentLen:       config.C.Middleware.CopyBody.MaxContentLen,
 	}))
 
+	e.Use(middleware.TenantWithConfig(middleware.TenantConfig{
+		AllowedPathPrefixes: allowedPrefixes,
+		SkippedPathPrefixes: config.C.Middleware.Tenant.SkippedPathPrefixes,
+		HeaderKey:           config.C.Middleware.Tenant.HeaderKey,
+	}))
+
 	e.Use(middleware.AuthWithConfig(middleware.AuthConfig{
 		AllowedPathPrefixes: allowedPrefixes,
 		SkippedPathPrefixes: config.C.Middleware.Auth.SkippedPathPrefixes,
@@ -176,12 +182,15 @@ func useHTTPMiddlewares(_ context.Context, e *gin.Engine, injector *wirex.Inject
 			}
 			return false
 		},
-		GetEnforcer: func(c *gin.Context) *casbin.Enforcer {
+		GetEnforcer: func(c *gin.Context) *casbin.SyncedEnforcer {
 			return injector.Mods.Auth.Casbinx.GetEnforcer()
 		},
 		GetSubjects: func(c *gin.Context) []string {
 			return util.FromUserCache(c.Request.Context()).RoleIDs
 		},
+		GetDomain: func(c *gin.Context) string {
+			return util.FromUserCache(c.Request.Context()).TenantID
+		},
 	}))
 
 	if config.C.Util.Prometheus.Enable {
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 
+	"go-admin/pkg/crypto/hash"
 	"go-admin/pkg/encoding/json"
 	"go-admin/pkg/logging"
 )
@@ -41,14 +42,126 @@ type General struct {
 		ID       string `default:"root"`
 		Email    string `default:"admin@admin.com"`
 		FullName string `default:"Admin"`
-		Password string
+		Password string // Hash of the login password (bcrypt/argon2id/scrypt, see the hash-password command), legacy MD5 digests are still accepted
+	}
+	PasswordHash struct {
+		Algorithm         string `default:"bcrypt"` // bcrypt/argon2id/scrypt, stored hashes of other algorithms or parameters are upgraded on the next login
+		BcryptCost        int    `default:"10"`
+		Argon2Memory      uint32 `default:"65536"` // KiB
+		Argon2Iterations  uint32 `default:"3"`
+		Argon2Parallelism uint8  `default:"2"`
+		ScryptLogN        int    `default:"15"` // CPU/memory cost as power of two
+		ScryptR           int    `default:"8"`
+		ScryptP           int    `default:"1"`
+	}
+	ResetPassword struct {
+		TokenExp int    `default:"1800"`                                   // seconds
+		URL      string `default:"http://localhost:5001/#/reset-password"` // Reset password page, the token is appended as query parameter `token`
+	}
+	RegisterVerification struct {
+		Enable         bool   // Registered users stay inactive until their email is verified
+		TokenExp       int    `default:"86400"`                                // seconds
+		URL            string `default:"http://localhost:5001/#/verify-email"` // Verify email page, the token is appended as query parameter `token`
+		ResendInterval int    `default:"60"`                                   // seconds, minimum interval between two emails to the same address
+		MaxSendsPerDay int    `default:"5"`                                    // Maximum emails to the same address per day (0 means unlimited)
+	}
+	MFA struct {
+		Issuer        string // Issuer displayed in authenticator apps, defaults to AppName
+		SecretKey     string // AES key (16/24/32 bytes) to encrypt TOTP secrets, required to enroll
+		EnrollExp     int    `default:"600"` // seconds, time to confirm a new TOTP secret
+		ChallengeExp  int    `default:"300"` // seconds, time to complete the login challenge
+		MaxAttempts   int    `default:"5"`   // Failed codes allowed per login challenge
+		RecoveryCodes int    `default:"10"`  // Number of one-time recovery codes
+	}
+	Lockout struct {
+		MaxAttempts   int `default:"5"`     // Failed logins of an email before it is locked (0 means disabled)
+		MaxIPAttempts int `default:"20"`    // Failed logins from an IP before it is locked (0 means disabled)
+		Window        int `default:"900"`   // seconds, failed logins are forgotten after this time without failures
+		Duration      int `default:"300"`   // seconds, the first lockout, doubled by every consecutive lockout
+		MaxDuration   int `default:"86400"` // seconds, upper limit of the lockout duration
+	}
+	PasswordPolicy struct {
+		Enable        bool   // Check new passwords, clients must send plain passwords (over HTTPS) instead of MD5 hashes
+		MinLength     int    `default:"8"`
+		RequireUpper  bool   // Require an uppercase letter
+		RequireLower  bool   // Require a lowercase letter
+		RequireDigit  bool   // Require a digit
+		RequireSymbol bool   // Require a symbol
+		BannedFile    string // Banned passwords (one per line) in addition to the built-in common passwords, relative to the work dir
+		HistorySize   int    // Number of previous passwords that can't be reused (0 means disabled)
+		MaxAge        int    // days, expired passwords must be changed at the next login (0 means never)
+		ChangeExp     int    `default:"600"` // seconds, time to change the expired password after the login
+	}
+	OIDC struct {
+		LoginURL  string `default:"http://localhost:5001/#/login"`     // Login page, the login code or error is appended as query parameter `code` or `error`
+		BaseURL   string `default:"http://localhost:5001/api/v1/oidc"` // Public URL of the OIDC routes, the redirect URL of a provider is {BaseURL}/{Name}/callback
+		StateExp  int    `default:"600"`                               // seconds, time to complete the login at the provider
+		Providers []OIDCProvider
+	}
+	LDAP struct {
+		Enable             bool   // Login with the directory password, clients must send plain passwords (over HTTPS) instead of MD5 hashes
+		URL                string `default:"ldap://localhost:389"` // ldap://host:389 or ldaps://host:636
+		StartTLS           bool   // Upgrade ldap:// connections with StartTLS
+		InsecureSkipVerify bool   // Skip the verification of the server certificate
+		BindDN             string // Service account to search users, anonymous search if empty
+		BindPassword       string
+		BaseDN             string // Base DN of the user search, e.g. ou=people,dc=example,dc=com
+		UserFilter         string `default:"(&(objectClass=person)(mail=%s))"` // %s is replaced with the login email
+		FirstNameAttribute string `default:"givenName"`
+		LastNameAttribute  string `default:"sn"`
+		FullNameAttribute  string `default:"cn"`
+		GroupAttribute     string `default:"memberOf"` // Attribute of the user entry with the DNs of its groups
+		GroupRoles         []struct {
+			Group  string // DN of the group
+			RoleID string // From Role.ID
+		} // Roles of the group members, synchronized on every login, other roles of the users are kept
+		AutoCreate    bool // Create users on their first login
+		LocalFallback bool // Users not found in the directory login with their local password
+		Timeout       int  `default:"10"` // seconds
+	}
+	Impersonation struct {
+		TokenExp int `default:"1800"` // seconds, impersonation tokens can't be refreshed
+	}
+	RoleAssignment struct {
+		SweepInterval int `default:"60"` // seconds, the users whose time-bound roles started or ended are refreshed after this time at most
+	}
+	LoginHistory struct {
+		AlertMail bool // Email the users after logins from a new IP or user agent
+	}
+	WebAuthn struct {
+		RPID             string   `default:"localhost"` // Domain of the site, passkeys are bound to it and its subdomains
+		RPName           string   // Name displayed by the authenticators, defaults to AppName
+		Origins          []string `default:"[\"http://localhost:5001\"]"` // Origins of the admin pages, e.g. https://admin.example.com
+		ChallengeExp     int      `default:"300"`                         // seconds, time to complete the registration or login ceremony
+		UserVerification string   `default:"preferred"`                   // required/preferred/discouraged
+	}
+	UserImport struct {
+		MaxRows int `default:"1000"` // Maximum users of an import file
+	}
+	Invitation struct {
+		TokenExp int    `default:"604800"`                                    // seconds
+		URL      string `default:"http://localhost:5001/#/accept-invitation"` // Accept invitation page, the token is appended as query parameter `token`
 	}
 }
 
+// OpenID Connect identity provider
+type OIDCProvider struct {
+	Name           string // Unique name in the callback URL, e.g. google
+	DisplayName    string // Name of the login button
+	Issuer         string // Issuer URL, the discovery document is {Issuer}/.well-known/openid-configuration
+	ClientID       string
+	ClientSecret   string
+	Scopes         []string // Default openid, email and profile
+	AutoCreate     bool     // Create users on their first login
+	LinkByEmail    bool     // Link to existing users with the same verified email
+	DefaultRoleIDs []string // Roles of the created users
+}
+
 type Storage struct {
 	Cache struct {
 		Type      string `default:"memory"` // memory/badger/redis
 		Delimiter string `default:":"`      // delimiter for key
+		KeyPrefix string // prefix of the keys and pub/sub channels, empty by default, set a distinct one per app sharing one redis
 		Memory    struct {
 			CleanupInterval int `default:"60"` // seconds
 		}
@@ -95,6 +208,14 @@ type Util struct {
 			KeyPrefix string `default:"captcha:"`
 		}
 	}
+	Mail struct {
+		SmtpHost string // If empty, sending emails is disabled
+		Port     int    `default:"25"`
+		FromName string `default:"go-admin"`
+		FromMail string
+		UserName string
+		AuthCode string
+	}
 	Prometheus struct {
 		Enable         bool
 		Port           int    `default:"9100"`
@@ -147,6 +268,21 @@ func (c *Config) PreLoad() {
 	}
 }
 
+// Create the hasher of the new password hashes.
+func (c *Config) PasswordHasher() (hash.PasswordHasher, error) {
+	cfg := c.General.PasswordHash
+	return hash.NewPasswordHasher(hash.PasswordConfig{
+		Algorithm:         cfg.Algorithm,
+		BcryptCost:        cfg.BcryptCost,
+		Argon2Memory:      cfg.Argon2Memory,
+		Argon2Iterations:  cfg.Argon2Iterations,
+		Argon2Parallelism: cfg.Argon2Parallelism,
+		ScryptLogN:        cfg.ScryptLogN,
+		ScryptR:           cfg.ScryptR,
+		ScryptP:           cfg.ScryptP,
+	})
+}
+
 func (c *Config) Print() {
 	if c.General.DisablePrintConfig {
 		return
@@ -1,17 +1,41 @@
 package config
 
+import "time"
+
 const (
 	CacheNSForUser       = "user"
 	CacheNSForRole       = "role"
 	CacheNSForPermission = "permission"
+	CacheNSForCasbin     = "casbin"
+	CacheNSForResetPwd   = "reset-pwd"
+	CacheNSForVerify     = "verify-email"
+	CacheNSForMFA        = "mfa"
+	CacheNSForSession    = "session"
+	CacheNSForLockout    = "lockout"
+	CacheNSForChangePwd  = "change-pwd"
+	CacheNSForOIDC       = "oidc"
+	CacheNSForWebAuthn   = "webauthn"
 )
 
 const (
-	CacheKeyForSyncToCasbin = "sync:casbin"
+	CacheExpForSyncToCasbin = 24 * time.Hour // Keep role sync marks long enough for every instance to poll them
 )
 
 const (
 	ErrInvalidTokenID            = "com.invalid.token"
 	ErrInvalidCaptchaID          = "com.invalid.captcha"
 	ErrInvalidUsernameOrPassword = "com.invalid.username-or-password"
+	ErrInvalidResetPwdToken      = "com.invalid.reset-password-token"
+	ErrInvalidVerifyToken        = "com.invalid.verify-email-token"
+	ErrEmailNotVerified          = "com.email.not-verified"
+	ErrTooManyVerifyMails        = "com.too-many.verify-emails"
+	ErrInvalidMFAToken           = "com.invalid.mfa-token"
+	ErrInvalidMFACode            = "com.invalid.mfa-code"
+	ErrLoginLocked               = "com.login.locked"
+	ErrInvalidPassword           = "com.invalid.password"
+	ErrPasswordReused            = "com.password.reused"
+	ErrInvalidChangePwdToken     = "com.invalid.change-password-token"
+	ErrInvalidOIDCCode           = "com.invalid.oidc-code"
+	ErrInvalidPasskey            = "com.invalid.passkey"
+	ErrInvalidInviteToken        = "com.invalid.invite-token"
 )
@@ -28,6 +28,10 @@ type Middleware struct {
 		MaxOutputRequestBodyLen  int `default:"4096"`
 		MaxOutputResponseBodyLen int `default:"1024"`
 	}
+	Tenant struct {
+		SkippedPathPrefixes []string
+		HeaderKey           string `default:"X-Tenant-ID"` // tenant of the unauthenticated requests and of root users
+	}
 	CopyBody struct {
 		SkippedPathPrefixes []string
 		MaxContentLen       int64 `default:"33554432"` // max content length (default 32MB)
@@ -35,10 +39,11 @@ type Middleware struct {
 	Auth struct {
 		Disable             bool
 		SkippedPathPrefixes []string
-		SigningMethod       string `default:"HS512"`    // HS256/HS384/HS512
+		SigningMethod       string `default:"HS512"`    // HS256/HS384/HS512, or RS256/RS384/RS512/PS256/PS384/PS512/ES256/ES384/ES512/EdDSA with Keys
 		SigningKey          string `default:"XnEsT0S@"` // secret key
 		OldSigningKey       string // old secret key (for migration)
-		Expired             int    `default:"86400"` // seconds
+		Expired             int    `default:"86400"`  // seconds
+		RefreshExpired      int    `default:"604800"` // seconds, lifetime of refresh tokens
 		Store               struct {
 			Type      string `default:"memory"` // memory/badger/redis
 			Delimiter string `default:":"`      // delimiter for key
@@ -55,6 +60,14 @@ type Middleware struct {
 				DB       int
 			}
 		}
+		// Asymmetric signing keys, tokens are signed by the first key with a private key and the other keys only verify tokens.
+		// If set, the SigningKey is ignored and only the OldSigningKey is still accepted (for migration).
+		Keys []struct {
+			ID             string // kid header of tokens
+			Method         string // default SigningMethod
+			PrivateKeyFile string // PEM file, relative to the work dir
+			PublicKeyFile  string // PEM file, relative to the work dir (for keys without private key)
+		}
 	}
 	RateLimiter struct {
 		Enable              bool
@@ -80,9 +93,9 @@ type Middleware struct {
 		Disable             bool
 		SkippedPathPrefixes []string
 		LoadThread          int    `default:"2"`
-		AutoLoadInterval    int    `default:"3"` // seconds
+		AutoLoadInterval    int    `default:"3"`  // seconds, only used when the cache does not support pub/sub
+		ResyncInterval      int    `default:"60"` // seconds, backstop poll when the changes are pushed via pub/sub
 		ModelFile           string `default:"rbac_model.conf"`
-		GenPolicyFile       string `default:"gen_rbac_policy.csv"`
 	}
 	Static struct {
 		Dir string // Static files directory (From command arguments)
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"net/http"
+
 	"go-admin/internal/modules/auth/model"
 	"go-admin/internal/modules/auth/service"
 	"go-admin/pkg/util"
@@ -50,7 +52,7 @@ func (a *Auth) ResponseCaptcha(c *gin.Context) {
 // @Failure 500 {object} util.ResponseResult
 // @Router /api/v1/login [post]
 func (a *Auth) Login(c *gin.Context) {
-	ctx := c.Request.Context()
+	ctx := util.GetClientContext(c)
 	item := new(model.LoginForm)
 	if err := util.ParseJSON(c, item); err != nil {
 		util.ResError(c, err)
@@ -66,14 +68,130 @@ func (a *Auth) Login(c *gin.Context) {
 }
 
 // @Tags AuthAPI
-// @Summary Login system with username and password
+// @Summary Complete the two-factor login with a TOTP code or a recovery code
+// @Param body body model.LoginMFAForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.LoginToken}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/login/mfa [post]
+func (a *Auth) LoginMFA(c *gin.Context) {
+	ctx := util.GetClientContext(c)
+	item := new(model.LoginMFAForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	data, err := a.AuthService.LoginMFA(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "Login Successfully")
+}
+
+// @Tags AuthAPI
+// @Summary Start the login with a passkey, the options are passed to navigator.credentials.get()
+// @Param body body model.PasskeyLoginBeginForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.PasskeyChallenge{options=webauthn.RequestOptions}}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/login/passkey/begin [post]
+func (a *Auth) PasskeyLoginBegin(c *gin.Context) {
+	ctx := util.GetClientContext(c)
+	item := new(model.PasskeyLoginBeginForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	data, err := a.AuthService.PasskeyLoginBegin(ctx, item)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags AuthAPI
+// @Summary Complete the login with the assertion of the passkey
+// @Param body body model.PasskeyLoginFinishForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.LoginToken}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/login/passkey/finish [post]
+func (a *Auth) PasskeyLoginFinish(c *gin.Context) {
+	ctx := util.GetClientContext(c)
+	item := new(model.PasskeyLoginFinishForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	data, err := a.AuthService.PasskeyLoginFinish(ctx, item)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "Login Successfully")
+}
+
+// @Tags AuthAPI
+// @Summary Change the expired password returned by the login and complete the login
+// @Param body body model.LoginChangePasswordForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.LoginToken}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/login/change-password [post]
+func (a *Auth) LoginChangePassword(c *gin.Context) {
+	ctx := util.GetClientContext(c)
+	item := new(model.LoginChangePasswordForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	data, err := a.AuthService.LoginChangePassword(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "Login Successfully")
+}
+
+// @Tags AuthAPI
+// @Summary Exchange the refresh token for a new access token and refresh token
+// @Param body body model.RefreshTokenForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.LoginToken}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/refresh-token [post]
+func (a *Auth) RefreshToken(c *gin.Context) {
+	ctx := util.GetClientContext(c)
+	item := new(model.RefreshTokenForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	data, err := a.AuthService.RefreshToken(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags AuthAPI
+// @Summary Register a user, it stays inactive until the email is verified when verification is enabled
 // @Param body body model.RegisterForm true "Request body"
 // @Success 200 {object} util.ResponseResult{data=model.LoginToken}
 // @Failure 400 {object} util.ResponseResult
 // @Failure 500 {object} util.ResponseResult
 // @Router /api/v1/register [post]
 func (a *Auth) Register(c *gin.Context) {
-	ctx := c.Request.Context()
+	ctx := util.GetClientContext(c)
 	item := new(model.RegisterForm)
 	if err := util.ParseJSON(c, item); err != nil {
 		util.ResError(c, err)
@@ -83,19 +201,122 @@ func (a *Auth) Register(c *gin.Context) {
 	if err != nil {
 		util.ResError(c, err)
 		return
+	} else if data == nil {
+		util.ResSuccess(c, nil, "Register Successfully, please check your inbox to verify the email")
+		return
 	}
 	util.ResSuccess(c, data, "Register Successfully")
 }
 
 // @Tags AuthAPI
-// @Security ApiKeyAuth
-// @Summary Logout system
+// @Summary Accept the invitation with the name and password of the new user
+// @Param body body model.AcceptInvitationForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.LoginToken}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/accept-invitation [post]
+func (a *Auth) AcceptInvitation(c *gin.Context) {
+	ctx := util.GetClientContext(c)
+	item := new(model.AcceptInvitationForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	data, err := a.AuthService.AcceptInvitation(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "Accept Invitation Successfully")
+}
+
+// @Tags AuthAPI
+// @Summary Verify the registered email and activate the user
+// @Param body body model.VerifyEmailForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.LoginToken}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/verify-email [post]
+func (a *Auth) VerifyEmail(c *gin.Context) {
+	ctx := util.GetClientContext(c)
+	item := new(model.VerifyEmailForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	data, err := a.AuthService.VerifyEmail(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "Verify Email Successfully")
+}
+
+// @Tags AuthAPI
+// @Summary Send the verification email again
+// @Param body body model.ResendVerificationForm true "Request body"
 // @Success 200 {object} util.ResponseResult
+// @Failure 400 {object} util.ResponseResult
+// @Failure 429 {object} util.ResponseResult
 // @Failure 500 {object} util.ResponseResult
-// @Router /api/v1/current/logout [post]
-func (a *Auth) Logout(c *gin.Context) {
+// @Router /api/v1/resend-verification [post]
+func (a *Auth) ResendVerification(c *gin.Context) {
 	ctx := c.Request.Context()
-	err := a.AuthService.Logout(ctx)
+	item := new(model.ResendVerificationForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	err := a.AuthService.ResendVerification(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags AuthAPI
+// @Summary Send a reset password email to the user
+// @Param body body model.ForgotPasswordForm true "Request body"
+// @Success 200 {object} util.ResponseResult
+// @Failure 400 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/forgot-password [post]
+func (a *Auth) ForgotPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	item := new(model.ForgotPasswordForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	err := a.AuthService.ForgotPassword(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags AuthAPI
+// @Summary Reset the user password with the token from the reset password email
+// @Param body body model.ResetPasswordForm true "Request body"
+// @Success 200 {object} util.ResponseResult
+// @Failure 400 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/reset-password [post]
+func (a *Auth) ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	item := new(model.ResetPasswordForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	err := a.AuthService.ResetPassword(ctx, item.Trim())
 	if err != nil {
 		util.ResError(c, err)
 		return
@@ -105,19 +326,18 @@ func (a *Auth) Logout(c *gin.Context) {
 
 // @Tags AuthAPI
 // @Security ApiKeyAuth
-// @Summary Refresh current access token
-// @Success 200 {object} util.ResponseResult{data=model.LoginToken}
-// @Failure 401 {object} util.ResponseResult
+// @Summary Logout system
+// @Success 200 {object} util.ResponseResult
 // @Failure 500 {object} util.ResponseResult
-// @Router /api/v1/current/refresh-token [post]
-func (a *Auth) RefreshToken(c *gin.Context) {
+// @Router /api/v1/current/logout [post]
+func (a *Auth) Logout(c *gin.Context) {
 	ctx := c.Request.Context()
-	data, err := a.AuthService.RefreshToken(ctx)
+	err := a.AuthService.Logout(ctx)
 	if err != nil {
 		util.ResError(c, err)
 		return
 	}
-	util.ResSuccess(c, data, "")
+	util.ResOK(c)
 }
 
 // @Tags AuthAPI
@@ -203,3 +423,346 @@ func (a *Auth) UpdateUser(c *gin.Context) {
 	}
 	util.ResOK(c)
 }
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Start the two-factor authentication enrollment of the current user
+// @Success 200 {object} util.ResponseResult{data=model.MFAEnrollment}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/mfa/enroll [post]
+func (a *Auth) EnrollMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	data, err := a.AuthService.EnrollMFA(ctx)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Confirm the enrollment with a TOTP code and enable two-factor authentication
+// @Param body body model.MFACodeForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.MFARecoveryCodes}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/mfa/confirm [post]
+func (a *Auth) ConfirmMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	item := new(model.MFACodeForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	data, err := a.AuthService.ConfirmMFA(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "Two-factor authentication enabled")
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Disable two-factor authentication of the current user
+// @Param body body model.MFACodeForm true "Request body"
+// @Success 200 {object} util.ResponseResult
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/mfa/disable [post]
+func (a *Auth) DisableMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	item := new(model.MFACodeForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	err := a.AuthService.DisableMFA(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Query the login sessions of the current user
+// @Success 200 {object} util.ResponseResult{data=[]model.Session}
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/sessions [get]
+func (a *Auth) QuerySessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	data, err := a.AuthService.QuerySessions(ctx)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Revoke a login session of the current user
+// @Param id path string true "Session ID"
+// @Success 200 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 404 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/sessions/{id} [delete]
+func (a *Auth) RevokeSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.AuthService.RevokeSession(ctx, c.Param("id"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Revoke all login sessions of the current user except the current one
+// @Success 200 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/sessions [delete]
+func (a *Auth) RevokeOtherSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.AuthService.RevokeOtherSessions(ctx)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Query the API keys of the current user
+// @Success 200 {object} util.ResponseResult{data=[]model.APIKey}
+// @Failure 401 {object} util.ResponseResult
+// @Failure 403 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/api-keys [get]
+func (a *Auth) QueryAPIKeys(c *gin.Context) {
+	ctx := c.Request.Context()
+	data, err := a.AuthService.QueryAPIKeys(ctx)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Create an API key for the current user, the key is only returned once
+// @Param body body model.APIKeyForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.APIKey}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 403 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/api-keys [post]
+func (a *Auth) CreateAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	item := new(model.APIKeyForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	data, err := a.AuthService.CreateAPIKey(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Revoke an API key of the current user
+// @Param id path string true "API key ID"
+// @Success 200 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 403 {object} util.ResponseResult
+// @Failure 404 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/api-keys/{id} [delete]
+func (a *Auth) RevokeAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.AuthService.RevokeAPIKey(ctx, c.Param("id"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Query the login history of the current user
+// @Param current query int true "pagination index" default(1)
+// @Param pageSize query int true "pagination size" default(10)
+// @Param success query bool false "Login succeeded"
+// @Success 200 {object} util.ResponseResult{data=[]model.LoginHistory}
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/login-history [get]
+func (a *Auth) QueryLoginHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params model.LoginHistoryQueryParam
+	if err := util.ParseQuery(c, &params); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	result, err := a.AuthService.QueryLoginHistory(ctx, params)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResPage(c, result.Data, result.PageResult)
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Query the passkeys of the current user
+// @Success 200 {object} util.ResponseResult{data=[]model.Passkey}
+// @Failure 401 {object} util.ResponseResult
+// @Failure 403 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/passkeys [get]
+func (a *Auth) QueryPasskeys(c *gin.Context) {
+	ctx := c.Request.Context()
+	data, err := a.AuthService.QueryPasskeys(ctx)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Start the registration of a passkey, the options are passed to navigator.credentials.create()
+// @Success 200 {object} util.ResponseResult{data=model.PasskeyChallenge{options=webauthn.CreationOptions}}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 403 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/passkeys/begin [post]
+func (a *Auth) BeginPasskeyRegistration(c *gin.Context) {
+	ctx := c.Request.Context()
+	data, err := a.AuthService.BeginPasskeyRegistration(ctx)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Complete the registration of a passkey with the credential created by the authenticator
+// @Param body body model.PasskeyRegisterForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.Passkey}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 403 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/passkeys [post]
+func (a *Auth) FinishPasskeyRegistration(c *gin.Context) {
+	ctx := c.Request.Context()
+	item := new(model.PasskeyRegisterForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	data, err := a.AuthService.FinishPasskeyRegistration(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Delete a passkey of the current user
+// @Param id path string true "Passkey ID"
+// @Success 200 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 403 {object} util.ResponseResult
+// @Failure 404 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/passkeys/{id} [delete]
+func (a *Auth) DeletePasskey(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.AuthService.DeletePasskey(ctx, c.Param("id"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary Login as the user with a short-lived access token to reproduce what the user sees
+// @Param id path string true "unique id"
+// @Success 200 {object} util.ResponseResult{data=model.LoginToken}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 403 {object} util.ResponseResult
+// @Failure 404 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/users/{id}/impersonate [post]
+func (a *Auth) Impersonate(c *gin.Context) {
+	ctx := c.Request.Context()
+	data, err := a.AuthService.Impersonate(ctx, c.Param("id"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags AuthAPI
+// @Security ApiKeyAuth
+// @Summary End the impersonation, the impersonation access token becomes invalid
+// @Success 200 {object} util.ResponseResult
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/current/impersonation [delete]
+func (a *Auth) EndImpersonation(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.AuthService.EndImpersonation(ctx)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags AuthAPI
+// @Summary Get the public keys to verify access tokens (JWK Set)
+// @Success 200 {object} jwtx.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (a *Auth) JWKS(c *gin.Context) {
+	ctx := c.Request.Context()
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, a.AuthService.JWKS(ctx))
+}
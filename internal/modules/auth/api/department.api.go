@@ -0,0 +1,158 @@
+package api
+
+import (
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/service"
+	"go-admin/pkg/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Department management of the organization
+type Department struct {
+	DepartmentService *service.Department
+}
+
+// @Tags DepartmentAPI
+// @Security ApiKeyAuth
+// @Summary Query department tree data
+// @Param name query string false "Name of department"
+// @Param status query string false "Status of department (disabled, enabled)"
+// @Success 200 {object} util.ResponseResult{data=[]model.Department}
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/departments [get]
+func (a *Department) Query(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params model.DepartmentQueryParam
+	if err := util.ParseQuery(c, &params); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	result, err := a.DepartmentService.Query(ctx, params)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResPage(c, result.Data, result.PageResult)
+}
+
+// @Tags DepartmentAPI
+// @Security ApiKeyAuth
+// @Summary Get department record by ID
+// @Param id path string true "unique id"
+// @Success 200 {object} util.ResponseResult{data=model.Department}
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/departments/{id} [get]
+func (a *Department) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+	item, err := a.DepartmentService.Get(ctx, c.Param("id"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, item, "")
+}
+
+// @Tags DepartmentAPI
+// @Security ApiKeyAuth
+// @Summary Create department record
+// @Param body body model.DepartmentForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.Department}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/departments [post]
+func (a *Department) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+	item := new(model.DepartmentForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	} else if err := item.Validate(); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	result, err := a.DepartmentService.Create(ctx, item)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, result, "")
+}
+
+// @Tags DepartmentAPI
+// @Security ApiKeyAuth
+// @Summary Update department record by ID
+// @Param id path string true "unique id"
+// @Param body body model.DepartmentForm true "Request body"
+// @Success 200 {object} util.ResponseResult
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/departments/{id} [put]
+func (a *Department) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	item := new(model.DepartmentForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	} else if err := item.Validate(); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	err := a.DepartmentService.Update(ctx, c.Param("id"), item)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags DepartmentAPI
+// @Security ApiKeyAuth
+// @Summary Delete department record by ID
+// @Param id path string true "unique id"
+// @Success 200 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/departments/{id} [delete]
+func (a *Department) Delete(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.DepartmentService.Delete(ctx, c.Param("id"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags DepartmentAPI
+// @Security ApiKeyAuth
+// @Summary Move department record to another parent or position by ID
+// @Param id path string true "unique id"
+// @Param body body model.DepartmentMoveForm true "Request body"
+// @Success 200 {object} util.ResponseResult
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/departments/{id}/move [patch]
+func (a *Department) Move(c *gin.Context) {
+	ctx := c.Request.Context()
+	item := new(model.DepartmentMoveForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	err := a.DepartmentService.Move(ctx, c.Param("id"), item)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
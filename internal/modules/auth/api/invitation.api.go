@@ -0,0 +1,104 @@
+package api
+
+import (
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/service"
+	"go-admin/pkg/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Invitations of users by email
+type Invitation struct {
+	InvitationService *service.Invitation
+}
+
+// @Tags InvitationAPI
+// @Security ApiKeyAuth
+// @Summary Query invitation list
+// @Param current query int true "pagination index" default(1)
+// @Param pageSize query int true "pagination size" default(10)
+// @Param email query string false "Email of the invitee"
+// @Param status query string false "Status of invitation (pending, accepted, revoked)"
+// @Success 200 {object} util.ResponseResult{data=[]model.Invitation}
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/invitations [get]
+func (a *Invitation) Query(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params model.InvitationQueryParam
+	if err := util.ParseQuery(c, &params); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	result, err := a.InvitationService.Query(ctx, params)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResPage(c, result.Data, result.PageResult)
+}
+
+// @Tags InvitationAPI
+// @Security ApiKeyAuth
+// @Summary Invite a user by email with pre-assigned roles
+// @Param body body model.InvitationForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.Invitation}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/invitations [post]
+func (a *Invitation) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+	item := new(model.InvitationForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	result, err := a.InvitationService.Create(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, result, "")
+}
+
+// @Tags InvitationAPI
+// @Security ApiKeyAuth
+// @Summary Resend the pending invitation with a new invite token
+// @Param id path string true "unique id"
+// @Success 200 {object} util.ResponseResult
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/invitations/{id}/resend [post]
+func (a *Invitation) Resend(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.InvitationService.Resend(ctx, c.Param("id"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags InvitationAPI
+// @Security ApiKeyAuth
+// @Summary Revoke the pending invitation by ID
+// @Param id path string true "unique id"
+// @Success 200 {object} util.ResponseResult
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/invitations/{id} [delete]
+func (a *Invitation) Revoke(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.InvitationService.Revoke(ctx, c.Param("id"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/service"
+	"go-admin/pkg/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Single sign-on with external OpenID Connect providers
+type OIDC struct {
+	OIDCService *service.OIDC
+}
+
+// @Tags OIDCAPI
+// @Summary Query the identity providers to login with
+// @Success 200 {object} util.ResponseResult{data=[]model.OIDCProvider}
+// @Router /api/v1/oidc/providers [get]
+func (a *OIDC) Providers(c *gin.Context) {
+	ctx := c.Request.Context()
+	util.ResSuccess(c, a.OIDCService.Providers(ctx), "Query Identity Providers Successfully")
+}
+
+// @Tags OIDCAPI
+// @Summary Redirect to the identity provider to login
+// @Param provider path string true "Name of the identity provider"
+// @Success 302 "Redirect to the authorization endpoint of the provider"
+// @Failure 404 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/oidc/{provider}/authorize [get]
+func (a *OIDC) Authorize(c *gin.Context) {
+	ctx := util.GetClientContext(c)
+	redirectURL, err := a.OIDCService.AuthCodeURL(ctx, c.Param("provider"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+// @Tags OIDCAPI
+// @Summary Callback of the identity provider, redirect to the login page with the login code or error
+// @Param provider path string true "Name of the identity provider"
+// @Param code query string false "Authorization code"
+// @Param state query string true "Login state"
+// @Param error query string false "Error of the provider"
+// @Success 302 "Redirect to the login page"
+// @Router /api/v1/oidc/{provider}/callback [get]
+func (a *OIDC) Callback(c *gin.Context) {
+	ctx := util.GetClientContext(c)
+	code, err := a.OIDCService.Callback(ctx, c.Param("provider"), c.Request.URL.Query())
+	c.Redirect(http.StatusFound, a.OIDCService.LoginURL(ctx, code, err))
+}
+
+// @Tags OIDCAPI
+// @Summary Login with the login code of the identity provider callback
+// @Param body body model.OIDCLoginForm true "Request body"
+// @Success 200 {object} util.ResponseResult{data=model.LoginToken}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/login/oidc [post]
+func (a *OIDC) Login(c *gin.Context) {
+	ctx := util.GetClientContext(c)
+	item := new(model.OIDCLoginForm)
+	if err := util.ParseJSON(c, item); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	data, err := a.OIDCService.Login(ctx, item.Trim())
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "Login Successfully")
+}
@@ -1,11 +1,19 @@
 package api
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
 	"go-admin/internal/modules/auth/model"
 	"go-admin/internal/modules/auth/service"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/logging"
 	"go-admin/pkg/util"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // User management for RBAC
@@ -21,6 +29,7 @@ type User struct {
 // @Param username query string false "Username for login"
 // @Param name query string false "Name of user"
 // @Param status query string false "Status of user (active, inactive)"
+// @Param department_id query string false "Members of the department and its sub-departments"
 // @Success 200 {object} util.ResponseResult{data=[]model.User}
 // @Failure 401 {object} util.ResponseResult
 // @Failure 500 {object} util.ResponseResult
@@ -151,3 +160,228 @@ func (a *User) ResetPassword(c *gin.Context) {
 	}
 	util.ResOK(c)
 }
+
+// @Tags UserAPI
+// @Security ApiKeyAuth
+// @Summary Unlock the login of the user locked by too many failed logins
+// @Param id path string true "unique id"
+// @Success 200 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/users/{id}/unlock [patch]
+func (a *User) Unlock(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.UserService.Unlock(ctx, c.Param("id"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags UserAPI
+// @Security ApiKeyAuth
+// @Summary Query the login sessions of the user
+// @Param id path string true "unique id"
+// @Success 200 {object} util.ResponseResult{data=[]model.Session}
+// @Failure 401 {object} util.ResponseResult
+// @Failure 404 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/users/{id}/sessions [get]
+func (a *User) QuerySessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	data, err := a.UserService.QuerySessions(ctx, c.Param("id"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags UserAPI
+// @Security ApiKeyAuth
+// @Summary Revoke all login sessions of the user
+// @Param id path string true "unique id"
+// @Success 200 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/users/{id}/sessions [delete]
+func (a *User) RevokeSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.UserService.RevokeSessions(ctx, c.Param("id"), "")
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags UserAPI
+// @Security ApiKeyAuth
+// @Summary Revoke a login session of the user
+// @Param id path string true "unique id"
+// @Param sid path string true "Session ID"
+// @Success 200 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 404 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/users/{id}/sessions/{sid} [delete]
+func (a *User) RevokeSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.UserService.RevokeSessions(ctx, c.Param("id"), c.Param("sid"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags UserAPI
+// @Security ApiKeyAuth
+// @Summary Query the login history of the user
+// @Param id path string true "unique id"
+// @Param current query int true "pagination index" default(1)
+// @Param pageSize query int true "pagination size" default(10)
+// @Param success query bool false "Login succeeded"
+// @Success 200 {object} util.ResponseResult{data=[]model.LoginHistory}
+// @Failure 401 {object} util.ResponseResult
+// @Failure 404 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/users/{id}/login-history [get]
+func (a *User) QueryLoginHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params model.LoginHistoryQueryParam
+	if err := util.ParseQuery(c, &params); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	result, err := a.UserService.QueryLoginHistory(ctx, c.Param("id"), params)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResPage(c, result.Data, result.PageResult)
+}
+
+// @Tags UserAPI
+// @Security ApiKeyAuth
+// @Summary Query the API keys of the user
+// @Param id path string true "unique id"
+// @Success 200 {object} util.ResponseResult{data=[]model.APIKey}
+// @Failure 401 {object} util.ResponseResult
+// @Failure 404 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/users/{id}/api-keys [get]
+func (a *User) QueryAPIKeys(c *gin.Context) {
+	ctx := c.Request.Context()
+	data, err := a.UserService.QueryAPIKeys(ctx, c.Param("id"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, data, "")
+}
+
+// @Tags UserAPI
+// @Security ApiKeyAuth
+// @Summary Revoke an API key of the user
+// @Param id path string true "unique id"
+// @Param kid path string true "API key ID"
+// @Success 200 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 404 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/users/{id}/api-keys/{kid} [delete]
+func (a *User) RevokeAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	err := a.UserService.RevokeAPIKey(ctx, c.Param("id"), c.Param("kid"))
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResOK(c)
+}
+
+// @Tags UserAPI
+// @Security ApiKeyAuth
+// @Summary Import users from a CSV or XLSX file, the users get the default login password
+// @Accept multipart/form-data
+// @Param file formData file true "CSV or XLSX file, the header is email, first_name, last_name, phone, status and roles (role codes separated by ;)"
+// @Param dry_run query bool false "Only validate the rows, no user is created"
+// @Success 200 {object} util.ResponseResult{data=model.UserImportResult}
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/users/import [post]
+func (a *User) Import(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params model.UserImportParam
+	if err := util.ParseQuery(c, &params); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		util.ResError(c, errors.BadRequest("", "Failed to parse file: %s", err.Error()))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	defer file.Close()
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	result, err := a.UserService.Import(ctx, format, file, fileHeader.Size, params.DryRun)
+	if err != nil {
+		util.ResError(c, err)
+		return
+	}
+	util.ResSuccess(c, result, "")
+}
+
+// @Tags UserAPI
+// @Security ApiKeyAuth
+// @Summary Export users to a CSV or XLSX file with the filters of the user list
+// @Param email query string false "Email for login"
+// @Param full_name query string false "Full Name of user"
+// @Param status query string false "Status of user (active, inactive)"
+// @Param department_id query string false "Members of the department and its sub-departments"
+// @Param format query string false "File format (csv, xlsx)" default(csv)
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 "User file"
+// @Failure 400 {object} util.ResponseResult
+// @Failure 401 {object} util.ResponseResult
+// @Failure 500 {object} util.ResponseResult
+// @Router /api/v1/users/export [get]
+func (a *User) Export(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params model.UserExportParam
+	if err := util.ParseQuery(c, &params); err != nil {
+		util.ResError(c, err)
+		return
+	}
+
+	if params.Format == "" {
+		params.Format = model.UserFileFormatCSV
+	}
+	contentType := "text/csv; charset=utf-8"
+	if params.Format == model.UserFileFormatXLSX {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="users-%s.%s"`, time.Now().Format("20060102"), params.Format))
+
+	if err := a.UserService.Export(ctx, params, c.Writer); err != nil {
+		// the error can't be responded after the file is partly sent
+		if c.Writer.Written() {
+			logging.Context(ctx).Error("Failed to export users", zap.Error(err))
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		util.ResError(c, err)
+	}
+}
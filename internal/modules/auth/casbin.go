@@ -1,15 +1,9 @@
 package auth
 
 import (
-	"bytes"
 	"context"
-	"fmt"
-	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
-	"sync"
-	"sync/atomic"
 	"time"
 
 	"go-admin/internal/config"
@@ -25,24 +19,19 @@ import (
 
 // Load rbac permissions to casbin
 type Casbinx struct {
-	enforcer         *atomic.Value `wire:"-"`
-	ticker           *time.Ticker  `wire:"-"`
-	Cache            cachex.Cacher
-	MenuRepo         *repo.Menu
-	MenuResourceRepo *repo.MenuResource
-	RoleRepo         *repo.Role
+	enforcer           *casbin.SyncedEnforcer `wire:"-"`
+	ticker             *time.Ticker           `wire:"-"`
+	cancel             context.CancelFunc     `wire:"-"`
+	Cache              cachex.Cacher
+	MenuRepo           *repo.Menu
+	MenuResourceRepo   *repo.MenuResource
+	RoleRepo           *repo.Role
+	PermissionRepo     *repo.Permission
+	RolePermissionRepo *repo.RolePermission
 }
 
-func (a *Casbinx) GetEnforcer() *casbin.Enforcer {
-	if v := a.enforcer.Load(); v != nil {
-		return v.(*casbin.Enforcer)
-	}
-	return nil
-}
-
-type policyQueueItem struct {
-	RoleID    string
-	Resources model.MenuResources
+func (a *Casbinx) GetEnforcer() *casbin.SyncedEnforcer {
+	return a.enforcer
 }
 
 func (a *Casbinx) Load(ctx context.Context) error {
@@ -50,92 +39,118 @@ func (a *Casbinx) Load(ctx context.Context) error {
 		return nil
 	}
 
-	a.enforcer = new(atomic.Value)
-	if err := a.load(ctx); err != nil {
+	modelFile := filepath.Join(config.C.General.WorkDir, config.C.Middleware.Casbin.ModelFile)
+	e, err := casbin.NewSyncedEnforcer(modelFile, newPolicyAdapter(ctx, a))
+	if err != nil {
+		logging.Context(ctx).Error("Failed to create casbin enforcer", zap.Error(err))
 		return err
 	}
+	e.EnableAutoSave(false)
+	e.EnableLog(config.C.IsDebug())
+	a.enforcer = e
 
+	ctx, a.cancel = context.WithCancel(ctx)
+	interval := config.C.Middleware.Casbin.AutoLoadInterval
+	if notifier, ok := a.Cache.(cachex.Notifier); ok {
+		if err := a.subscribe(ctx, notifier); err != nil {
+			return err
+		}
+		// pushed messages may be lost, a slow poll of the sync marks catches up with them
+		interval = config.C.Middleware.Casbin.ResyncInterval
+	}
+	a.ticker = time.NewTicker(time.Duration(interval) * time.Second)
 	go a.autoLoad(ctx)
 	return nil
 }
 
-func (a *Casbinx) load(ctx context.Context) error {
-	start := time.Now()
-	roleResult, err := a.RoleRepo.Query(ctx, model.RoleQueryParam{
-		Status: model.RoleStatusEnabled,
-	}, model.RoleQueryOptions{
-		QueryOptions: util.QueryOptions{SelectFields: []string{"id"}},
-	})
+// Query the policy rules (sub, dom, obj, act) granted to the role by its menus and permissions,
+// the domain is the tenant of the role.
+func (a *Casbinx) queryRolePolicies(ctx context.Context, role *model.Role) ([][]string, error) {
+	roleID := role.ID
+	resources, err := a.queryRoleResources(ctx, roleID)
 	if err != nil {
-		return err
-	} else if len(roleResult.Data) == 0 {
-		return nil
+		return nil, err
 	}
-
-	var resCount int32
-	queue := make(chan *policyQueueItem, len(roleResult.Data))
-	threadNum := config.C.Middleware.Casbin.LoadThread
-	lock := new(sync.Mutex)
-	buf := new(bytes.Buffer)
-
-	wg := new(sync.WaitGroup)
-	wg.Add(threadNum)
-	for i := 0; i < threadNum; i++ {
-		go func() {
-			defer wg.Done()
-			ibuf := new(bytes.Buffer)
-			for item := range queue {
-				for _, res := range item.Resources {
-					_, _ = ibuf.WriteString(fmt.Sprintf("p, %s, %s, %s \n", item.RoleID, res.Path, res.Method))
-				}
-			}
-			lock.Lock()
-			_, _ = buf.Write(ibuf.Bytes())
-			lock.Unlock()
-		}()
+	permissions, err := a.queryRolePermissions(ctx, roleID)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, item := range roleResult.Data {
-		resources, err := a.queryRoleResources(ctx, item.ID)
-		if err != nil {
-			logging.Context(ctx).Error("Failed to query role resources", zap.Error(err))
-			continue
+	var rules [][]string
+	ruleMapper := make(map[string]struct{})
+	addRule := func(path, method string) {
+		key := path + " " + method
+		if _, ok := ruleMapper[key]; ok {
+			return
 		}
-		atomic.AddInt32(&resCount, int32(len(resources)))
-		queue <- &policyQueueItem{
-			RoleID:    item.ID,
-			Resources: resources,
+		ruleMapper[key] = struct{}{}
+		rules = append(rules, []string{roleID, role.TenantID, path, method})
+	}
+
+	for _, res := range resources {
+		addRule(res.Path, res.Method)
+	}
+	for _, perm := range permissions {
+		for _, method := range perm.SplitHttpMethods() {
+			addRule(perm.HttpPath, method)
 		}
 	}
-	close(queue)
-	wg.Wait()
+	return rules, nil
+}
+
+// The grouping rules (role, parent, dom) of the role, the role inherits the policy rules of its parents.
+func roleGroupingPolicies(role *model.Role) [][]string {
+	var rules [][]string
+	for _, parentID := range role.ParentIDs {
+		rules = append(rules, []string{role.ID, parentID, role.TenantID})
+	}
+	return rules
+}
+
+// Replace the policy and grouping rules of the role in the enforcer, removes them if the role is deleted or disabled.
+func (a *Casbinx) reloadRole(ctx context.Context, roleID string) error {
+	role, err := a.RoleRepo.Get(ctx, roleID, model.RoleQueryOptions{
+		QueryOptions: util.QueryOptions{SelectFields: []string{"id", "tenant_id", "status", "parent_ids"}},
+	})
+	if err != nil {
+		return err
+	}
 
-	if buf.Len() > 0 {
-		policyFile := filepath.Join(config.C.General.WorkDir, config.C.Middleware.Casbin.GenPolicyFile)
-		_ = os.Rename(policyFile, policyFile+".bak")
-		_ = os.MkdirAll(filepath.Dir(policyFile), 0755)
-		if err := os.WriteFile(policyFile, buf.Bytes(), 0666); err != nil {
-			logging.Context(ctx).Error("Failed to write policy file", zap.Error(err))
+	var rules, groupingRules [][]string
+	if role != nil && role.Status == model.RoleStatusEnabled {
+		rules, err = a.queryRolePolicies(ctx, role)
+		if err != nil {
 			return err
 		}
-		// set readonly
-		_ = os.Chmod(policyFile, 0444)
+		groupingRules = roleGroupingPolicies(role)
+	}
 
-		modelFile := filepath.Join(config.C.General.WorkDir, config.C.Middleware.Casbin.ModelFile)
-		e, err := casbin.NewEnforcer(modelFile, policyFile)
-		if err != nil {
-			logging.Context(ctx).Error("Failed to create casbin enforcer", zap.Error(err))
+	// Hold the write lock across remove and add so requests never observe a partial policy.
+	lock := a.enforcer.GetLock()
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, err := a.enforcer.Enforcer.RemoveFilteredPolicy(0, roleID); err != nil {
+		return err
+	}
+	if len(rules) > 0 {
+		if _, err := a.enforcer.Enforcer.AddPolicies(rules); err != nil {
+			return err
+		}
+	}
+	if _, err := a.enforcer.Enforcer.RemoveFilteredGroupingPolicy(0, roleID); err != nil {
+		return err
+	}
+	if len(groupingRules) > 0 {
+		if _, err := a.enforcer.Enforcer.AddGroupingPolicies(groupingRules); err != nil {
 			return err
 		}
-		e.EnableLog(config.C.IsDebug())
-		a.enforcer.Store(e)
 	}
 
-	logging.Context(ctx).Info("Casbin load policy",
-		zap.Duration("cost", time.Since(start)),
-		zap.Int("roles", len(roleResult.Data)),
-		zap.Int32("resources", resCount),
-		zap.Int("bytes", buf.Len()),
+	logging.Context(ctx).Info("Casbin reload role policy",
+		zap.String("role_id", roleID),
+		zap.Int("rules", len(rules)),
+		zap.Int("grouping_rules", len(groupingRules)),
 	)
 	return nil
 }
@@ -187,29 +202,84 @@ func (a *Casbinx) queryRoleResources(ctx context.Context, roleID string) (model.
 	return menuResourceResult.Data, nil
 }
 
+func (a *Casbinx) queryRolePermissions(ctx context.Context, roleID string) (model.Permissions, error) {
+	rolePermissionResult, err := a.RolePermissionRepo.Query(ctx, model.RolePermissionQueryParam{
+		RoleID: roleID,
+	}, model.RolePermissionQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"permission_id"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	} else if len(rolePermissionResult.Data) == 0 {
+		return nil, nil
+	}
+
+	permissionResult, err := a.PermissionRepo.Query(ctx, model.PermissionQueryParam{
+		InIDs: rolePermissionResult.Data.ToPermissionIDs(),
+	}, model.PermissionQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "http_method", "http_path"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return permissionResult.Data, nil
+}
+
+// Reload the policy of a role as soon as a rbac service publishes its ID,
+// and the whole policy after a reconnect since the messages published meanwhile are lost.
+func (a *Casbinx) subscribe(ctx context.Context, notifier cachex.Notifier) error {
+	return notifier.Subscribe(ctx, config.CacheNSForCasbin, func(ctx context.Context, roleID string) {
+		if err := a.reloadRole(ctx, roleID); err != nil {
+			logging.Context(ctx).Error("Failed to reload casbin role policy", zap.Error(err), zap.String("role_id", roleID))
+		}
+	}, func(ctx context.Context) {
+		if err := a.enforcer.LoadPolicy(); err != nil {
+			logging.Context(ctx).Error("Failed to reload casbin policy after resubscribing", zap.Error(err))
+			return
+		}
+		logging.Context(ctx).Info("Casbin policy reloaded after resubscribing")
+	})
+}
+
+// Poll the role sync marks written by the rbac services and reload the policy of the changed roles only.
 func (a *Casbinx) autoLoad(ctx context.Context) {
-	var lastUpdated int64
-	a.ticker = time.NewTicker(time.Duration(config.C.Middleware.Casbin.AutoLoadInterval) * time.Second)
-	for range a.ticker.C {
-		val, ok, err := a.Cache.Get(ctx, config.CacheNSForRole, config.CacheKeyForSyncToCasbin)
-		if err != nil {
-			logging.Context(ctx).Error("Failed to get cache", zap.Error(err), zap.String("key", config.CacheKeyForSyncToCasbin))
-			continue
-		} else if !ok {
-			continue
+	synced := make(map[string]string)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.ticker.C:
 		}
 
-		updated, err := strconv.ParseInt(val, 10, 64)
+		marks := make(map[string]string)
+		err := a.Cache.Iterator(ctx, config.CacheNSForCasbin, func(ctx context.Context, key, value string) bool {
+			marks[key] = value
+			return true
+		})
 		if err != nil {
-			logging.Context(ctx).Error("Failed to parse cache value", zap.Error(err), zap.String("val", val))
+			logging.Context(ctx).Error("Failed to iterate cache", zap.Error(err), zap.String("ns", config.CacheNSForCasbin))
 			continue
 		}
 
-		if lastUpdated < updated {
-			if err := a.load(ctx); err != nil {
-				logging.Context(ctx).Error("Failed to load casbin policy", zap.Error(err))
-			} else {
-				lastUpdated = updated
+		for roleID, mark := range marks {
+			if synced[roleID] == mark {
+				continue
+			}
+			if err := a.reloadRole(ctx, roleID); err != nil {
+				logging.Context(ctx).Error("Failed to reload casbin role policy", zap.Error(err), zap.String("role_id", roleID))
+				continue
+			}
+			synced[roleID] = mark
+		}
+
+		for roleID := range synced {
+			if _, ok := marks[roleID]; !ok {
+				delete(synced, roleID)
 			}
 		}
 	}
@@ -219,5 +289,8 @@ func (a *Casbinx) Release(ctx context.Context) error {
 	if a.ticker != nil {
 		a.ticker.Stop()
 	}
+	if a.cancel != nil {
+		a.cancel()
+	}
 	return nil
 }
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	casbinModel "github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"go.uber.org/zap"
+)
+
+// Casbin treats this message as "skip the storage step" for auto-save operations.
+var errPolicyAdapterReadOnly = errors.New("not implemented")
+
+// A read-only casbin adapter that loads the rbac policy directly from the role/menu/resource/permission tables.
+type policyAdapter struct {
+	ctx     context.Context
+	casbinx *Casbinx
+}
+
+var _ persist.Adapter = (*policyAdapter)(nil)
+
+func newPolicyAdapter(ctx context.Context, casbinx *Casbinx) *policyAdapter {
+	return &policyAdapter{ctx: ctx, casbinx: casbinx}
+}
+
+// LoadPolicy loads the policy and grouping rules of all enabled roles into the casbin model.
+func (a *policyAdapter) LoadPolicy(m casbinModel.Model) error {
+	ctx := a.ctx
+	start := time.Now()
+	roleResult, err := a.casbinx.RoleRepo.Query(ctx, model.RoleQueryParam{
+		Status: model.RoleStatusEnabled,
+	}, model.RoleQueryOptions{
+		QueryOptions: util.QueryOptions{SelectFields: []string{"id", "tenant_id", "parent_ids"}},
+	})
+	if err != nil {
+		return err
+	}
+
+	var ruleCount int32
+	queue := make(chan *model.Role, len(roleResult.Data))
+	threadNum := config.C.Middleware.Casbin.LoadThread
+	if threadNum < 1 {
+		threadNum = 1
+	}
+	lock := new(sync.Mutex)
+
+	wg := new(sync.WaitGroup)
+	wg.Add(threadNum)
+	for i := 0; i < threadNum; i++ {
+		go func() {
+			defer wg.Done()
+			for role := range queue {
+				rules, err := a.casbinx.queryRolePolicies(ctx, role)
+				if err != nil {
+					logging.Context(ctx).Error("Failed to query role policies", zap.Error(err), zap.String("role_id", role.ID))
+					continue
+				}
+
+				lock.Lock()
+				for _, rule := range rules {
+					_ = persist.LoadPolicyArray(append([]string{"p"}, rule...), m)
+				}
+				for _, rule := range roleGroupingPolicies(role) {
+					_ = persist.LoadPolicyArray(append([]string{"g"}, rule...), m)
+				}
+				lock.Unlock()
+				atomic.AddInt32(&ruleCount, int32(len(rules)))
+			}
+		}()
+	}
+
+	for _, item := range roleResult.Data {
+		queue <- item
+	}
+	close(queue)
+	wg.Wait()
+
+	logging.Context(ctx).Info("Casbin load policy",
+		zap.Duration("cost", time.Since(start)),
+		zap.Int("roles", len(roleResult.Data)),
+		zap.Int32("rules", ruleCount),
+	)
+	return nil
+}
+
+// SavePolicy is not supported, the policy is derived from the rbac tables.
+func (a *policyAdapter) SavePolicy(m casbinModel.Model) error {
+	return errPolicyAdapterReadOnly
+}
+
+// AddPolicy is not supported, the policy is derived from the rbac tables.
+func (a *policyAdapter) AddPolicy(sec string, ptype string, rule []string) error {
+	return errPolicyAdapterReadOnly
+}
+
+// RemovePolicy is not supported, the policy is derived from the rbac tables.
+func (a *policyAdapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return errPolicyAdapterReadOnly
+}
+
+// RemoveFilteredPolicy is not supported, the policy is derived from the rbac tables.
+func (a *policyAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return errPolicyAdapterReadOnly
+}
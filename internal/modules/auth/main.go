@@ -7,6 +7,7 @@ import (
 	"go-admin/internal/config"
 	"go-admin/internal/modules/auth/api"
 	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/service"
 	"go-admin/pkg/logging"
 
 	"github.com/gin-gonic/gin"
@@ -15,13 +16,19 @@ import (
 )
 
 type Auth struct {
-	DB            *gorm.DB
-	MenuAPI       *api.Menu
-	RoleAPI       *api.Role
-	UserAPI       *api.User
-	AuthAPI       *api.Auth
-	PermissionAPI *api.Permission
-	Casbinx       *Casbinx
+	DB              *gorm.DB
+	MenuAPI         *api.Menu
+	RoleAPI         *api.Role
+	UserAPI         *api.User
+	AuthAPI         *api.Auth
+	PermissionAPI   *api.Permission
+	OIDCAPI         *api.OIDC
+	DepartmentAPI   *api.Department
+	TenantAPI       *api.Tenant
+	InvitationAPI   *api.Invitation
+	Casbinx         *Casbinx
+	RoleSweeper     *RoleSweeper
+	PasswordService *service.Password
 }
 
 func (a *Auth) AutoMigrate(ctx context.Context) error {
@@ -33,6 +40,16 @@ func (a *Auth) AutoMigrate(ctx context.Context) error {
 		new(model.User),
 		new(model.UserRole),
 		new(model.Permission),
+		new(model.RolePermission),
+		new(model.PasswordHistory),
+		new(model.UserIdentity),
+		new(model.APIKey),
+		new(model.Passkey),
+		new(model.LoginHistory),
+		new(model.Department),
+		new(model.UserDepartment),
+		new(model.Tenant),
+		new(model.Invitation),
 	)
 }
 
@@ -47,6 +64,11 @@ func (a *Auth) Init(ctx context.Context) error {
 		return err
 	}
 
+	if err := a.PasswordService.Load(ctx); err != nil {
+		return err
+	}
+	a.RoleSweeper.Start(ctx)
+
 	if name := config.C.General.MenuFile; name != "" {
 		fullPath := filepath.Join(config.C.General.WorkDir, name)
 		if err := a.MenuAPI.MenuService.InitFromFile(ctx, fullPath); err != nil {
@@ -64,16 +86,48 @@ func (a *Auth) RegisterV1Routers(ctx context.Context, v1 *gin.RouterGroup) error
 		captcha.GET("image", a.AuthAPI.ResponseCaptcha)
 	}
 	v1.POST("login", a.AuthAPI.Login)
+	v1.POST("login/mfa", a.AuthAPI.LoginMFA)
+	v1.POST("login/change-password", a.AuthAPI.LoginChangePassword)
+	v1.POST("login/oidc", a.OIDCAPI.Login)
+	v1.POST("login/passkey/begin", a.AuthAPI.PasskeyLoginBegin)
+	v1.POST("login/passkey/finish", a.AuthAPI.PasskeyLoginFinish)
+	v1.POST("refresh-token", a.AuthAPI.RefreshToken)
 	v1.POST("register", a.AuthAPI.Register)
+	v1.POST("verify-email", a.AuthAPI.VerifyEmail)
+	v1.POST("resend-verification", a.AuthAPI.ResendVerification)
+	v1.POST("forgot-password", a.AuthAPI.ForgotPassword)
+	v1.POST("reset-password", a.AuthAPI.ResetPassword)
+	v1.POST("accept-invitation", a.AuthAPI.AcceptInvitation)
+
+	oidc := v1.Group("oidc")
+	{
+		oidc.GET("providers", a.OIDCAPI.Providers)
+		oidc.GET(":provider/authorize", a.OIDCAPI.Authorize)
+		oidc.GET(":provider/callback", a.OIDCAPI.Callback)
+	}
 
 	current := v1.Group("current")
 	{
-		current.POST("refresh-token", a.AuthAPI.RefreshToken)
 		current.GET("user", a.AuthAPI.GetUserInfo)
 		current.GET("menus", a.AuthAPI.QueryMenus)
 		current.PUT("password", a.AuthAPI.UpdatePassword)
 		current.PUT("user", a.AuthAPI.UpdateUser)
 		current.POST("logout", a.AuthAPI.Logout)
+		current.POST("mfa/enroll", a.AuthAPI.EnrollMFA)
+		current.POST("mfa/confirm", a.AuthAPI.ConfirmMFA)
+		current.POST("mfa/disable", a.AuthAPI.DisableMFA)
+		current.GET("sessions", a.AuthAPI.QuerySessions)
+		current.DELETE("sessions", a.AuthAPI.RevokeOtherSessions)
+		current.DELETE("sessions/:id", a.AuthAPI.RevokeSession)
+		current.GET("api-keys", a.AuthAPI.QueryAPIKeys)
+		current.POST("api-keys", a.AuthAPI.CreateAPIKey)
+		current.DELETE("api-keys/:id", a.AuthAPI.RevokeAPIKey)
+		current.GET("login-history", a.AuthAPI.QueryLoginHistory)
+		current.GET("passkeys", a.AuthAPI.QueryPasskeys)
+		current.POST("passkeys/begin", a.AuthAPI.BeginPasskeyRegistration)
+		current.POST("passkeys", a.AuthAPI.FinishPasskeyRegistration)
+		current.DELETE("passkeys/:id", a.AuthAPI.DeletePasskey)
+		current.DELETE("impersonation", a.AuthAPI.EndImpersonation)
 	}
 	menu := v1.Group("menus")
 	{
@@ -99,21 +153,64 @@ func (a *Auth) RegisterV1Routers(ctx context.Context, v1 *gin.RouterGroup) error
 		role.PUT(":id", a.RoleAPI.Update)
 		role.DELETE(":id", a.RoleAPI.Delete)
 	}
+	department := v1.Group("departments")
+	{
+		department.GET("", a.DepartmentAPI.Query)
+		department.GET(":id", a.DepartmentAPI.Get)
+		department.POST("", a.DepartmentAPI.Create)
+		department.PUT(":id", a.DepartmentAPI.Update)
+		department.DELETE(":id", a.DepartmentAPI.Delete)
+		department.PATCH(":id/move", a.DepartmentAPI.Move)
+	}
+	tenant := v1.Group("tenants")
+	{
+		tenant.GET("", a.TenantAPI.Query)
+		tenant.GET(":id", a.TenantAPI.Get)
+		tenant.POST("", a.TenantAPI.Create)
+		tenant.PUT(":id", a.TenantAPI.Update)
+		tenant.DELETE(":id", a.TenantAPI.Delete)
+	}
+	invitation := v1.Group("invitations")
+	{
+		invitation.GET("", a.InvitationAPI.Query)
+		invitation.POST("", a.InvitationAPI.Create)
+		invitation.POST(":id/resend", a.InvitationAPI.Resend)
+		invitation.DELETE(":id", a.InvitationAPI.Revoke)
+	}
 	user := v1.Group("users")
 	{
 		user.GET("", a.UserAPI.Query)
+		user.GET("export", a.UserAPI.Export)
+		user.POST("import", a.UserAPI.Import)
 		user.GET(":id", a.UserAPI.Get)
 		user.POST("", a.UserAPI.Create)
 		user.PUT(":id", a.UserAPI.Update)
 		user.DELETE(":id", a.UserAPI.Delete)
 		user.PATCH(":id/reset-pwd", a.UserAPI.ResetPassword)
+		user.PATCH(":id/unlock", a.UserAPI.Unlock)
+		user.GET(":id/sessions", a.UserAPI.QuerySessions)
+		user.DELETE(":id/sessions", a.UserAPI.RevokeSessions)
+		user.DELETE(":id/sessions/:sid", a.UserAPI.RevokeSession)
+		user.GET(":id/login-history", a.UserAPI.QueryLoginHistory)
+		user.GET(":id/api-keys", a.UserAPI.QueryAPIKeys)
+		user.DELETE(":id/api-keys/:kid", a.UserAPI.RevokeAPIKey)
+		user.POST(":id/impersonate", a.AuthAPI.Impersonate)
 	}
 	return nil
 }
 
+// Public routes outside the API prefix, e.g. /.well-known/jwks.json
+func (a *Auth) RegisterWellKnownRouters(ctx context.Context, wellKnown *gin.RouterGroup) error {
+	wellKnown.GET("jwks.json", a.AuthAPI.JWKS)
+	return nil
+}
+
 func (a *Auth) Release(ctx context.Context) error {
 	if err := a.Casbinx.Release(ctx); err != nil {
 		return err
 	}
+	if err := a.RoleSweeper.Release(ctx); err != nil {
+		return err
+	}
 	return nil
 }
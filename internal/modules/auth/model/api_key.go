@@ -0,0 +1,45 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"go-admin/internal/config"
+)
+
+// Personal API keys of users for machine access
+type APIKey struct {
+	ID         string     `json:"id" gorm:"size:20;primarykey;"`             // Unique ID
+	UserID     string     `json:"user_id" gorm:"size:20;index"`              // From User.ID
+	Name       string     `json:"name" gorm:"size:128;"`                     // Name of the key
+	Prefix     string     `json:"prefix" gorm:"size:16;"`                    // Leading characters of the key to recognize it
+	KeyHash    string     `json:"-" gorm:"size:64;uniqueIndex"`              // SHA256 hash of the key
+	RoleIDs    []string   `json:"role_ids" gorm:"size:1024;serializer:json"` // Roles granted to the key, all roles of the user if empty
+	ExpiresAt  *time.Time `json:"expires_at"`                                // Expiration time, never expires if empty
+	LastUsedAt *time.Time `json:"last_used_at"`                              // Last time the key was used
+	LastUsedIP string     `json:"last_used_ip" gorm:"size:64;"`              // Client IP of the last use
+	CreatedAt  time.Time  `json:"created_at" gorm:"index;"`                  // Create time
+	Key        string     `json:"key,omitempty" gorm:"-"`                    // The key, only returned when it is created
+}
+
+func (a *APIKey) TableName() string {
+	return config.C.FormatTableName("api_keys")
+}
+
+func (a *APIKey) Expired() bool {
+	return a.ExpiresAt != nil && time.Now().After(*a.ExpiresAt)
+}
+
+// Defining the slice of `APIKey` struct.
+type APIKeys []*APIKey
+
+type APIKeyForm struct {
+	Name      string     `json:"name" binding:"required,max=128"` // Name of the key
+	ExpiresAt *time.Time `json:"expires_at"`                      // Expiration time, never expires if empty
+	RoleIDs   []string   `json:"role_ids"`                        // Roles granted to the key (subset of the user roles), all roles of the user if empty
+}
+
+func (a *APIKeyForm) Trim() *APIKeyForm {
+	a.Name = strings.TrimSpace(a.Name)
+	return a
+}
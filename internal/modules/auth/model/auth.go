@@ -53,10 +53,101 @@ type UpdateLoginPassword struct {
 	NewPassword string `json:"new_password" binding:"required"` // New password (md5 hash)
 }
 
+type VerifyEmailForm struct {
+	Token string `json:"token" binding:"required"` // Token from the verification email
+}
+
+func (a *VerifyEmailForm) Trim() *VerifyEmailForm {
+	a.Token = strings.TrimSpace(a.Token)
+	return a
+}
+
+type ResendVerificationForm struct {
+	Email string `json:"email" binding:"required,email"` // Registered email
+}
+
+func (a *ResendVerificationForm) Trim() *ResendVerificationForm {
+	a.Email = strings.TrimSpace(a.Email)
+	return a
+}
+
+type ForgotPasswordForm struct {
+	Email string `json:"email" binding:"required,email"` // Email of the account to recover
+}
+
+func (a *ForgotPasswordForm) Trim() *ForgotPasswordForm {
+	a.Email = strings.TrimSpace(a.Email)
+	return a
+}
+
+type ResetPasswordForm struct {
+	Token    string `json:"token" binding:"required"`    // Token from the reset password email
+	Password string `json:"password" binding:"required"` // New password (md5 hash)
+}
+
+func (a *ResetPasswordForm) Trim() *ResetPasswordForm {
+	a.Token = strings.TrimSpace(a.Token)
+	return a
+}
+
 type LoginToken struct {
-	AccessToken string `json:"access_token"` // Access token (JWT)
-	TokenType   string `json:"token_type"`   // Token type (Usage: Authorization=${token_type} ${access_token})
-	ExpiresAt   int64  `json:"expires_at"`   // Expired time (Unit: second)
+	AccessToken      string `json:"access_token"`                 // Access token (JWT)
+	TokenType        string `json:"token_type"`                   // Token type (Usage: Authorization=${token_type} ${access_token})
+	ExpiresAt        int64  `json:"expires_at"`                   // Expired time (Unit: second)
+	RefreshToken     string `json:"refresh_token,omitempty"`      // Refresh token, exchange it for a new token pair before it expires
+	RefreshExpiresAt int64  `json:"refresh_expires_at,omitempty"` // Expired time of the refresh token (Unit: second)
+	MFARequired      bool   `json:"mfa_required,omitempty"`       // Two-factor authentication is required, complete it with `mfa_token`
+	MFAToken         string `json:"mfa_token,omitempty"`          // Token of the two-factor login challenge
+	PasswordExpired  bool   `json:"password_expired,omitempty"`   // The password is expired, change it with `password_token` to login
+	PasswordToken    string `json:"password_token,omitempty"`     // Token to change the expired password
+}
+
+type RefreshTokenForm struct {
+	RefreshToken string `json:"refresh_token" binding:"required"` // Refresh token, it can only be used once
+}
+
+func (a *RefreshTokenForm) Trim() *RefreshTokenForm {
+	a.RefreshToken = strings.TrimSpace(a.RefreshToken)
+	return a
+}
+
+type LoginChangePasswordForm struct {
+	PasswordToken string `json:"password_token" binding:"required"`      // Token to change the expired password
+	NewPassword   string `json:"new_password" binding:"required,max=64"` // New password
+}
+
+func (a *LoginChangePasswordForm) Trim() *LoginChangePasswordForm {
+	a.PasswordToken = strings.TrimSpace(a.PasswordToken)
+	return a
+}
+
+type LoginMFAForm struct {
+	MFAToken string `json:"mfa_token" binding:"required"` // Token of the two-factor login challenge
+	Code     string `json:"code" binding:"required"`      // TOTP code or one-time recovery code
+}
+
+func (a *LoginMFAForm) Trim() *LoginMFAForm {
+	a.MFAToken = strings.TrimSpace(a.MFAToken)
+	a.Code = strings.TrimSpace(a.Code)
+	return a
+}
+
+type MFACodeForm struct {
+	Code string `json:"code" binding:"required"` // TOTP code (or one-time recovery code when disabling)
+}
+
+func (a *MFACodeForm) Trim() *MFACodeForm {
+	a.Code = strings.TrimSpace(a.Code)
+	return a
+}
+
+type MFAEnrollment struct {
+	Secret string `json:"secret"` // TOTP secret (base32) for manual entry
+	URI    string `json:"uri"`    // Provisioning URI (otpauth://), display it as a QR code
+}
+
+type MFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"` // One-time recovery codes, only displayed once
 }
 
 type UpdateCurrentUser struct {
@@ -65,3 +156,19 @@ type UpdateCurrentUser struct {
 	Phone     string `json:"phone" binding:"max=32"`               // Phone number of user
 	Remark    string `json:"remark" binding:"max=1024"`            // Remark of user
 }
+
+// External identity provider to login with
+type OIDCProvider struct {
+	Name         string `json:"name"`          // Unique name of the provider
+	DisplayName  string `json:"display_name"`  // Name of the login button
+	AuthorizeURL string `json:"authorize_url"` // Open it in the browser to login with the provider
+}
+
+type OIDCLoginForm struct {
+	Code string `json:"code" binding:"required"` // Login code appended to the login page by the provider callback
+}
+
+func (a *OIDCLoginForm) Trim() *OIDCLoginForm {
+	a.Code = strings.TrimSpace(a.Code)
+	return a
+}
@@ -0,0 +1,172 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/pkg/util"
+)
+
+const (
+	DepartmentStatusDisabled = "disabled"
+	DepartmentStatusEnabled  = "enabled"
+)
+
+var (
+	DepartmentsOrderParams = []util.OrderByParam{
+		{Field: "sequence", Direction: util.DESC},
+		{Field: "created_at", Direction: util.DESC},
+	}
+)
+
+// Department of the organization
+type Department struct {
+	ID          string       `json:"id" gorm:"size:20;primarykey;"`      // Unique ID
+	TenantID    string       `json:"tenant_id" gorm:"size:20;index;"`    // From Tenant.ID, empty for the default tenant
+	Code        string       `json:"code" gorm:"size:32;index;"`         // Code of department (unique for each level)
+	Name        string       `json:"name" gorm:"size:128;index"`         // Display name of department
+	Description string       `json:"description" gorm:"size:1024"`       // Details about department
+	Sequence    int          `json:"sequence" gorm:"index;"`             // Sequence for sorting (Order by desc)
+	Status      string       `json:"status" gorm:"size:20;index"`        // Status of department (enabled, disabled)
+	ParentID    string       `json:"parent_id" gorm:"size:20;index;"`    // Parent ID (From Department.ID)
+	ParentPath  string       `json:"parent_path" gorm:"size:255;index;"` // Parent path (split by .)
+	Children    *Departments `json:"children" gorm:"-"`                  // Child departments
+	LeaderIDs   []string     `json:"leader_ids" gorm:"-"`                // Leaders of department (From User.ID)
+	CreatedAt   time.Time    `json:"created_at" gorm:"index;"`           // Create time
+	UpdatedAt   time.Time    `json:"updated_at" gorm:"index;"`           // Update time
+}
+
+func (a *Department) TableName() string {
+	return config.C.FormatTableName("departments")
+}
+
+// Defining the query parameters for the `Department` struct.
+type DepartmentQueryParam struct {
+	util.PaginationParam
+	LikeName         string   `form:"name"`                                       // Display name of department
+	Status           string   `form:"status" binding:"oneof=disabled enabled ''"` // Status of department (disabled, enabled)
+	InIDs            []string `form:"-"`                                          // Include department IDs
+	ParentID         string   `form:"-"`                                          // Parent ID (From Department.ID)
+	ParentPathPrefix string   `form:"-"`                                          // Parent path (split by .)
+}
+
+// Defining the query options for the `Department` struct.
+type DepartmentQueryOptions struct {
+	util.QueryOptions
+}
+
+// Defining the query result for the `Department` struct.
+type DepartmentQueryResult struct {
+	Data       Departments
+	PageResult *util.PaginationResult
+}
+
+// Defining the slice of `Department` struct.
+type Departments []*Department
+
+func (a Departments) Len() int {
+	return len(a)
+}
+
+func (a Departments) Less(i, j int) bool {
+	if a[i].Sequence == a[j].Sequence {
+		return a[i].CreatedAt.Unix() > a[j].CreatedAt.Unix()
+	}
+	return a[i].Sequence > a[j].Sequence
+}
+
+func (a Departments) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
+
+func (a Departments) ToMap() map[string]*Department {
+	m := make(map[string]*Department)
+	for _, item := range a {
+		m[item.ID] = item
+	}
+	return m
+}
+
+func (a Departments) ToIDs() []string {
+	var ids []string
+	for _, item := range a {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func (a Departments) SplitParentIDs() []string {
+	parentIDs := make([]string, 0, len(a))
+	idMapper := make(map[string]struct{})
+	for _, item := range a {
+		if _, ok := idMapper[item.ID]; ok {
+			continue
+		}
+		idMapper[item.ID] = struct{}{}
+		if pp := item.ParentPath; pp != "" {
+			for _, pid := range strings.Split(pp, util.TreePathDelimiter) {
+				if pid == "" {
+					continue
+				}
+				if _, ok := idMapper[pid]; ok {
+					continue
+				}
+				parentIDs = append(parentIDs, pid)
+				idMapper[pid] = struct{}{}
+			}
+		}
+	}
+	return parentIDs
+}
+
+func (a Departments) ToTree() Departments {
+	var list Departments
+	m := a.ToMap()
+	for _, item := range a {
+		if item.ParentID == "" {
+			list = append(list, item)
+			continue
+		}
+		if parent, ok := m[item.ParentID]; ok {
+			if parent.Children == nil {
+				children := Departments{item}
+				parent.Children = &children
+				continue
+			}
+			*parent.Children = append(*parent.Children, item)
+		}
+	}
+	return list
+}
+
+// Defining the data structure for creating a `Department` struct.
+type DepartmentForm struct {
+	Code        string `json:"code" binding:"required,max=32"`                   // Code of department (unique for each level)
+	Name        string `json:"name" binding:"required,max=128"`                  // Display name of department
+	Description string `json:"description"`                                      // Details about department
+	Sequence    int    `json:"sequence"`                                         // Sequence for sorting (Order by desc)
+	Status      string `json:"status" binding:"required,oneof=disabled enabled"` // Status of department (enabled, disabled)
+	ParentID    string `json:"parent_id"`                                        // Parent ID (From Department.ID)
+}
+
+// A validation function for the `DepartmentForm` struct.
+func (a *DepartmentForm) Validate() error {
+	return nil
+}
+
+func (a *DepartmentForm) FillTo(department *Department) error {
+	department.Code = a.Code
+	department.Name = a.Name
+	department.Description = a.Description
+	department.Sequence = a.Sequence
+	department.Status = a.Status
+	department.ParentID = a.ParentID
+	return nil
+}
+
+// Defining the data structure for moving a `Department` struct.
+type DepartmentMoveForm struct {
+	ParentID string `json:"parent_id"` // New parent ID (From Department.ID), moved to the top level if empty
+	Sequence *int   `json:"sequence"`  // New sequence for sorting (Order by desc), unchanged if empty
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/pkg/util"
+)
+
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+)
+
+// Invitation of a user by email, the invitee sets the name and password and gets the pre-assigned roles
+type Invitation struct {
+	ID         string     `json:"id" gorm:"size:20;primarykey;"`             // Unique ID
+	TenantID   string     `json:"tenant_id" gorm:"size:20;index;"`           // From Tenant.ID, empty for the default tenant
+	Email      string     `json:"email" gorm:"size:255;index"`               // Email of the invitee
+	RoleIDs    []string   `json:"role_ids" gorm:"size:1024;serializer:json"` // Roles assigned to the invitee
+	Status     string     `json:"status" gorm:"size:20;index"`               // Status of invitation (pending, accepted, revoked)
+	TokenHash  string     `json:"-" gorm:"size:64;uniqueIndex"`              // sha256 hash of the invite token
+	ExpiresAt  time.Time  `json:"expires_at"`                                // Expire time of the invite token
+	InvitedBy  string     `json:"invited_by" gorm:"size:20;"`                // From User.ID
+	UserID     string     `json:"user_id" gorm:"size:20;index"`              // From User.ID, the user created by the invitee
+	SentAt     time.Time  `json:"sent_at"`                                   // Last time the invitation was mailed
+	AcceptedAt *time.Time `json:"accepted_at"`                               // Accept time
+	CreatedAt  time.Time  `json:"created_at" gorm:"index;"`                  // Create time
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"index;"`                  // Update time
+}
+
+func (a *Invitation) TableName() string {
+	return config.C.FormatTableName("invitations")
+}
+
+// Check if the invitation can be accepted at the time
+func (a *Invitation) IsAcceptableAt(t time.Time) bool {
+	return a.Status == InvitationStatusPending && a.ExpiresAt.After(t)
+}
+
+// Defining the query parameters for the `Invitation` struct.
+type InvitationQueryParam struct {
+	util.PaginationParam
+	LikeEmail string `form:"email"`                                              // Email of the invitee
+	Status    string `form:"status" binding:"oneof=pending accepted revoked ''"` // Status of invitation (pending, accepted, revoked)
+}
+
+// Defining the query options for the `Invitation` struct.
+type InvitationQueryOptions struct {
+	util.QueryOptions
+}
+
+// Defining the query result for the `Invitation` struct.
+type InvitationQueryResult struct {
+	Data       Invitations
+	PageResult *util.PaginationResult
+}
+
+// Defining the slice of `Invitation` struct.
+type Invitations []*Invitation
+
+// Defining the data structure for creating a `Invitation` struct.
+type InvitationForm struct {
+	Email   string   `json:"email" binding:"required,email,max=128"` // Email of the invitee
+	RoleIDs []string `json:"role_ids"`                               // Roles assigned to the invitee
+}
+
+func (a *InvitationForm) Trim() *InvitationForm {
+	a.Email = strings.TrimSpace(a.Email)
+	return a
+}
+
+// The invitee accepts the invitation with the name and password of the new user
+type AcceptInvitationForm struct {
+	Token     string `json:"token" binding:"required"`             // Invite token from the email
+	FirstName string `json:"first_name" binding:"required,max=64"` // First name
+	LastName  string `json:"last_name" binding:"required,max=64"`  // Last name
+	Password  string `json:"password" binding:"required"`          // Login password (md5 hash)
+}
+
+func (a *AcceptInvitationForm) Trim() *AcceptInvitationForm {
+	a.Token = strings.TrimSpace(a.Token)
+	a.FirstName = strings.TrimSpace(a.FirstName)
+	a.LastName = strings.TrimSpace(a.LastName)
+	return a
+}
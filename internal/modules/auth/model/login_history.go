@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/pkg/util"
+)
+
+const (
+	LoginMethodPassword = "password"
+	LoginMethodLDAP     = "ldap"
+	LoginMethodOIDC     = "oidc"
+	LoginMethodPasskey  = "passkey"
+	LoginMethodEmail    = "email" // Login with the link of the verification email
+)
+
+// Login attempts of users, the failed attempts of unknown emails are recorded without user
+type LoginHistory struct {
+	ID           string    `json:"id" gorm:"size:20;primarykey;"` // Unique ID
+	UserID       string    `json:"user_id" gorm:"size:20;index"`  // From User.ID
+	Email        string    `json:"email" gorm:"size:255;"`        // Login email
+	IP           string    `json:"ip" gorm:"size:64;"`            // Client IP
+	UserAgent    string    `json:"user_agent" gorm:"size:512;"`   // User agent of the client
+	Method       string    `json:"method" gorm:"size:20;"`        // Login method (password, ldap, oidc, passkey, email)
+	Success      bool      `json:"success" gorm:"index"`          // Login succeeded
+	Reason       string    `json:"reason" gorm:"size:255;"`       // Reason of the failure
+	MFAUsed      bool      `json:"mfa_used"`                      // Completed with two-factor authentication
+	NewIP        bool      `json:"new_ip"`                        // First successful login from the IP
+	NewUserAgent bool      `json:"new_user_agent"`                // First successful login with the user agent
+	CreatedAt    time.Time `json:"created_at" gorm:"index;"`      // Login time
+}
+
+func (a *LoginHistory) TableName() string {
+	return config.C.FormatTableName("login_histories")
+}
+
+// Login from a new IP or user agent
+func (a *LoginHistory) Unusual() bool {
+	return a.NewIP || a.NewUserAgent
+}
+
+// Defining the query parameters for the `LoginHistory` struct.
+type LoginHistoryQueryParam struct {
+	util.PaginationParam
+	Success *bool  `form:"success"` // Login succeeded
+	UserID  string `form:"-"`       // From User.ID
+}
+
+// Defining the query options for the `LoginHistory` struct.
+type LoginHistoryQueryOptions struct {
+	util.QueryOptions
+}
+
+// Defining the query result for the `LoginHistory` struct.
+type LoginHistoryQueryResult struct {
+	Data       LoginHistories
+	PageResult *util.PaginationResult
+}
+
+// Defining the slice of `LoginHistory` struct.
+type LoginHistories []*LoginHistory
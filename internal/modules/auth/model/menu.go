@@ -24,21 +24,22 @@ var (
 
 // Menu management for RBAC
 type Menu struct {
-	ID          string        `json:"id" gorm:"size:20;primarykey;"`      // Unique ID
-	Code        string        `json:"code" gorm:"size:32;index;"`         // Code of menu (unique for each level)
-	Name        string        `json:"name" gorm:"size:128;index"`         // Display name of menu
-	Description string        `json:"description" gorm:"size:1024"`       // Details about menu
-	Sequence    int           `json:"sequence" gorm:"index;"`             // Sequence for sorting (Order by desc)
-	Type        string        `json:"type" gorm:"size:20;index"`          // Type of menu (page, button)
-	Path        string        `json:"path" gorm:"size:255;"`              // Access path of menu
-	Properties  string        `json:"properties" gorm:"type:text;"`       // Properties of menu (JSON)
-	Status      string        `json:"status" gorm:"size:20;index"`        // Status of menu (enabled, disabled)
-	ParentID    string        `json:"parent_id" gorm:"size:20;index;"`    // Parent ID (From Menu.ID)
-	ParentPath  string        `json:"parent_path" gorm:"size:255;index;"` // Parent path (split by .)
-	Children    *Menus        `json:"children" gorm:"-"`                  // Child menus
-	CreatedAt   time.Time     `json:"created_at" gorm:"index;"`           // Create time
-	UpdatedAt   time.Time     `json:"updated_at" gorm:"index;"`           // Update time
-	Resources   MenuResources `json:"resources" gorm:"-"`                 // Resources of menu
+	ID          string        `json:"id" gorm:"size:20;primarykey;"`                   // Unique ID
+	TenantID    string        `json:"tenant_id" gorm:"size:20;index;" tenant:"shared"` // From Tenant.ID, menus of the default tenant are shared by all tenants
+	Code        string        `json:"code" gorm:"size:32;index;"`                      // Code of menu (unique for each level)
+	Name        string        `json:"name" gorm:"size:128;index"`                      // Display name of menu
+	Description string        `json:"description" gorm:"size:1024"`                    // Details about menu
+	Sequence    int           `json:"sequence" gorm:"index;"`                          // Sequence for sorting (Order by desc)
+	Type        string        `json:"type" gorm:"size:20;index"`                       // Type of menu (page, button)
+	Path        string        `json:"path" gorm:"size:255;"`                           // Access path of menu
+	Properties  string        `json:"properties" gorm:"type:text;"`                    // Properties of menu (JSON)
+	Status      string        `json:"status" gorm:"size:20;index"`                     // Status of menu (enabled, disabled)
+	ParentID    string        `json:"parent_id" gorm:"size:20;index;"`                 // Parent ID (From Menu.ID)
+	ParentPath  string        `json:"parent_path" gorm:"size:255;index;"`              // Parent path (split by .)
+	Children    *Menus        `json:"children" gorm:"-"`                               // Child menus
+	CreatedAt   time.Time     `json:"created_at" gorm:"index;"`                        // Create time
+	UpdatedAt   time.Time     `json:"updated_at" gorm:"index;"`                        // Update time
+	Resources   MenuResources `json:"resources" gorm:"-"`                              // Resources of menu
 }
 
 func (a *Menu) TableName() string {
@@ -57,6 +58,7 @@ type MenuQueryParam struct {
 	ParentPathPrefix string   `form:"-"`                // Parent path (split by .)
 	UserID           string   `form:"-"`                // User ID
 	RoleID           string   `form:"-"`                // Role ID
+	InRoleIDs        []string `form:"-"`                // Role ID list
 }
 
 // Defining the query options for the `Menu` struct.
@@ -96,6 +98,14 @@ func (a Menus) ToMap() map[string]*Menu {
 	return m
 }
 
+func (a Menus) ToIDs() []string {
+	var ids []string
+	for _, item := range a {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
 func (a Menus) SplitParentIDs() []string {
 	parentIDs := make([]string, 0, len(a))
 	idMapper := make(map[string]struct{})
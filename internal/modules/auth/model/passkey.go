@@ -0,0 +1,56 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/pkg/webauthn"
+)
+
+// WebAuthn credentials of users for the passwordless login
+type Passkey struct {
+	ID           string     `json:"id" gorm:"size:20;primarykey;"`              // Unique ID
+	UserID       string     `json:"user_id" gorm:"size:20;index"`               // From User.ID
+	Name         string     `json:"name" gorm:"size:128;"`                      // Name of the passkey
+	CredentialID string     `json:"credential_id" gorm:"size:1024;uniqueIndex"` // Credential ID of the authenticator (base64url)
+	PublicKey    []byte     `json:"-"`                                          // Public key of the credential (COSE_Key)
+	AAGUID       string     `json:"aaguid" gorm:"size:36;"`                     // Model of the authenticator
+	SignCount    uint32     `json:"-"`                                          // Signature counter of the authenticator
+	Transports   []string   `json:"transports" gorm:"size:256;serializer:json"` // Transports of the authenticator, e.g. internal/usb
+	LastUsedAt   *time.Time `json:"last_used_at"`                               // Last time the passkey was used to login
+	CreatedAt    time.Time  `json:"created_at" gorm:"index;"`                   // Create time
+}
+
+func (a *Passkey) TableName() string {
+	return config.C.FormatTableName("passkeys")
+}
+
+// Defining the slice of `Passkey` struct.
+type Passkeys []*Passkey
+
+// Options of a registration or login ceremony, the challenge ID is sent back with the response of the authenticator
+type PasskeyChallenge struct {
+	ChallengeID string      `json:"challenge_id"` // ID of the challenge
+	Options     interface{} `json:"options"`      // Options of navigator.credentials.create() or navigator.credentials.get()
+}
+
+type PasskeyRegisterForm struct {
+	ChallengeID string                       `json:"challenge_id" binding:"required"` // From PasskeyChallenge.ChallengeID
+	Name        string                       `json:"name" binding:"required,max=128"` // Name of the passkey
+	Credential  webauthn.AttestationResponse `json:"credential"`                      // Credential created by navigator.credentials.create()
+}
+
+func (a *PasskeyRegisterForm) Trim() *PasskeyRegisterForm {
+	a.Name = strings.TrimSpace(a.Name)
+	return a
+}
+
+type PasskeyLoginBeginForm struct {
+	Email string `json:"email" binding:"omitempty,max=128"` // Login with the passkeys of the user, any discoverable passkey if empty
+}
+
+type PasskeyLoginFinishForm struct {
+	ChallengeID string                     `json:"challenge_id" binding:"required"` // From PasskeyChallenge.ChallengeID
+	Credential  webauthn.AssertionResponse `json:"credential"`                      // Assertion returned by navigator.credentials.get()
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"go-admin/internal/config"
+)
+
+// Previous passwords of users, to prevent reusing them
+type PasswordHistory struct {
+	ID        string    `json:"id" gorm:"size:20;primarykey;"` // Unique ID
+	UserID    string    `json:"user_id" gorm:"size:20;index"`  // From User.ID
+	Password  string    `json:"-" gorm:"size:255;"`            // Previous password (encrypted)
+	CreatedAt time.Time `json:"created_at" gorm:"index;"`      // Create time
+}
+
+func (a *PasswordHistory) TableName() string {
+	return config.C.FormatTableName("password_histories")
+}
+
+// Defining the slice of `PasswordHistory` struct.
+type PasswordHistories []*PasswordHistory
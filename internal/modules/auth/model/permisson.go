@@ -1,33 +1,65 @@
 package model
 
 import (
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"go-admin/internal/config"
+	"go-admin/pkg/errors"
 	"go-admin/pkg/util"
 )
 
 const (
 	PermissionResultTypeSelect = "select" // Select
+
+	PermissionHttpMethodDelimiter = "," // Delimiter for multiple HTTP methods (e.g. GET,POST)
 )
 
+var permissionHttpMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
 // Permission management for RBAC
 type Permission struct {
-	ID          string    `json:"id" gorm:"size:20;primarykey;"` // Unique ID
-	Code        string    `json:"code" gorm:"size:32;index"`     // Display name of permission
-	Name        string    `json:"name" gorm:"size:255;index"`    // Display name of permission
-	Description string    `json:"description" gorm:"size:1024"`  // Details about permission
-	HttpMethod  string    `json:"http_method" gorm:"size:255"`   // HTTP method
-	HttpPath    string    `json:"http_path" gorm:"size:1024"`    // HTTP path
-	Sequence    int       `json:"sequence" gorm:"index"`         // Sequence for sorting
-	CreatedAt   time.Time `json:"created_at" gorm:"index;"`      // Create time
-	UpdatedAt   time.Time `json:"updated_at" gorm:"index;"`      // Update time
+	ID          string    `json:"id" gorm:"size:20;primarykey;"`                   // Unique ID
+	TenantID    string    `json:"tenant_id" gorm:"size:20;index;" tenant:"shared"` // From Tenant.ID, permissions of the default tenant are shared by all tenants
+	Code        string    `json:"code" gorm:"size:32;index"`                       // Display name of permission
+	Name        string    `json:"name" gorm:"size:255;index"`                      // Display name of permission
+	Description string    `json:"description" gorm:"size:1024"`                    // Details about permission
+	HttpMethod  string    `json:"http_method" gorm:"size:255"`                     // HTTP method
+	HttpPath    string    `json:"http_path" gorm:"size:1024"`                      // HTTP path
+	Sequence    int       `json:"sequence" gorm:"index"`                           // Sequence for sorting
+	CreatedAt   time.Time `json:"created_at" gorm:"index;"`                        // Create time
+	UpdatedAt   time.Time `json:"updated_at" gorm:"index;"`                        // Update time
 }
 
 func (a *Permission) TableName() string {
 	return config.C.FormatTableName("permissions")
 }
 
+// Split the HTTP method field into a list of upper case HTTP methods.
+func (a *Permission) SplitHttpMethods() []string {
+	return splitHttpMethods(a.HttpMethod)
+}
+
+func splitHttpMethods(s string) []string {
+	var methods []string
+	for _, method := range strings.Split(s, PermissionHttpMethodDelimiter) {
+		if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
 // Defining the query parameters for the `Permission` struct.
 type PermissionQueryParam struct {
 	util.PaginationParam
@@ -51,18 +83,35 @@ type PermissionQueryResult struct {
 // Defining the slice of `Permission` struct.
 type Permissions []*Permission
 
+func (a Permissions) ToIDs() []string {
+	var ids []string
+	for _, item := range a {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
 // Defining the data structure for creating a `Permission` struct.
 type PermissionForm struct {
 	Code        string `json:"code" binding:"required,max=32"`         // Code of permission (unique)
 	Name        string `json:"name" binding:"required,max=128"`        // Display name of permission
 	Description string `json:"description"`                            // Details about permission
 	Sequence    int    `json:"sequence"`                               // Sequence for sorting
-	HttpMethod  string `json:"http_method" binding:"required,max=255"` // HTTP method
+	HttpMethod  string `json:"http_method" binding:"required,max=255"` // HTTP method (multiple separated by commas, e.g. GET,POST)
 	HttpPath    string `json:"http_path" binding:"required,max=1024"`  // HTTP path
 }
 
 // A validation function for the `PermissionForm` struct.
 func (a *PermissionForm) Validate() error {
+	methods := splitHttpMethods(a.HttpMethod)
+	if len(methods) == 0 {
+		return errors.BadRequest("", "Invalid http method")
+	}
+	for _, method := range methods {
+		if !slices.Contains(permissionHttpMethods, method) {
+			return errors.BadRequest("", "Invalid http method '%s'", method)
+		}
+	}
 	return nil
 }
 
@@ -71,7 +120,7 @@ func (a *PermissionForm) FillTo(permission *Permission) error {
 	permission.Name = a.Name
 	permission.Description = a.Description
 	permission.Sequence = a.Sequence
-	permission.HttpMethod = a.HttpMethod
+	permission.HttpMethod = strings.Join(splitHttpMethods(a.HttpMethod), PermissionHttpMethodDelimiter)
 	permission.HttpPath = a.HttpPath
 	return nil
 }
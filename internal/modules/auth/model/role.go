@@ -1,9 +1,11 @@
 package model
 
 import (
+	"slices"
 	"time"
 
 	"go-admin/internal/config"
+	"go-admin/pkg/errors"
 	"go-admin/pkg/util"
 )
 
@@ -12,20 +14,31 @@ const (
 	RoleStatusDisabled = "disabled" // Disabled
 
 	RoleResultTypeSelect = "select" // Select
+
+	RoleDataScopeAll        = "all"        // All rows
+	RoleDataScopeOwn        = "own"        // Rows of the user itself
+	RoleDataScopeCustom     = "custom"     // Rows of the user itself and the selected users and departments
+	RoleDataScopeDepartment = "department" // Rows of the members of the user's departments and their sub-departments
+	RoleDataScopeInherit    = "inherit"    // Only the rows visible to the parent roles
 )
 
 // Role management for RBAC
 type Role struct {
-	ID          string          `json:"id" gorm:"size:20;primarykey;"` // Unique ID
-	Code        string          `json:"code" gorm:"size:32;index"`     // Display name of role
-	Name        string          `json:"name" gorm:"size:255;index"`    // Display name of role
-	Description string          `json:"description" gorm:"size:1024"`  // Details about role
-	Sequence    int             `json:"sequence" gorm:"index"`         // Sequence for sorting
-	Status      string          `json:"status" gorm:"size:20;index"`   // Status of role (disabled, enabled)
-	CreatedAt   time.Time       `json:"created_at" gorm:"index;"`      // Create time
-	UpdatedAt   time.Time       `json:"updated_at" gorm:"index;"`      // Update time
-	Menus       RoleMenus       `json:"menus" gorm:"-"`                // Role menu list
-	Permissions RolePermissions `json:"permissions" gorm:"-"`          // Role permission list
+	ID                string          `json:"id" gorm:"size:20;primarykey;"`                        // Unique ID
+	TenantID          string          `json:"tenant_id" gorm:"size:20;index;"`                      // From Tenant.ID, empty for the default tenant
+	Code              string          `json:"code" gorm:"size:32;index"`                            // Display name of role
+	Name              string          `json:"name" gorm:"size:255;index"`                           // Display name of role
+	Description       string          `json:"description" gorm:"size:1024"`                         // Details about role
+	Sequence          int             `json:"sequence" gorm:"index"`                                // Sequence for sorting
+	Status            string          `json:"status" gorm:"size:20;index"`                          // Status of role (disabled, enabled)
+	DataScope         string          `json:"data_scope" gorm:"size:20;default:all"`                // Rows visible to the role (all, own, custom, department, inherit)
+	DataUserIDs       []string        `json:"data_user_ids" gorm:"size:4096;serializer:json"`       // Users whose rows are visible to the custom data scope
+	DataDepartmentIDs []string        `json:"data_department_ids" gorm:"size:4096;serializer:json"` // Departments whose members' rows are visible to the custom data scope, sub-departments included
+	ParentIDs         []string        `json:"parent_ids" gorm:"size:1024;serializer:json"`          // Roles whose menus, permissions and data scopes are inherited
+	CreatedAt         time.Time       `json:"created_at" gorm:"index;"`                             // Create time
+	UpdatedAt         time.Time       `json:"updated_at" gorm:"index;"`                             // Update time
+	Menus             RoleMenus       `json:"menus" gorm:"-"`                                       // Role menu list
+	Permissions       RolePermissions `json:"permissions" gorm:"-"`                                 // Role permission list
 }
 
 func (a *Role) TableName() string {
@@ -39,6 +52,7 @@ type RoleQueryParam struct {
 	Status      string     `form:"status" binding:"oneof=disabled enabled ''"` // Status of role (disabled, enabled)
 	ResultType  string     `form:"resultType"`                                 // Result type (options: select)
 	InIDs       []string   `form:"-"`                                          // ID list
+	ParentID    string     `form:"-"`                                          // Inherits the role
 	GtUpdatedAt *time.Time `form:"-"`                                          // Update time is greater than
 }
 
@@ -58,17 +72,24 @@ type Roles []*Role
 
 // Defining the data structure for creating a `Role` struct.
 type RoleForm struct {
-	Code        string          `json:"code" binding:"required,max=32"`                   // Code of role (unique)
-	Name        string          `json:"name" binding:"required,max=128"`                  // Display name of role
-	Description string          `json:"description"`                                      // Details about role
-	Sequence    int             `json:"sequence"`                                         // Sequence for sorting
-	Status      string          `json:"status" binding:"required,oneof=disabled enabled"` // Status of role (enabled, disabled)
-	Menus       RoleMenus       `json:"menus"`                                            // Role menu list
-	Permissions RolePermissions `json:"permissions"`                                      // Role permission list
+	Code              string          `json:"code" binding:"required,max=32"`                                         // Code of role (unique)
+	Name              string          `json:"name" binding:"required,max=128"`                                        // Display name of role
+	Description       string          `json:"description"`                                                            // Details about role
+	Sequence          int             `json:"sequence"`                                                               // Sequence for sorting
+	Status            string          `json:"status" binding:"required,oneof=disabled enabled"`                       // Status of role (enabled, disabled)
+	DataScope         string          `json:"data_scope" binding:"omitempty,oneof=all own custom department inherit"` // Rows visible to the role (all, own, custom, department, inherit), default inherit with parents, otherwise all
+	DataUserIDs       []string        `json:"data_user_ids"`                                                          // Users whose rows are visible to the custom data scope
+	DataDepartmentIDs []string        `json:"data_department_ids"`                                                    // Departments whose members' rows are visible to the custom data scope, sub-departments included
+	ParentIDs         []string        `json:"parent_ids"`                                                             // Roles whose menus, permissions and data scopes are inherited
+	Menus             RoleMenus       `json:"menus"`                                                                  // Role menu list
+	Permissions       RolePermissions `json:"permissions"`                                                            // Role permission list
 }
 
 // A validation function for the `RoleForm` struct.
 func (a *RoleForm) Validate() error {
+	if a.DataScope == RoleDataScopeInherit && len(a.ParentIDs) == 0 {
+		return errors.BadRequest("", "The inherit data scope requires parent roles")
+	}
 	return nil
 }
 
@@ -78,5 +99,24 @@ func (a *RoleForm) FillTo(role *Role) error {
 	role.Description = a.Description
 	role.Sequence = a.Sequence
 	role.Status = a.Status
+	role.DataScope = a.DataScope
+	if role.DataScope == "" {
+		role.DataScope = RoleDataScopeAll
+		if len(a.ParentIDs) > 0 {
+			role.DataScope = RoleDataScopeInherit
+		}
+	}
+	role.DataUserIDs = nil
+	role.DataDepartmentIDs = nil
+	if role.DataScope == RoleDataScopeCustom {
+		role.DataUserIDs = a.DataUserIDs
+		role.DataDepartmentIDs = a.DataDepartmentIDs
+	}
+	role.ParentIDs = nil
+	for _, parentID := range a.ParentIDs {
+		if !slices.Contains(role.ParentIDs, parentID) {
+			role.ParentIDs = append(role.ParentIDs, parentID)
+		}
+	}
 	return nil
 }
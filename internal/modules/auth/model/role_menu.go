@@ -23,7 +23,8 @@ func (a *RoleMenu) TableName() string {
 // Defining the query parameters for the `RoleMenu` struct.
 type RoleMenuQueryParam struct {
 	util.PaginationParam
-	RoleID string `form:"-"` // From Role.ID
+	RoleID    string   `form:"-"` // From Role.ID
+	InMenuIDs []string `form:"-"` // From Menu.ID
 }
 
 // Defining the query options for the `RoleMenu` struct.
@@ -40,6 +41,19 @@ type RoleMenuQueryResult struct {
 // Defining the slice of `RoleMenu` struct.
 type RoleMenus []*RoleMenu
 
+func (a RoleMenus) ToRoleIDs() []string {
+	var ids []string
+	m := make(map[string]struct{})
+	for _, item := range a {
+		if _, ok := m[item.RoleID]; ok {
+			continue
+		}
+		m[item.RoleID] = struct{}{}
+		ids = append(ids, item.RoleID)
+	}
+	return ids
+}
+
 // Defining the data structure for creating a `RoleMenu` struct.
 type RoleMenuForm struct {
 }
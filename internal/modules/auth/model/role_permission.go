@@ -60,6 +60,19 @@ func (a RolePermissions) ToPermissionIDs() []string {
 	return ids
 }
 
+func (a RolePermissions) ToRoleIDs() []string {
+	var ids []string
+	m := make(map[string]struct{})
+	for _, item := range a {
+		if _, ok := m[item.RoleID]; ok {
+			continue
+		}
+		m[item.RoleID] = struct{}{}
+		ids = append(ids, item.RoleID)
+	}
+	return ids
+}
+
 // Defining the data structure for creating a `RolePermission` struct.
 type RolePermissionForm struct {
 }
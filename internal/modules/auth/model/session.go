@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+)
+
+// Login session of a user, the access tokens and refresh tokens issued by a login share the session.
+type Session struct {
+	ID         string    `json:"id"`           // Unique ID
+	UserID     string    `json:"user_id"`      // From User.ID
+	Device     string    `json:"device"`       // Device parsed from the user agent
+	IP         string    `json:"ip"`           // Client IP of the last request
+	UserAgent  string    `json:"user_agent"`   // User agent of the last request
+	IssuedAt   time.Time `json:"issued_at"`    // Login time
+	LastSeenAt time.Time `json:"last_seen_at"` // Time of the last request
+	ExpiresAt  time.Time `json:"expires_at"`   // Expiration time of the refresh token
+	Current    bool      `json:"current"`      // Session of the current request
+}
+
+// Defining the slice of `Session` struct.
+type Sessions []*Session
+
+func (a Sessions) Len() int {
+	return len(a)
+}
+
+func (a Sessions) Less(i, j int) bool {
+	return a[i].LastSeenAt.After(a[j].LastSeenAt)
+}
+
+func (a Sessions) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
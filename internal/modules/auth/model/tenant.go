@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/pkg/util"
+)
+
+const (
+	TenantStatusDisabled = "disabled"
+	TenantStatusEnabled  = "enabled"
+)
+
+// Tenant of the deployment, the users, roles, menus, permissions and logs are isolated by tenant
+type Tenant struct {
+	ID          string    `json:"id" gorm:"size:20;primarykey;"` // Unique ID
+	Code        string    `json:"code" gorm:"size:32;index;"`    // Code of tenant (unique)
+	Name        string    `json:"name" gorm:"size:128;index"`    // Display name of tenant
+	Description string    `json:"description" gorm:"size:1024"`  // Details about tenant
+	Status      string    `json:"status" gorm:"size:20;index"`   // Status of tenant (disabled, enabled)
+	CreatedAt   time.Time `json:"created_at" gorm:"index;"`      // Create time
+	UpdatedAt   time.Time `json:"updated_at" gorm:"index;"`      // Update time
+}
+
+func (a *Tenant) TableName() string {
+	return config.C.FormatTableName("tenants")
+}
+
+// Defining the query parameters for the `Tenant` struct.
+type TenantQueryParam struct {
+	util.PaginationParam
+	LikeName string `form:"name"`                                       // Display name of tenant
+	Status   string `form:"status" binding:"oneof=disabled enabled ''"` // Status of tenant (disabled, enabled)
+}
+
+// Defining the query options for the `Tenant` struct.
+type TenantQueryOptions struct {
+	util.QueryOptions
+}
+
+// Defining the query result for the `Tenant` struct.
+type TenantQueryResult struct {
+	Data       Tenants
+	PageResult *util.PaginationResult
+}
+
+// Defining the slice of `Tenant` struct.
+type Tenants []*Tenant
+
+// Defining the data structure for creating a `Tenant` struct.
+type TenantForm struct {
+	Code        string `json:"code" binding:"required,max=32"`                   // Code of tenant (unique)
+	Name        string `json:"name" binding:"required,max=128"`                  // Display name of tenant
+	Description string `json:"description"`                                      // Details about tenant
+	Status      string `json:"status" binding:"required,oneof=disabled enabled"` // Status of tenant (disabled, enabled)
+}
+
+// A validation function for the `TenantForm` struct.
+func (a *TenantForm) Validate() error {
+	return nil
+}
+
+func (a *TenantForm) FillTo(tenant *Tenant) error {
+	tenant.Code = a.Code
+	tenant.Name = a.Name
+	tenant.Description = a.Description
+	tenant.Status = a.Status
+	return nil
+}
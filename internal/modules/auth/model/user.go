@@ -18,18 +18,26 @@ const (
 
 // User management for RBAC
 type User struct {
-	ID        string    `json:"id" gorm:"size:20;primarykey;"`    // Unique ID
-	Email     string    `json:"email" gorm:"size:255;index"`      // Email for login
-	FirstName string    `json:"first_name" gorm:"size:100;index"` // First Name of user
-	LastName  string    `json:"last_name" gorm:"size:100;index"`  // Last Name of user
-	FullName  string    `json:"full_name" gorm:"size:255;index"`  // Full Name of user
-	Password  string    `json:"-" gorm:"size:255;"`               // Password for login (encrypted)
-	Phone     string    `json:"phone" gorm:"size:32;"`            // Phone number of user
-	Remark    string    `json:"remark" gorm:"size:1024;"`         // Remark of user
-	Status    string    `json:"status" gorm:"size:20;index"`      // Status of user (active, inactive)
-	CreatedAt time.Time `json:"created_at" gorm:"index;"`         // Create time
-	UpdatedAt time.Time `json:"updated_at" gorm:"index;"`         // Update time
-	Roles     UserRoles `json:"roles" gorm:"-"`                   // Roles of user
+	ID             string          `json:"id" gorm:"size:20;primarykey;"`      // Unique ID
+	TenantID       string          `json:"tenant_id" gorm:"size:20;index;"`    // From Tenant.ID, empty for the default tenant
+	Email          string          `json:"email" gorm:"size:255;index"`        // Email for login
+	FirstName      string          `json:"first_name" gorm:"size:100;index"`   // First Name of user
+	LastName       string          `json:"last_name" gorm:"size:100;index"`    // Last Name of user
+	FullName       string          `json:"full_name" gorm:"size:255;index"`    // Full Name of user
+	Password       string          `json:"-" gorm:"size:255;"`                 // Password for login (encrypted)
+	Phone          string          `json:"phone" gorm:"size:32;"`              // Phone number of user
+	Remark         string          `json:"remark" gorm:"size:1024;"`           // Remark of user
+	Status         string          `json:"status" gorm:"size:20;index"`        // Status of user (active, inactive)
+	Unverified     bool            `json:"unverified" gorm:"index"`            // Registered but the email is not verified yet
+	MFAEnabled     bool            `json:"mfa_enabled"`                        // TOTP two-factor authentication is enabled
+	MFASecret      string          `json:"-" gorm:"size:255;"`                 // TOTP secret (encrypted)
+	MFACodes       string          `json:"-" gorm:"size:1024;"`                // Unused recovery codes (sha256 hashes, comma separated)
+	PwdChangedAt   *time.Time      `json:"password_changed_at"`                // Last change of the password
+	CreatedAt      time.Time       `json:"created_at" gorm:"index;"`           // Create time
+	UpdatedAt      time.Time       `json:"updated_at" gorm:"index;"`           // Update time
+	Roles          UserRoles       `json:"roles" gorm:"-"`                     // Roles of user
+	Departments    UserDepartments `json:"departments" gorm:"-"`               // Departments of user
+	InheritedRoles UserRoles       `json:"inherited_roles,omitempty" gorm:"-"` // Roles inherited from the roles of user
 }
 
 func (a *User) TableName() string {
@@ -39,9 +47,10 @@ func (a *User) TableName() string {
 // Defining the query parameters for the `User` struct.
 type UserQueryParam struct {
 	util.PaginationParam
-	LikeEmail    string `form:"email"`                                  // Email for login
-	LikeFullName string `form:"full_name"`                              // Full Name of user
-	Status       string `form:"status" binding:"oneof=active inactive"` // Status of user (active, inactive)
+	LikeEmail    string `form:"email"`                                     // Email for login
+	LikeFullName string `form:"full_name"`                                 // Full Name of user
+	Status       string `form:"status" binding:"oneof=active inactive ''"` // Status of user (active, inactive)
+	DepartmentID string `form:"department_id"`                             // Members of the department and its sub-departments
 }
 
 // Defining the query options for the `User` struct.
@@ -68,14 +77,16 @@ func (a Users) ToIDs() []string {
 
 // Defining the data structure for creating a `User` struct.
 type UserForm struct {
-	Email     string    `json:"email" binding:"required,max=128"`                // Username for login
-	FirstName string    `json:"first_name" binding:"required,max=64"`            // First Name of user
-	LastName  string    `json:"last_name" binding:"required,max=64"`             // Last Name of user
-	Password  string    `json:"password" binding:"required,max=64"`              // Password for login (md5 hash)
-	Phone     string    `json:"phone" binding:"max=32"`                          // Phone number of user
-	Remark    string    `json:"remark" binding:"max=1024"`                       // Remark of user
-	Status    string    `json:"status" binding:"required,oneof=active inactive"` // Status of user (active, inactive)
-	Roles     UserRoles `json:"roles"`                                           // Roles of user
+	Email       string          `json:"email" binding:"required,max=128"`                // Username for login
+	FirstName   string          `json:"first_name" binding:"required,max=64"`            // First Name of user
+	LastName    string          `json:"last_name" binding:"required,max=64"`             // Last Name of user
+	Password    string          `json:"password" binding:"required,max=64"`              // Password for login (md5 hash)
+	Phone       string          `json:"phone" binding:"max=32"`                          // Phone number of user
+	Remark      string          `json:"remark" binding:"max=1024"`                       // Remark of user
+	Status      string          `json:"status" binding:"required,oneof=active inactive"` // Status of user (active, inactive)
+	Roles       UserRoles       `json:"roles"`                                           // Roles of user
+	Departments UserDepartments `json:"departments"`                                     // Departments of user
+	Unverified  bool            `json:"-"`                                               // Registered with email verification (Set by Auth.Register)
 }
 
 // A validation function for the `UserForm` struct.
@@ -83,6 +94,11 @@ func (a *UserForm) Validate() error {
 	if a.Email != "" && validator.New().Var(a.Email, "email") != nil {
 		return errors.BadRequest("", "Invalid email address")
 	}
+	for _, userRole := range a.Roles {
+		if userRole.ValidFrom != nil && userRole.ValidUntil != nil && !userRole.ValidUntil.After(*userRole.ValidFrom) {
+			return errors.BadRequest("", "The end of the role assignment must be after its start")
+		}
+	}
 	return nil
 }
 
@@ -95,6 +111,12 @@ func (a *UserForm) FillTo(user *User) error {
 	user.Phone = a.Phone
 	user.Remark = a.Remark
 	user.Status = a.Status
+	// Activating the user also completes its pending email verification
+	if a.Unverified {
+		user.Unverified = true
+	} else if a.Status == UserStatusActive {
+		user.Unverified = false
+	}
 
 	if pass := a.Password; pass != "" {
 		hashPass, err := hash.GeneratePassword(pass)
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/pkg/util"
+)
+
+// Departments of users
+type UserDepartment struct {
+	ID             string    `json:"id" gorm:"size:20;primarykey"`                 // Unique ID
+	UserID         string    `json:"user_id" gorm:"size:20;index"`                 // From User.ID
+	DepartmentID   string    `json:"department_id" gorm:"size:20;index"`           // From Department.ID
+	Leader         bool      `json:"leader"`                                       // The user leads the department
+	CreatedAt      time.Time `json:"created_at" gorm:"index;"`                     // Create time
+	UpdatedAt      time.Time `json:"updated_at" gorm:"index;"`                     // Update time
+	DepartmentName string    `json:"department_name" gorm:"<-:false;-:migration;"` // From Department.Name
+}
+
+func (a *UserDepartment) TableName() string {
+	return config.C.FormatTableName("user_departments")
+}
+
+// Defining the query parameters for the `UserDepartment` struct.
+type UserDepartmentQueryParam struct {
+	util.PaginationParam
+	InUserIDs       []string `form:"-"` // From User.ID
+	UserID          string   `form:"-"` // From User.ID
+	InDepartmentIDs []string `form:"-"` // From Department.ID
+	DepartmentID    string   `form:"-"` // From Department.ID
+	Leader          bool     `form:"-"` // Only the leaders
+}
+
+// Defining the query options for the `UserDepartment` struct.
+type UserDepartmentQueryOptions struct {
+	util.QueryOptions
+	JoinDepartment bool // Join department table
+}
+
+// Defining the query result for the `UserDepartment` struct.
+type UserDepartmentQueryResult struct {
+	Data       UserDepartments
+	PageResult *util.PaginationResult
+}
+
+// Defining the slice of `UserDepartment` struct.
+type UserDepartments []*UserDepartment
+
+func (a UserDepartments) ToUserIDMap() map[string]UserDepartments {
+	m := make(map[string]UserDepartments)
+	for _, userDepartment := range a {
+		m[userDepartment.UserID] = append(m[userDepartment.UserID], userDepartment)
+	}
+	return m
+}
+
+func (a UserDepartments) ToDepartmentIDs() []string {
+	var ids []string
+	for _, item := range a {
+		ids = append(ids, item.DepartmentID)
+	}
+	return ids
+}
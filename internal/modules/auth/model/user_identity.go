@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"go-admin/internal/config"
+)
+
+// Accounts of users at external identity providers
+type UserIdentity struct {
+	ID          string     `json:"id" gorm:"size:20;primarykey;"`                                  // Unique ID
+	TenantID    string     `json:"tenant_id" gorm:"size:20;uniqueIndex:idx_user_identity_subject"` // From Tenant.ID, empty for the default tenant
+	UserID      string     `json:"user_id" gorm:"size:20;index"`                                   // From User.ID
+	Provider    string     `json:"provider" gorm:"size:64;uniqueIndex:idx_user_identity_subject"`  // Name of the identity provider
+	Subject     string     `json:"subject" gorm:"size:255;uniqueIndex:idx_user_identity_subject"`  // Subject (user ID) at the identity provider
+	Email       string     `json:"email" gorm:"size:255;"`                                         // Email at the identity provider
+	LastLoginAt *time.Time `json:"last_login_at"`                                                  // Last login with the identity
+	CreatedAt   time.Time  `json:"created_at" gorm:"index;"`                                       // Create time
+}
+
+func (a *UserIdentity) TableName() string {
+	return config.C.FormatTableName("user_identities")
+}
+
+// Defining the slice of `UserIdentity` struct.
+type UserIdentities []*UserIdentity
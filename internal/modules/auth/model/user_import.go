@@ -0,0 +1,35 @@
+package model
+
+const (
+	UserFileFormatCSV  = "csv"
+	UserFileFormatXLSX = "xlsx"
+)
+
+// Header of the user import and export files, the roles are role codes separated by `;`
+var UserFileColumns = []string{"email", "first_name", "last_name", "phone", "status", "roles"}
+
+// Defining the parameters for importing users.
+type UserImportParam struct {
+	DryRun bool `form:"dry_run"` // Only validate the rows, no user is created
+}
+
+// Defining the parameters for exporting users, the filters are the same as the user list.
+type UserExportParam struct {
+	UserQueryParam
+	Format string `form:"format" binding:"oneof=csv xlsx ''"` // File format (csv, xlsx), default csv
+}
+
+// Result of the user import, no user is created if any row fails.
+type UserImportResult struct {
+	DryRun  bool               `json:"dry_run"` // Only validated
+	Total   int                `json:"total"`   // Rows of users in the file
+	Created int                `json:"created"` // Created users
+	Errors  []*UserImportError `json:"errors"`  // Errors of the invalid rows
+}
+
+// Defining the error of a row of the user import.
+type UserImportError struct {
+	Row     int    `json:"row"`     // Row number in the file, the header is row 1
+	Email   string `json:"email"`   // Email of the row
+	Message string `json:"message"` // Reason of the error
+}
@@ -9,24 +9,35 @@ import (
 
 // User roles for RBAC
 type UserRole struct {
-	ID        string    `json:"id" gorm:"size:20;primarykey"`           // Unique ID
-	UserID    string    `json:"user_id" gorm:"size:20;index"`           // From User.ID
-	RoleID    string    `json:"role_id" gorm:"size:20;index"`           // From Role.ID
-	CreatedAt time.Time `json:"created_at" gorm:"index;"`               // Create time
-	UpdatedAt time.Time `json:"updated_at" gorm:"index;"`               // Update time
-	RoleName  string    `json:"role_name" gorm:"<-:false;-:migration;"` // From Role.Name
+	ID         string     `json:"id" gorm:"size:20;primarykey"`           // Unique ID
+	UserID     string     `json:"user_id" gorm:"size:20;index"`           // From User.ID
+	RoleID     string     `json:"role_id" gorm:"size:20;index"`           // From Role.ID
+	ValidFrom  *time.Time `json:"valid_from" gorm:"index;"`               // The role is assigned from this time, always if empty
+	ValidUntil *time.Time `json:"valid_until" gorm:"index;"`              // The role is assigned until this time, always if empty
+	CreatedAt  time.Time  `json:"created_at" gorm:"index;"`               // Create time
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"index;"`               // Update time
+	RoleName   string     `json:"role_name" gorm:"<-:false;-:migration;"` // From Role.Name
+	RoleCode   string     `json:"role_code" gorm:"<-:false;-:migration;"` // From Role.Code
 }
 
 func (a *UserRole) TableName() string {
 	return config.C.FormatTableName("user_roles")
 }
 
+// Check if the role is assigned at the time
+func (a *UserRole) IsValidAt(t time.Time) bool {
+	return (a.ValidFrom == nil || !a.ValidFrom.After(t)) && (a.ValidUntil == nil || a.ValidUntil.After(t))
+}
+
 // Defining the query parameters for the `UserRole` struct.
 type UserRoleQueryParam struct {
 	util.PaginationParam
-	InUserIDs []string `form:"-"` // From User.ID
-	UserID    string   `form:"-"` // From User.ID
-	RoleID    string   `form:"-"` // From Role.ID
+	InUserIDs     []string   `form:"-"` // From User.ID
+	UserID        string     `form:"-"` // From User.ID
+	RoleID        string     `form:"-"` // From Role.ID
+	ValidAt       *time.Time `form:"-"` // The assignment is valid at the time
+	GtBoundaryAt  *time.Time `form:"-"` // The assignment starts or ends after the time
+	LteBoundaryAt *time.Time `form:"-"` // The assignment starts or ends before or at the time
 }
 
 // Defining the query options for the `UserRole` struct.
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+// Get API key storage instance
+func GetAPIKeyDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return util.GetDB(ctx, defDB).Model(new(model.APIKey))
+}
+
+// Personal API keys of users
+type APIKey struct {
+	DB *gorm.DB
+}
+
+// Query the API keys of the user, the newest first.
+func (a *APIKey) QueryByUserID(ctx context.Context, userID string) (model.APIKeys, error) {
+	var list model.APIKeys
+	result := GetAPIKeyDB(ctx, a.DB).Where("user_id=?", userID).Order("created_at DESC").Find(&list)
+	return list, errors.WithStack(result.Error)
+}
+
+// Get the API key of the user.
+func (a *APIKey) Get(ctx context.Context, userID, id string) (*model.APIKey, error) {
+	item := new(model.APIKey)
+	ok, err := util.FindOne(ctx, GetAPIKeyDB(ctx, a.DB).Where("id=? AND user_id=?", id, userID), util.QueryOptions{}, item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item, nil
+}
+
+func (a *APIKey) GetByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	item := new(model.APIKey)
+	ok, err := util.FindOne(ctx, GetAPIKeyDB(ctx, a.DB).Where("key_hash=?", keyHash), util.QueryOptions{}, item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item, nil
+}
+
+func (a *APIKey) Create(ctx context.Context, item *model.APIKey) error {
+	result := GetAPIKeyDB(ctx, a.DB).Create(item)
+	return errors.WithStack(result.Error)
+}
+
+func (a *APIKey) UpdateLastUsed(ctx context.Context, id string, usedAt time.Time, ip string) error {
+	result := GetAPIKeyDB(ctx, a.DB).Where("id=?", id).Updates(map[string]interface{}{
+		"last_used_at": usedAt,
+		"last_used_ip": ip,
+	})
+	return errors.WithStack(result.Error)
+}
+
+func (a *APIKey) Delete(ctx context.Context, id string) error {
+	result := GetAPIKeyDB(ctx, a.DB).Where("id=?", id).Delete(new(model.APIKey))
+	return errors.WithStack(result.Error)
+}
+
+func (a *APIKey) DeleteByUserID(ctx context.Context, userID string) error {
+	result := GetAPIKeyDB(ctx, a.DB).Where("user_id=?", userID).Delete(new(model.APIKey))
+	return errors.WithStack(result.Error)
+}
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"context"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+// Get department storage instance
+func GetDepartmentDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return util.GetDB(ctx, defDB).Model(new(model.Department))
+}
+
+// Department management for auth
+type Department struct {
+	DB *gorm.DB
+}
+
+// Query departments from the database based on the provided parameters and options.
+func (a *Department) Query(ctx context.Context, params model.DepartmentQueryParam, opts ...model.DepartmentQueryOptions) (*model.DepartmentQueryResult, error) {
+	var opt model.DepartmentQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db := GetDepartmentDB(ctx, a.DB)
+
+	if v := params.InIDs; len(v) > 0 {
+		db = db.Where("id IN ?", v)
+	}
+	if v := params.LikeName; len(v) > 0 {
+		db = db.Where("name LIKE ?", "%"+v+"%")
+	}
+	if v := params.Status; len(v) > 0 {
+		db = db.Where("status = ?", v)
+	}
+	if v := params.ParentID; len(v) > 0 {
+		db = db.Where("parent_id = ?", v)
+	}
+	if v := params.ParentPathPrefix; len(v) > 0 {
+		db = db.Where("parent_path LIKE ?", v+"%")
+	}
+
+	var list model.Departments
+	pageResult, err := util.WrapPageQuery(ctx, db, params.PaginationParam, opt.QueryOptions, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	queryResult := &model.DepartmentQueryResult{
+		PageResult: pageResult,
+		Data:       list,
+	}
+	return queryResult, nil
+}
+
+// Get the specified department from the database.
+func (a *Department) Get(ctx context.Context, id string, opts ...model.DepartmentQueryOptions) (*model.Department, error) {
+	var opt model.DepartmentQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	item := new(model.Department)
+	ok, err := util.FindOne(ctx, GetDepartmentDB(ctx, a.DB).Where("id=?", id), opt.QueryOptions, item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item, nil
+}
+
+// Checks if the specified department exists in the database.
+func (a *Department) Exists(ctx context.Context, id string) (bool, error) {
+	ok, err := util.Exists(ctx, GetDepartmentDB(ctx, a.DB).Where("id=?", id))
+	return ok, errors.WithStack(err)
+}
+
+// Checks if a department with the specified `code` exists under the specified `parentID` in the database.
+func (a *Department) ExistsCodeByParentID(ctx context.Context, code, parentID string) (bool, error) {
+	ok, err := util.Exists(ctx, GetDepartmentDB(ctx, a.DB).Where("code=? AND parent_id=?", code, parentID))
+	return ok, errors.WithStack(err)
+}
+
+// Checks if the specified department has sub-departments in the database.
+func (a *Department) ExistsChildren(ctx context.Context, id string) (bool, error) {
+	ok, err := util.Exists(ctx, GetDepartmentDB(ctx, a.DB).Where("parent_id=?", id))
+	return ok, errors.WithStack(err)
+}
+
+// Create a new department.
+func (a *Department) Create(ctx context.Context, item *model.Department) error {
+	result := GetDepartmentDB(ctx, a.DB).Create(item)
+	return errors.WithStack(result.Error)
+}
+
+// Update the specified department in the database.
+func (a *Department) Update(ctx context.Context, item *model.Department) error {
+	result := GetDepartmentDB(ctx, a.DB).Where("id=?", item.ID).Select("*").Omit("created_at").Updates(item)
+	return errors.WithStack(result.Error)
+}
+
+// Delete the specified department from the database.
+func (a *Department) Delete(ctx context.Context, id string) error {
+	result := GetDepartmentDB(ctx, a.DB).Where("id=?", id).Delete(new(model.Department))
+	return errors.WithStack(result.Error)
+}
+
+// Updates the parent path of the specified department.
+func (a *Department) UpdateParentPath(ctx context.Context, id, parentPath string) error {
+	result := GetDepartmentDB(ctx, a.DB).Where("id=?", id).Update("parent_path", parentPath)
+	return errors.WithStack(result.Error)
+}
+
+// Updates the status of all departments whose parent path starts with the provided parent path.
+func (a *Department) UpdateStatusByParentPath(ctx context.Context, parentPath, status string) error {
+	result := GetDepartmentDB(ctx, a.DB).Where("parent_path like ?", parentPath+"%").Update("status", status)
+	return errors.WithStack(result.Error)
+}
@@ -0,0 +1,119 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+// Get invitation storage instance
+func GetInvitationDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return util.GetDB(ctx, defDB).Model(new(model.Invitation))
+}
+
+// Invitations of users by email
+type Invitation struct {
+	DB *gorm.DB
+}
+
+// Query invitations from the database based on the provided parameters and options.
+func (a *Invitation) Query(ctx context.Context, params model.InvitationQueryParam, opts ...model.InvitationQueryOptions) (*model.InvitationQueryResult, error) {
+	var opt model.InvitationQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db := GetInvitationDB(ctx, a.DB)
+	if v := params.LikeEmail; len(v) > 0 {
+		db = db.Where("email LIKE ?", "%"+v+"%")
+	}
+	if v := params.Status; len(v) > 0 {
+		db = db.Where("status = ?", v)
+	}
+
+	var list model.Invitations
+	pageResult, err := util.WrapPageQuery(ctx, db, params.PaginationParam, opt.QueryOptions, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	queryResult := &model.InvitationQueryResult{
+		PageResult: pageResult,
+		Data:       list,
+	}
+	return queryResult, nil
+}
+
+// Get the specified invitation from the database.
+func (a *Invitation) Get(ctx context.Context, id string, opts ...model.InvitationQueryOptions) (*model.Invitation, error) {
+	var opt model.InvitationQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	item := new(model.Invitation)
+	ok, err := util.FindOne(ctx, GetInvitationDB(ctx, a.DB).Where("id=?", id), opt.QueryOptions, item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item, nil
+}
+
+// Get the invitation of the invite token hash.
+func (a *Invitation) GetByTokenHash(ctx context.Context, tokenHash string, opts ...model.InvitationQueryOptions) (*model.Invitation, error) {
+	var opt model.InvitationQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	item := new(model.Invitation)
+	ok, err := util.FindOne(ctx, GetInvitationDB(ctx, a.DB).Where("token_hash=?", tokenHash), opt.QueryOptions, item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item, nil
+}
+
+// ExistsPendingEmail checks if a pending invitation of the email exists in the database.
+func (a *Invitation) ExistsPendingEmail(ctx context.Context, email string) (bool, error) {
+	ok, err := util.Exists(ctx, GetInvitationDB(ctx, a.DB).Where("email=? AND status=?", email, model.InvitationStatusPending))
+	return ok, errors.WithStack(err)
+}
+
+// Create a new invitation.
+func (a *Invitation) Create(ctx context.Context, item *model.Invitation) error {
+	result := GetInvitationDB(ctx, a.DB).Create(item)
+	return errors.WithStack(result.Error)
+}
+
+// Update the specified invitation in the database.
+func (a *Invitation) Update(ctx context.Context, item *model.Invitation, selectFields ...string) error {
+	db := GetInvitationDB(ctx, a.DB).Where("id=?", item.ID)
+	if len(selectFields) > 0 {
+		db = db.Select(selectFields)
+	} else {
+		db = db.Select("*").Omit("created_at")
+	}
+	result := db.Updates(item)
+	return errors.WithStack(result.Error)
+}
+
+// Mark the pending invitation as accepted by the user, returns false if it's not pending anymore.
+func (a *Invitation) Accept(ctx context.Context, id, userID string, acceptedAt time.Time) (bool, error) {
+	result := GetInvitationDB(ctx, a.DB).Where("id=? AND status=?", id, model.InvitationStatusPending).
+		Select("status", "user_id", "accepted_at", "updated_at").
+		Updates(model.Invitation{Status: model.InvitationStatusAccepted, UserID: userID, AcceptedAt: &acceptedAt, UpdatedAt: acceptedAt})
+	if result.Error != nil {
+		return false, errors.WithStack(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"context"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+// Get login history storage instance
+func GetLoginHistoryDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return util.GetDB(ctx, defDB).Model(new(model.LoginHistory))
+}
+
+// Login attempts of users
+type LoginHistory struct {
+	DB *gorm.DB
+}
+
+// Query login histories from the database based on the provided parameters and options.
+func (a *LoginHistory) Query(ctx context.Context, params model.LoginHistoryQueryParam, opts ...model.LoginHistoryQueryOptions) (*model.LoginHistoryQueryResult, error) {
+	var opt model.LoginHistoryQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db := GetLoginHistoryDB(ctx, a.DB)
+	if v := params.UserID; len(v) > 0 {
+		db = db.Where("user_id = ?", v)
+	}
+	if v := params.Success; v != nil {
+		db = db.Where("success = ?", *v)
+	}
+
+	var list model.LoginHistories
+	pageResult, err := util.WrapPageQuery(ctx, db, params.PaginationParam, opt.QueryOptions, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	queryResult := &model.LoginHistoryQueryResult{
+		PageResult: pageResult,
+		Data:       list,
+	}
+	return queryResult, nil
+}
+
+// Check if the user logged in successfully before, with the IP or the user agent if not empty.
+func (a *LoginHistory) ExistsSuccess(ctx context.Context, userID, ip, userAgent string) (bool, error) {
+	db := GetLoginHistoryDB(ctx, a.DB).Where("user_id = ? AND success = ?", userID, true)
+	if ip != "" {
+		db = db.Where("ip = ?", ip)
+	}
+	if userAgent != "" {
+		db = db.Where("user_agent = ?", userAgent)
+	}
+	ok, err := util.Exists(ctx, db)
+	return ok, errors.WithStack(err)
+}
+
+func (a *LoginHistory) Create(ctx context.Context, item *model.LoginHistory) error {
+	result := GetLoginHistoryDB(ctx, a.DB).Create(item)
+	return errors.WithStack(result.Error)
+}
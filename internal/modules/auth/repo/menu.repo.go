@@ -53,6 +53,10 @@ func (a *Menu) Query(ctx context.Context, params model.MenuQueryParam, opts ...m
 		roleMenuQuery := GetRoleMenuDB(ctx, a.DB).Where("role_id = ?", v).Select("menu_id")
 		db = db.Where("id IN (?)", roleMenuQuery)
 	}
+	if v := params.InRoleIDs; len(v) > 0 {
+		roleMenuQuery := GetRoleMenuDB(ctx, a.DB).Where("role_id IN (?)", v).Select("menu_id")
+		db = db.Where("id IN (?)", roleMenuQuery)
+	}
 
 	var list model.Menus
 	pageResult, err := util.WrapPageQuery(ctx, db, params.PaginationParam, opt.QueryOptions, &list)
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+// Get passkey storage instance
+func GetPasskeyDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return util.GetDB(ctx, defDB).Model(new(model.Passkey))
+}
+
+// WebAuthn credentials of users
+type Passkey struct {
+	DB *gorm.DB
+}
+
+// Query the passkeys of the user, the newest first.
+func (a *Passkey) QueryByUserID(ctx context.Context, userID string) (model.Passkeys, error) {
+	var list model.Passkeys
+	result := GetPasskeyDB(ctx, a.DB).Where("user_id=?", userID).Order("created_at DESC").Find(&list)
+	return list, errors.WithStack(result.Error)
+}
+
+// Get the passkey of the user.
+func (a *Passkey) Get(ctx context.Context, userID, id string) (*model.Passkey, error) {
+	item := new(model.Passkey)
+	ok, err := util.FindOne(ctx, GetPasskeyDB(ctx, a.DB).Where("id=? AND user_id=?", id, userID), util.QueryOptions{}, item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item, nil
+}
+
+func (a *Passkey) GetByCredentialID(ctx context.Context, credentialID string) (*model.Passkey, error) {
+	item := new(model.Passkey)
+	ok, err := util.FindOne(ctx, GetPasskeyDB(ctx, a.DB).Where("credential_id=?", credentialID), util.QueryOptions{}, item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item, nil
+}
+
+func (a *Passkey) Create(ctx context.Context, item *model.Passkey) error {
+	result := GetPasskeyDB(ctx, a.DB).Create(item)
+	return errors.WithStack(result.Error)
+}
+
+func (a *Passkey) UpdateSignCount(ctx context.Context, id string, signCount uint32, usedAt time.Time) error {
+	result := GetPasskeyDB(ctx, a.DB).Where("id=?", id).Updates(map[string]interface{}{
+		"sign_count":   signCount,
+		"last_used_at": usedAt,
+	})
+	return errors.WithStack(result.Error)
+}
+
+func (a *Passkey) Delete(ctx context.Context, id string) error {
+	result := GetPasskeyDB(ctx, a.DB).Where("id=?", id).Delete(new(model.Passkey))
+	return errors.WithStack(result.Error)
+}
+
+func (a *Passkey) DeleteByUserID(ctx context.Context, userID string) error {
+	result := GetPasskeyDB(ctx, a.DB).Where("user_id=?", userID).Delete(new(model.Passkey))
+	return errors.WithStack(result.Error)
+}
@@ -0,0 +1,51 @@
+package repo
+
+import (
+	"context"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+// Get password history storage instance
+func GetPasswordHistoryDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return util.GetDB(ctx, defDB).Model(new(model.PasswordHistory))
+}
+
+// Previous passwords of users
+type PasswordHistory struct {
+	DB *gorm.DB
+}
+
+// Query the most recent `limit` passwords of the user, the newest first.
+func (a *PasswordHistory) QueryRecent(ctx context.Context, userID string, limit int) (model.PasswordHistories, error) {
+	var list model.PasswordHistories
+	result := GetPasswordHistoryDB(ctx, a.DB).Where("user_id=?", userID).Order("created_at DESC").Limit(limit).Find(&list)
+	return list, errors.WithStack(result.Error)
+}
+
+func (a *PasswordHistory) Create(ctx context.Context, item *model.PasswordHistory) error {
+	result := GetPasswordHistoryDB(ctx, a.DB).Create(item)
+	return errors.WithStack(result.Error)
+}
+
+// Delete all but the most recent `keep` passwords of the user.
+func (a *PasswordHistory) Prune(ctx context.Context, userID string, keep int) error {
+	var ids []string
+	result := GetPasswordHistoryDB(ctx, a.DB).Where("user_id=?", userID).Order("created_at DESC").Pluck("id", &ids)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	} else if len(ids) <= keep {
+		return nil
+	}
+	result = GetPasswordHistoryDB(ctx, a.DB).Where("id IN (?)", ids[keep:]).Delete(new(model.PasswordHistory))
+	return errors.WithStack(result.Error)
+}
+
+func (a *PasswordHistory) DeleteByUserID(ctx context.Context, userID string) error {
+	result := GetPasswordHistoryDB(ctx, a.DB).Where("user_id=?", userID).Delete(new(model.PasswordHistory))
+	return errors.WithStack(result.Error)
+}
@@ -37,6 +37,9 @@ func (a *Role) Query(ctx context.Context, params model.RoleQueryParam, opts ...m
 	if v := params.Status; len(v) > 0 {
 		db = db.Where("status = ?", v)
 	}
+	if v := params.ParentID; len(v) > 0 {
+		db = db.Where("parent_ids LIKE ?", "%\""+v+"\"%")
+	}
 	if v := params.GtUpdatedAt; v != nil {
 		db = db.Where("updated_at > ?", v)
 	}
@@ -31,6 +31,9 @@ func (a *RoleMenu) Query(ctx context.Context, params model.RoleMenuQueryParam, o
 	if v := params.RoleID; len(v) > 0 {
 		db = db.Where("role_id = ?", v)
 	}
+	if v := params.InMenuIDs; len(v) > 0 {
+		db = db.Where("menu_id IN (?)", v)
+	}
 
 	var list model.RoleMenus
 	pageResult, err := util.WrapPageQuery(ctx, db, params.PaginationParam, opt.QueryOptions, &list)
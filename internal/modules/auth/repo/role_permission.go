@@ -106,3 +106,8 @@ func (a *RolePermission) DeleteByRoleID(ctx context.Context, roleID string) erro
 	result := GetRolePermissionDB(ctx, a.DB).Where("role_id=?", roleID).Delete(new(model.RolePermission))
 	return errors.WithStack(result.Error)
 }
+
+func (a *RolePermission) DeleteByPermissionID(ctx context.Context, permissionID string) error {
+	result := GetRolePermissionDB(ctx, a.DB).Where("permission_id=?", permissionID).Delete(new(model.RolePermission))
+	return errors.WithStack(result.Error)
+}
@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"context"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+// Get tenant storage instance
+func GetTenantDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return util.GetDB(ctx, defDB).Model(new(model.Tenant))
+}
+
+// Tenant management for auth
+type Tenant struct {
+	DB *gorm.DB
+}
+
+// Query tenants from the database based on the provided parameters and options.
+func (a *Tenant) Query(ctx context.Context, params model.TenantQueryParam, opts ...model.TenantQueryOptions) (*model.TenantQueryResult, error) {
+	var opt model.TenantQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db := GetTenantDB(ctx, a.DB)
+	if v := params.LikeName; len(v) > 0 {
+		db = db.Where("name LIKE ?", "%"+v+"%")
+	}
+	if v := params.Status; len(v) > 0 {
+		db = db.Where("status = ?", v)
+	}
+
+	var list model.Tenants
+	pageResult, err := util.WrapPageQuery(ctx, db, params.PaginationParam, opt.QueryOptions, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	queryResult := &model.TenantQueryResult{
+		PageResult: pageResult,
+		Data:       list,
+	}
+	return queryResult, nil
+}
+
+// Get the specified tenant from the database.
+func (a *Tenant) Get(ctx context.Context, id string, opts ...model.TenantQueryOptions) (*model.Tenant, error) {
+	var opt model.TenantQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	item := new(model.Tenant)
+	ok, err := util.FindOne(ctx, GetTenantDB(ctx, a.DB).Where("id=?", id), opt.QueryOptions, item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item, nil
+}
+
+// Exist checks if the specified tenant exists in the database.
+func (a *Tenant) Exists(ctx context.Context, id string) (bool, error) {
+	ok, err := util.Exists(ctx, GetTenantDB(ctx, a.DB).Where("id=?", id))
+	return ok, errors.WithStack(err)
+}
+
+func (a *Tenant) ExistsCode(ctx context.Context, code string) (bool, error) {
+	ok, err := util.Exists(ctx, GetTenantDB(ctx, a.DB).Where("code=?", code))
+	return ok, errors.WithStack(err)
+}
+
+// Create a new tenant.
+func (a *Tenant) Create(ctx context.Context, item *model.Tenant) error {
+	result := GetTenantDB(ctx, a.DB).Create(item)
+	return errors.WithStack(result.Error)
+}
+
+// Update the specified tenant in the database.
+func (a *Tenant) Update(ctx context.Context, item *model.Tenant) error {
+	result := GetTenantDB(ctx, a.DB).Where("id=?", item.ID).Select("*").Omit("created_at").Updates(item)
+	return errors.WithStack(result.Error)
+}
+
+// Delete the specified tenant from the database.
+func (a *Tenant) Delete(ctx context.Context, id string) error {
+	result := GetTenantDB(ctx, a.DB).Where("id=?", id).Delete(new(model.Tenant))
+	return errors.WithStack(result.Error)
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"go-admin/internal/modules/auth/model"
 	"go-admin/pkg/errors"
@@ -27,7 +28,7 @@ func (a *User) Query(ctx context.Context, params model.UserQueryParam, opts ...m
 		opt = opts[0]
 	}
 
-	db := GetUserDB(ctx, a.DB)
+	db := GetUserDB(ctx, a.DB).Scopes(util.DataScopeFunc(ctx, "id", DataScopeDepartmentUsers(ctx, a.DB)))
 	if v := params.LikeEmail; len(v) > 0 {
 		db = db.Where("email LIKE ?", "%"+v+"%")
 	}
@@ -37,6 +38,9 @@ func (a *User) Query(ctx context.Context, params model.UserQueryParam, opts ...m
 	if v := params.Status; len(v) > 0 {
 		db = db.Where("status = ?", v)
 	}
+	if v := params.DepartmentID; len(v) > 0 {
+		db = db.Where("id IN (?)", GetDepartmentUserIDsDB(ctx, a.DB, []string{v}))
+	}
 
 	var list model.Users
 	pageResult, err := util.WrapPageQuery(ctx, db, params.PaginationParam, opt.QueryOptions, &list)
@@ -120,6 +124,17 @@ func (a *User) Delete(ctx context.Context, id string) error {
 }
 
 func (a *User) UpdatePasswordByID(ctx context.Context, id string, password string) error {
-	result := GetUserDB(ctx, a.DB).Where("id=?", id).Select("password").Updates(model.User{Password: password})
+	now := time.Now()
+	result := GetUserDB(ctx, a.DB).Where("id=?", id).Select("password", "pwd_changed_at").Updates(model.User{Password: password, PwdChangedAt: &now})
 	return errors.WithStack(result.Error)
 }
+
+// Replace the recovery codes of the user only if they are still the given ones, so a code can't be consumed twice.
+// It reports whether the codes were replaced.
+func (a *User) ReplaceMFACodes(ctx context.Context, id string, oldCodes, newCodes string) (bool, error) {
+	result := GetUserDB(ctx, a.DB).Where("id=? AND mfa_codes=?", id, oldCodes).Select("mfa_codes", "updated_at").Updates(model.User{MFACodes: newCodes, UpdatedAt: time.Now()})
+	if err := result.Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+	return result.RowsAffected > 0, nil
+}
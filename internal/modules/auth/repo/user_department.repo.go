@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+// Get user department storage instance
+func GetUserDepartmentDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return util.GetDB(ctx, defDB).Model(new(model.UserDepartment))
+}
+
+// Get the subquery of the IDs of the users in the departments or their sub-departments
+func GetDepartmentUserIDsDB(ctx context.Context, defDB *gorm.DB, departmentIDs []string) *gorm.DB {
+	conds := []string{"id IN ?"}
+	args := []interface{}{departmentIDs}
+	for _, id := range departmentIDs {
+		conds = append(conds, "parent_path LIKE ?")
+		args = append(args, "%"+id+util.TreePathDelimiter+"%")
+	}
+	departmentQuery := GetDepartmentDB(ctx, defDB).Where(strings.Join(conds, " OR "), args...).Select("id")
+	return GetUserDepartmentDB(ctx, defDB).Where("department_id IN (?)", departmentQuery).Select("user_id")
+}
+
+// Builds the subquery of the department data scopes for `util.DataScopeFunc`
+func DataScopeDepartmentUsers(ctx context.Context, defDB *gorm.DB) func(departmentIDs []string) *gorm.DB {
+	return func(departmentIDs []string) *gorm.DB {
+		return GetDepartmentUserIDsDB(ctx, defDB, departmentIDs)
+	}
+}
+
+// Departments of users for auth
+type UserDepartment struct {
+	DB *gorm.DB
+}
+
+// Query user departments from the database based on the provided parameters and options.
+func (a *UserDepartment) Query(ctx context.Context, params model.UserDepartmentQueryParam, opts ...model.UserDepartmentQueryOptions) (*model.UserDepartmentQueryResult, error) {
+	var opt model.UserDepartmentQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db := util.GetDB(ctx, a.DB).Table(fmt.Sprintf("%s AS a", new(model.UserDepartment).TableName()))
+	if opt.JoinDepartment {
+		db = db.Joins(fmt.Sprintf("left join %s b on a.department_id=b.id", new(model.Department).TableName()))
+		db = db.Select("a.*,b.name as department_name")
+	}
+
+	if v := params.InUserIDs; len(v) > 0 {
+		db = db.Where("a.user_id IN (?)", v)
+	}
+	if v := params.UserID; len(v) > 0 {
+		db = db.Where("a.user_id = ?", v)
+	}
+	if v := params.InDepartmentIDs; len(v) > 0 {
+		db = db.Where("a.department_id IN (?)", v)
+	}
+	if v := params.DepartmentID; len(v) > 0 {
+		db = db.Where("a.department_id = ?", v)
+	}
+	if params.Leader {
+		db = db.Where("a.leader = ?", true)
+	}
+
+	var list model.UserDepartments
+	pageResult, err := util.WrapPageQuery(ctx, db, params.PaginationParam, opt.QueryOptions, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	queryResult := &model.UserDepartmentQueryResult{
+		PageResult: pageResult,
+		Data:       list,
+	}
+	return queryResult, nil
+}
+
+// Checks if the specified department has members in the database.
+func (a *UserDepartment) ExistsByDepartmentID(ctx context.Context, departmentID string) (bool, error) {
+	ok, err := util.Exists(ctx, GetUserDepartmentDB(ctx, a.DB).Where("department_id=?", departmentID))
+	return ok, errors.WithStack(err)
+}
+
+// Create a new user department.
+func (a *UserDepartment) Create(ctx context.Context, item *model.UserDepartment) error {
+	result := GetUserDepartmentDB(ctx, a.DB).Create(item)
+	return errors.WithStack(result.Error)
+}
+
+func (a *UserDepartment) DeleteByUserID(ctx context.Context, userID string) error {
+	result := GetUserDepartmentDB(ctx, a.DB).Where("user_id=?", userID).Delete(new(model.UserDepartment))
+	return errors.WithStack(result.Error)
+}
+
+func (a *UserDepartment) DeleteByDepartmentID(ctx context.Context, departmentID string) error {
+	result := GetUserDepartmentDB(ctx, a.DB).Where("department_id=?", departmentID).Delete(new(model.UserDepartment))
+	return errors.WithStack(result.Error)
+}
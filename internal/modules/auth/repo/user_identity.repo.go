@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+// Get user identity storage instance
+func GetUserIdentityDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return util.GetDB(ctx, defDB).Model(new(model.UserIdentity))
+}
+
+// Accounts of users at external identity providers
+type UserIdentity struct {
+	DB *gorm.DB
+}
+
+// Get the identity of the subject at the provider.
+func (a *UserIdentity) GetBySubject(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	item := new(model.UserIdentity)
+	ok, err := util.FindOne(ctx, GetUserIdentityDB(ctx, a.DB).Where("provider=? AND subject=?", provider, subject), util.QueryOptions{}, item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item, nil
+}
+
+func (a *UserIdentity) Create(ctx context.Context, item *model.UserIdentity) error {
+	result := GetUserIdentityDB(ctx, a.DB).Create(item)
+	return errors.WithStack(result.Error)
+}
+
+func (a *UserIdentity) UpdateLastLogin(ctx context.Context, id string, email string) error {
+	result := GetUserIdentityDB(ctx, a.DB).Where("id=?", id).Updates(map[string]interface{}{
+		"email":         email,
+		"last_login_at": time.Now(),
+	})
+	return errors.WithStack(result.Error)
+}
+
+func (a *UserIdentity) DeleteByUserID(ctx context.Context, userID string) error {
+	result := GetUserIdentityDB(ctx, a.DB).Where("user_id=?", userID).Delete(new(model.UserIdentity))
+	return errors.WithStack(result.Error)
+}
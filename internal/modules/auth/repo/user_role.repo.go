@@ -31,7 +31,7 @@ func (a *UserRole) Query(ctx context.Context, params model.UserRoleQueryParam, o
 	db := a.DB.Table(fmt.Sprintf("%s AS a", new(model.UserRole).TableName()))
 	if opt.JoinRole {
 		db = db.Joins(fmt.Sprintf("left join %s b on a.role_id=b.id", new(model.Role).TableName()))
-		db = db.Select("a.*,b.name as role_name")
+		db = db.Select("a.*,b.name as role_name,b.code as role_code")
 	}
 
 	if v := params.InUserIDs; len(v) > 0 {
@@ -43,6 +43,14 @@ func (a *UserRole) Query(ctx context.Context, params model.UserRoleQueryParam, o
 	if v := params.RoleID; len(v) > 0 {
 		db = db.Where("a.role_id = ?", v)
 	}
+	if v := params.ValidAt; v != nil {
+		db = db.Where("(a.valid_from IS NULL OR a.valid_from <= ?) AND (a.valid_until IS NULL OR a.valid_until > ?)", v, v)
+	}
+	if start, end := params.GtBoundaryAt, params.LteBoundaryAt; start != nil && end != nil {
+		db = db.Where("((a.valid_from > ? AND a.valid_from <= ?) OR (a.valid_until > ? AND a.valid_until <= ?))", start, end, start, end)
+	} else if start != nil {
+		db = db.Where("(a.valid_from > ? OR a.valid_until > ?)", start, start)
+	}
 
 	var list model.UserRoles
 	pageResult, err := util.WrapPageQuery(ctx, db, params.PaginationParam, opt.QueryOptions, &list)
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/repo"
+	"go-admin/pkg/cachex"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// Refresh the users whose time-bound role assignments started or ended, their caches are
+// deleted so the roles are computed again on the next request.
+type RoleSweeper struct {
+	ticker       *time.Ticker       `wire:"-"`
+	cancel       context.CancelFunc `wire:"-"`
+	lock         sync.Mutex         `wire:"-"`
+	last         time.Time          `wire:"-"`
+	Cache        cachex.Cacher
+	UserRoleRepo *repo.UserRole
+}
+
+func (a *RoleSweeper) Start(ctx context.Context) {
+	a.last = time.Now()
+	ctx, a.cancel = context.WithCancel(ctx)
+	a.ticker = time.NewTicker(time.Duration(config.C.General.RoleAssignment.SweepInterval) * time.Second)
+	go a.autoSweep(ctx)
+}
+
+// Stopping the ticker doesn't close its channel, the goroutine returns when the context is canceled.
+func (a *RoleSweeper) autoSweep(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-a.ticker.C:
+			if err := a.Sweep(ctx, now); err != nil {
+				logging.Context(ctx).Error("Failed to sweep role assignments", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Delete the caches of the users whose role assignments started or ended since the last sweep.
+func (a *RoleSweeper) Sweep(ctx context.Context, now time.Time) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !now.After(a.last) {
+		return nil
+	}
+	userRoleResult, err := a.UserRoleRepo.Query(ctx, model.UserRoleQueryParam{
+		GtBoundaryAt:  &a.last,
+		LteBoundaryAt: &now,
+	}, model.UserRoleQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"user_id"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	userIDs := make(map[string]struct{})
+	for _, userRole := range userRoleResult.Data {
+		if _, ok := userIDs[userRole.UserID]; ok {
+			continue
+		}
+		userIDs[userRole.UserID] = struct{}{}
+		if err := a.Cache.Delete(ctx, config.CacheNSForUser, userRole.UserID); err != nil {
+			return err
+		}
+	}
+	a.last = now
+
+	if len(userIDs) > 0 {
+		logging.Context(ctx).Info("Refreshed users with started or ended roles", zap.Int("users", len(userIDs)))
+	}
+	return nil
+}
+
+func (a *RoleSweeper) Release(ctx context.Context) error {
+	if a.ticker != nil {
+		a.ticker.Stop()
+	}
+	if a.cancel != nil {
+		a.cancel()
+	}
+	return nil
+}
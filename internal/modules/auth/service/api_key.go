@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/repo"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/crypto/rand"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+const (
+	apiKeyPrefix        = "gak_"      // Prefix of the keys to recognize them, e.g. in secret scanners
+	apiKeyPrefixLength  = 12          // Leading characters of the key stored in plain text
+	apiKeyTouchInterval = time.Minute // Minimum interval between two updates of the last used time
+)
+
+// Personal API keys of users for machine access
+type APIKey struct {
+	APIKeyRepo   *repo.APIKey
+	UserRoleRepo *repo.UserRole
+}
+
+// Query the API keys of the user.
+func (a *APIKey) Query(ctx context.Context, userID string) (model.APIKeys, error) {
+	return a.APIKeyRepo.QueryByUserID(ctx, userID)
+}
+
+// Create an API key for the user, the returned key is shown only once and stored as hash.
+func (a *APIKey) Create(ctx context.Context, userID string, formItem *model.APIKeyForm) (*model.APIKey, error) {
+	if userID == config.C.General.Root.ID {
+		return nil, errors.BadRequest("", "The root user can't create API keys")
+	} else if formItem.ExpiresAt != nil && formItem.ExpiresAt.Before(time.Now()) {
+		return nil, errors.BadRequest("", "Expiration time must be in the future")
+	}
+
+	// the key can't be granted more roles than the user currently has
+	if len(formItem.RoleIDs) > 0 {
+		now := time.Now()
+		userRoleResult, err := a.UserRoleRepo.Query(ctx, model.UserRoleQueryParam{
+			UserID:  userID,
+			ValidAt: &now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		userRoleIDs := make(map[string]bool)
+		for _, roleID := range userRoleResult.Data.ToRoleIDs() {
+			userRoleIDs[roleID] = true
+		}
+		for _, roleID := range formItem.RoleIDs {
+			if !userRoleIDs[roleID] {
+				return nil, errors.BadRequest("", "Role %s is not granted to the user", roleID)
+			}
+		}
+	}
+
+	key, err := rand.Random(40, rand.LdigitAndLetter)
+	if err != nil {
+		return nil, err
+	}
+	key = apiKeyPrefix + key
+
+	apiKey := &model.APIKey{
+		ID:        util.NewXID(),
+		UserID:    userID,
+		Name:      formItem.Name,
+		Prefix:    key[:apiKeyPrefixLength],
+		KeyHash:   hash.SHA256String(key),
+		RoleIDs:   formItem.RoleIDs,
+		ExpiresAt: formItem.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	if err := a.APIKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+	logging.Context(ctx).Info("Create API key", zap.String("key_id", apiKey.ID), zap.String("user_id", userID))
+
+	apiKey.Key = key
+	return apiKey, nil
+}
+
+// Revoke the API key of the user, it becomes invalid immediately.
+func (a *APIKey) Revoke(ctx context.Context, userID, id string) error {
+	apiKey, err := a.APIKeyRepo.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	} else if apiKey == nil {
+		return errors.NotFound("", "API key not found")
+	}
+
+	if err := a.APIKeyRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	logging.Context(ctx).Info("Revoke API key", zap.String("key_id", id), zap.String("user_id", userID))
+	return nil
+}
+
+// Delete all API keys of the user.
+func (a *APIKey) Clear(ctx context.Context, userID string) error {
+	return a.APIKeyRepo.DeleteByUserID(ctx, userID)
+}
+
+// Verify the key of a request and record its use, returns nil if the key is unknown or expired.
+func (a *APIKey) Verify(ctx context.Context, key string) (*model.APIKey, error) {
+	apiKey, err := a.APIKeyRepo.GetByHash(ctx, hash.SHA256String(key))
+	if err != nil || apiKey == nil || apiKey.Expired() {
+		return nil, err
+	}
+
+	client := util.FromClientInfo(ctx)
+	if apiKey.LastUsedAt == nil || time.Since(*apiKey.LastUsedAt) >= apiKeyTouchInterval || apiKey.LastUsedIP != client.IP {
+		if err := a.APIKeyRepo.UpdateLastUsed(ctx, apiKey.ID, time.Now(), client.IP); err != nil {
+			logging.Context(ctx).Error("Failed to update API key", zap.Error(err), zap.String("key_id", apiKey.ID))
+		}
+	}
+	return apiKey, nil
+}
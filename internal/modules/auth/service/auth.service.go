@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"go-admin/internal/config"
@@ -11,8 +15,10 @@ import (
 	"go-admin/internal/modules/auth/repo"
 	"go-admin/pkg/cachex"
 	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/crypto/rand"
 	"go-admin/pkg/errors"
 	"go-admin/pkg/jwtx"
+	"go-admin/pkg/ldapx"
 	"go-admin/pkg/logging"
 	"go-admin/pkg/util"
 
@@ -23,13 +29,23 @@ import (
 
 // Login management for RBAC
 type Auth struct {
-	Cache        cachex.Cacher
-	Auth         jwtx.Auther
-	UserRepo     *repo.User
-	UserRoleRepo *repo.UserRole
-	MenuRepo     *repo.Menu
-	UserService  *User
-	Trans        *util.Trans
+	Cache               cachex.Cacher
+	Auth                jwtx.Auther
+	UserRepo            *repo.User
+	UserRoleRepo        *repo.UserRole
+	RoleRepo            *repo.Role
+	MenuRepo            *repo.Menu
+	UserService         *User
+	SessionService      *Session
+	LockoutService      *Lockout
+	PasswordService     *Password
+	LDAPService         *LDAP
+	APIKeyService       *APIKey
+	PasskeyService      *Passkey
+	LoginHistoryService *LoginHistory
+	InvitationService   *Invitation
+	TenantRepo          *repo.Tenant
+	Trans               *util.Trans
 }
 
 func (a *Auth) ParseUserID(c *gin.Context) (string, error) {
@@ -39,59 +55,146 @@ func (a *Auth) ParseUserID(c *gin.Context) (string, error) {
 	}
 
 	invalidToken := errors.Unauthorized(config.ErrInvalidTokenID, "Invalid access token")
-	token := util.GetToken(c)
-	if token == "" {
-		return "", invalidToken
-	}
+	ctx := util.GetClientContext(c)
 
-	ctx := c.Request.Context()
-	ctx = util.NewUserToken(ctx, token)
-
-	userID, err := a.Auth.ParseSubject(ctx, token)
-	if err != nil {
-		if err == jwtx.ErrInvalidToken {
+	var userID string
+	var apiKey *model.APIKey
+	var actorCache *util.UserCache
+	if key := util.GetAPIKey(c); key != "" {
+		var err error
+		apiKey, err = a.APIKeyService.Verify(ctx, key)
+		if err != nil {
+			return "", err
+		} else if apiKey == nil {
+			return "", errors.Unauthorized(config.ErrInvalidTokenID, "Invalid API key")
+		}
+		userID = apiKey.UserID
+		ctx = util.NewAPIKeyID(ctx, apiKey.ID)
+	} else {
+		token := util.GetToken(c)
+		if token == "" {
 			return "", invalidToken
 		}
-		return "", err
-	} else if userID == rootID {
+		ctx = util.NewUserToken(ctx, token)
+
+		claims, err := a.Auth.ParseClaims(ctx, token)
+		if err != nil {
+			if err == jwtx.ErrInvalidToken {
+				return "", invalidToken
+			}
+			return "", err
+		}
+		userID = claims.Subject
+
+		if claims.Actor != "" {
+			// the impersonation ends if the impersonator is no longer active
+			if claims.Actor != rootID {
+				if actorCache, err = a.getUserCache(ctx, claims.Actor); err != nil {
+					return "", err
+				} else if actorCache == nil {
+					return "", invalidToken
+				}
+			}
+			ctx = util.NewImpersonatorID(ctx, claims.Actor)
+		} else if err := a.SessionService.Touch(ctx, userID, claims.SessionID); err != nil {
+			logging.Context(ctx).Error("Failed to update session", zap.Error(err))
+		}
+	}
+
+	if userID == rootID {
 		c.Request = c.Request.WithContext(util.NewIsRootUser(ctx))
 		return userID, nil
 	}
 
-	userCacheVal, ok, err := a.Cache.Get(ctx, config.CacheNSForUser, userID)
+	userCache, err := a.getUserCache(ctx, userID)
 	if err != nil {
 		return "", err
+	} else if userCache == nil {
+		return "", invalidToken
+	}
+
+	// the impersonation ends if the impersonator lost a role of the user, both caches are refreshed on role changes
+	if actorCache != nil && !hasAllRoles(actorCache.RoleIDs, userCache.RoleIDs) {
+		logging.Context(ctx).Warn("Impersonator no longer has the roles of the user", zap.String("impersonator_id", util.FromImpersonatorID(ctx)))
+		return "", invalidToken
+	}
+
+	// the key is restricted to its roles the user still has
+	if apiKey != nil && len(apiKey.RoleIDs) > 0 {
+		var roleIDs []string
+		for _, roleID := range userCache.RoleIDs {
+			for _, keyRoleID := range apiKey.RoleIDs {
+				if roleID == keyRoleID {
+					roleIDs = append(roleIDs, roleID)
+					break
+				}
+			}
+		}
+		if len(roleIDs) != len(userCache.RoleIDs) {
+			keyCache, err := a.UserService.NewUserCache(ctx, userID, roleIDs)
+			if err != nil {
+				return "", err
+			}
+			userCache = &keyCache
+		}
+	}
+
+	// the data of the user is scoped to its tenant, whatever the tenant header is
+	ctx = util.NewTenantID(ctx, userCache.TenantID)
+	c.Request = c.Request.WithContext(util.NewUserCache(ctx, *userCache))
+	return userID, nil
+}
+
+// Get the roles and the data scope of the user from the cache or the database, returns nil if the user is not active.
+func (a *Auth) getUserCache(ctx context.Context, userID string) (*util.UserCache, error) {
+	userCacheVal, ok, err := a.Cache.Get(ctx, config.CacheNSForUser, userID)
+	if err != nil {
+		return nil, err
 	} else if ok {
 		userCache := util.ParseUserCache(userCacheVal)
-		c.Request = c.Request.WithContext(util.NewUserCache(ctx, userCache))
-		return userID, nil
+		return &userCache, nil
 	}
 
 	// Check user status, if not activated, force to logout
-	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
-		QueryOptions: util.QueryOptions{SelectFields: []string{"status"}},
+	user, err := a.UserRepo.Get(util.NewCrossTenant(ctx), userID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{SelectFields: []string{"status", "tenant_id"}},
 	})
 	if err != nil {
-		return "", err
+		return nil, err
 	} else if user == nil || user.Status != model.UserStatusActive {
-		return "", invalidToken
+		return nil, nil
+	} else if enabled, err := a.tenantEnabled(ctx, user.TenantID); err != nil || !enabled {
+		return nil, err
 	}
 
 	roleIDs, err := a.UserService.GetRoleIDs(ctx, userID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	userCache := util.UserCache{
-		RoleIDs: roleIDs,
-	}
-	err = a.Cache.Set(ctx, config.CacheNSForUser, userID, userCache.String())
+	userCache, err := a.UserService.NewUserCache(ctx, userID, roleIDs)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	if err := a.setUserCache(ctx, userID, userCache); err != nil {
+		return nil, err
+	}
+	return &userCache, nil
+}
 
-	c.Request = c.Request.WithContext(util.NewUserCache(ctx, userCache))
-	return userID, nil
+// Cache the user until its next role assignment starts or ends, so the roles are computed again
+// even if the role sweeper missed the boundary.
+func (a *Auth) setUserCache(ctx context.Context, userID string, userCache util.UserCache) error {
+	exp := time.Duration(config.C.Dictionary.UserCacheExp) * time.Hour
+	next, err := a.UserService.GetNextRoleBoundary(ctx, userID)
+	if err != nil {
+		return err
+	} else if next != nil {
+		if until := max(time.Until(*next), time.Second); exp <= 0 || until < exp {
+			exp = until
+		}
+	}
+	return a.Cache.Set(ctx, config.CacheNSForUser, userID, userCache.String(), exp)
 }
 
 // This function generates a new captcha ID and returns it as a `model.Captcha` struct. The length of
@@ -129,17 +232,23 @@ func (a *Auth) genUserToken(ctx context.Context, userID string) (*model.LoginTok
 		return nil, err
 	}
 
-	tokenBuf, err := token.EncodeToJSON()
-	if err != nil {
-		return nil, err
+	logging.Context(ctx).Info("Generate user token", zap.String("session_id", token.GetSessionID()),
+		zap.Int64("expires_at", token.GetExpiresAt()), zap.Int64("refresh_expires_at", token.GetRefreshExpiresAt()))
+
+	if err := a.SessionService.Create(ctx, userID, token); err != nil {
+		logging.Context(ctx).Error("Failed to create session", zap.Error(err))
 	}
-	logging.Context(ctx).Info("Generate user token", zap.Any("token", string(tokenBuf)))
+	return toLoginToken(token), nil
+}
 
+func toLoginToken(token jwtx.TokenInfo) *model.LoginToken {
 	return &model.LoginToken{
-		AccessToken: token.GetAccessToken(),
-		TokenType:   token.GetTokenType(),
-		ExpiresAt:   token.GetExpiresAt(),
-	}, nil
+		AccessToken:      token.GetAccessToken(),
+		TokenType:        token.GetTokenType(),
+		ExpiresAt:        token.GetExpiresAt(),
+		RefreshToken:     token.GetRefreshToken(),
+		RefreshExpiresAt: token.GetRefreshExpiresAt(),
+	}
 }
 
 func (a *Auth) Login(ctx context.Context, formItem *model.LoginForm) (*model.LoginToken, error) {
@@ -149,54 +258,209 @@ func (a *Auth) Login(ctx context.Context, formItem *model.LoginForm) (*model.Log
 	}
 
 	ctx = logging.NewTag(ctx, logging.TagKeyLogin)
+	ctx = withRequestTenant(ctx)
+	ctx, attempt := newLoginAttempt(ctx, model.LoginMethodPassword, formItem.Email)
+	token, err := a.login(ctx, formItem)
+	a.LoginHistoryService.Record(ctx, attempt, token, err)
+	return token, err
+}
+
+func (a *Auth) login(ctx context.Context, formItem *model.LoginForm) (*model.LoginToken, error) {
+	if err := a.LockoutService.Check(ctx, formItem.Email); err != nil {
+		return nil, err
+	}
+
 	// login by root
 	if formItem.Email == config.C.General.Root.Email {
-		if hash.MD5([]byte(formItem.Password)) != config.C.General.Root.Password {
-			return nil, errors.BadRequest(config.ErrInvalidUsernameOrPassword, "Incorrect email or password")
+		needsRehash, err := hash.VerifyConfigPassword(config.C.General.Root.Password, formItem.Password)
+		if err != nil {
+			return nil, a.loginFailed(ctx, formItem.Email)
 		}
 		userID := config.C.General.Root.ID
 		ctx = logging.NewUserID(ctx, userID)
+		setLoginAttempt(ctx, "", userID)
+		if err := a.LockoutService.Reset(ctx, formItem.Email); err != nil {
+			return nil, err
+		}
 		logging.Context(ctx).Info("Login by root")
+		if needsRehash {
+			// the configured hash can't be upgraded like the stored ones
+			logging.Context(ctx).Warn("Root password is hashed with a retired algorithm or parameters, please replace it with the output of the hash-password command",
+				zap.String("algorithm", hash.PasswordAlgorithm(config.C.General.Root.Password)))
+		}
 		return a.genUserToken(ctx, userID)
 	}
 
+	// login with the directory password, users not found in the directory may fall back to the local password
+	if config.C.General.LDAP.Enable {
+		user, err := a.LDAPService.Authenticate(ctx, formItem.Email, formItem.Password)
+		switch {
+		case err == nil:
+			setLoginAttempt(ctx, model.LoginMethodLDAP, user.ID)
+			if err := checkUserStatus(user); err != nil {
+				return nil, err
+			}
+			return a.loginAuthenticated(ctx, formItem.Email, user, false)
+		case errors.Is(err, ldapx.ErrUserNotFound) && config.C.General.LDAP.LocalFallback:
+		case errors.Is(err, ldapx.ErrUserNotFound), errors.Is(err, ldapx.ErrInvalidCredentials):
+			setLoginAttempt(ctx, model.LoginMethodLDAP, "")
+			return nil, a.loginFailed(ctx, formItem.Email)
+		default:
+			return nil, err
+		}
+	}
+
 	// get user info
 	user, err := a.UserRepo.GetByEmail(ctx, formItem.Email, model.UserQueryOptions{
 		QueryOptions: util.QueryOptions{
-			SelectFields: []string{"id", "password", "status"},
+			SelectFields: []string{"id", "tenant_id", "password", "status", "unverified", "mfa_enabled"},
 		},
 	})
 	if err != nil {
 		return nil, err
 	} else if user == nil {
-		return nil, errors.BadRequest(config.ErrInvalidUsernameOrPassword, "Incorrect email or password")
-	} else if user.Status != model.UserStatusActive {
-		return nil, errors.BadRequest("", "User status is not active, please contact the administrator")
+		return nil, a.loginFailed(ctx, formItem.Email)
+	}
+	setLoginAttempt(ctx, "", user.ID)
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
 	}
 
 	// check password
-	if err := hash.CompareHashAndPassword(user.Password, formItem.Password); err != nil {
-		return nil, errors.BadRequest(config.ErrInvalidUsernameOrPassword, "Incorrect email or password")
+	needsRehash, err := hash.VerifyPassword(user.Password, formItem.Password)
+	if err != nil {
+		return nil, a.loginFailed(ctx, formItem.Email)
+	} else if needsRehash {
+		a.rehashPassword(ctx, user.ID, formItem.Password)
+	}
+	return a.loginAuthenticated(ctx, formItem.Email, user, true)
+}
+
+// Upgrade the stored hash to the configured algorithm and parameters, the password is only known
+// at the login. The login continues with the old hash if the upgrade fails.
+func (a *Auth) rehashPassword(ctx context.Context, userID, password string) {
+	newPassword, err := hash.GeneratePassword(password)
+	if err == nil {
+		err = a.UserRepo.Update(ctx, &model.User{ID: userID, Password: newPassword}, "password")
+	}
+	if err != nil {
+		logging.Context(ctx).Error("Failed to rehash password", zap.Error(err), zap.String("user_id", userID))
+		return
+	}
+	logging.Context(ctx).Info("Rehash password", zap.String("user_id", userID),
+		zap.String("algorithm", hash.DefaultPasswordHasher().Name()))
+}
+
+// Users are looked up by email in the tenant of the request, the default tenant if the request has none.
+func withRequestTenant(ctx context.Context) context.Context {
+	if _, ok := util.FromTenantID(ctx); !ok {
+		return util.NewTenantID(ctx, "")
+	}
+	return ctx
+}
+
+// Check if the tenant exists and is enabled, the default tenant is always enabled.
+func (a *Auth) tenantEnabled(ctx context.Context, tenantID string) (bool, error) {
+	if tenantID == "" {
+		return true, nil
+	}
+
+	tenant, err := a.TenantRepo.Get(ctx, tenantID, model.TenantQueryOptions{
+		QueryOptions: util.QueryOptions{SelectFields: []string{"status"}},
+	})
+	if err != nil {
+		return false, err
 	}
+	return tenant != nil && tenant.Status == model.TenantStatusEnabled, nil
+}
 
+func (a *Auth) checkTenant(ctx context.Context, tenantID string) error {
+	if enabled, err := a.tenantEnabled(ctx, tenantID); err != nil {
+		return err
+	} else if !enabled {
+		return errors.BadRequest("", "Tenant is not available, please contact the administrator")
+	}
+	return nil
+}
+
+func checkUserStatus(user *model.User) error {
+	if user.Status != model.UserStatusActive {
+		if user.Unverified {
+			return errors.BadRequest(config.ErrEmailNotVerified, "Email is not verified, please check your inbox for the verification email")
+		}
+		return errors.BadRequest("", "User status is not active, please contact the administrator")
+	}
+	return nil
+}
+
+// Continue the login of the user with a verified password, the expiry of the local password
+// is not checked for logins with the directory password.
+func (a *Auth) loginAuthenticated(ctx context.Context, email string, user *model.User, localPassword bool) (*model.LoginToken, error) {
 	userID := user.ID
 	ctx = logging.NewUserID(ctx, userID)
+	setLoginAttempt(ctx, "", userID)
+	if err := a.checkTenant(ctx, user.TenantID); err != nil {
+		return nil, err
+	}
+
+	// the access token is issued after the two-factor challenge is completed,
+	// so the failures are forgotten when the challenge succeeds
+	if user.MFAEnabled {
+		return a.newMFAChallenge(ctx, userID)
+	}
+
+	if err := a.LockoutService.Reset(ctx, email); err != nil {
+		return nil, err
+	}
+	logging.Context(ctx).Info("Login success", zap.String("email", email))
+	if !localPassword {
+		return a.issueLoginToken(ctx, userID)
+	}
+	return a.completeLogin(ctx, userID)
+}
+
+// Record the failed login and return the error for the client, a lockout error if it locks the login.
+func (a *Auth) loginFailed(ctx context.Context, email string) error {
+	if err := a.LockoutService.Fail(ctx, email); err != nil {
+		return err
+	}
+	return errors.BadRequest(config.ErrInvalidUsernameOrPassword, "Incorrect email or password")
+}
+
+// Check the password expiry and generate the access token.
+func (a *Auth) completeLogin(ctx context.Context, userID string) (*model.LoginToken, error) {
+	// the access token is issued after the expired password is changed
+	if config.C.General.PasswordPolicy.MaxAge > 0 {
+		user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+			QueryOptions: util.QueryOptions{
+				SelectFields: []string{"password", "created_at", "pwd_changed_at"},
+			},
+		})
+		if err != nil {
+			return nil, err
+		} else if user != nil && a.PasswordService.Expired(user) {
+			return a.newChangePasswordChallenge(ctx, userID)
+		}
+	}
+
+	return a.issueLoginToken(ctx, userID)
+}
 
-	// set user cache with role ids
+// Set user cache with role ids and generate the access token.
+func (a *Auth) issueLoginToken(ctx context.Context, userID string) (*model.LoginToken, error) {
 	roleIDs, err := a.UserService.GetRoleIDs(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	userCache := util.UserCache{RoleIDs: roleIDs}
-	err = a.Cache.Set(ctx, config.CacheNSForUser, userID, userCache.String(),
-		time.Duration(config.C.Dictionary.UserCacheExp)*time.Hour)
+	userCache, err := a.UserService.NewUserCache(ctx, userID, roleIDs)
 	if err != nil {
+		return nil, err
+	}
+	if err := a.setUserCache(ctx, userID, userCache); err != nil {
 		logging.Context(ctx).Error("Failed to set cache", zap.Error(err))
 	}
-	logging.Context(ctx).Info("Login success", zap.String("email", formItem.Email))
 
-	// generate token
 	return a.genUserToken(ctx, userID)
 }
 
@@ -206,48 +470,114 @@ func (a *Auth) Register(ctx context.Context, formItem *model.RegisterForm) (*mod
 		return nil, errors.BadRequest(config.ErrInvalidCaptchaID, "Incorrect captcha")
 	}
 	ctx = logging.NewTag(ctx, logging.TagKeyRegister)
+	ctx = withRequestTenant(ctx)
+	if tenantID, _ := util.FromTenantID(ctx); tenantID != "" {
+		if err := a.checkTenant(ctx, tenantID); err != nil {
+			return nil, err
+		}
+	}
 	userForm := &model.UserForm{}
 	if err := formItem.FillTo(userForm); err != nil {
 		return nil, err
 	}
+
+	verification := config.C.General.RegisterVerification.Enable
+	if verification {
+		userForm.Status = model.UserStatusInactive
+		userForm.Unverified = true
+	}
+
 	user, err := a.UserService.Create(ctx, userForm)
 	if err != nil {
 		return nil, err
 	}
 	userID := user.ID
 	ctx = logging.NewUserID(ctx, userID)
-	// set user cache with role ids
-	roleIDs, err := a.UserService.GetRoleIDs(ctx, userID)
+
+	// The user can login after the email is verified
+	if verification {
+		if err := a.sendVerificationMail(ctx, user); err != nil {
+			logging.Context(ctx).Error("Failed to send verification email", zap.Error(err))
+		}
+		logging.Context(ctx).Info("Register success, waiting for email verification", zap.String("email", formItem.Email))
+		return nil, nil
+	}
+	logging.Context(ctx).Info("Register success", zap.String("email", formItem.Email))
+	return a.completeLogin(ctx, userID)
+}
+
+// Accept the invitation by creating the invited user, the user is logged in with the pre-assigned roles.
+func (a *Auth) AcceptInvitation(ctx context.Context, formItem *model.AcceptInvitationForm) (*model.LoginToken, error) {
+	ctx = logging.NewTag(ctx, logging.TagKeyRegister)
+	invitation, err := a.InvitationService.GetAcceptable(ctx, formItem.Token)
 	if err != nil {
 		return nil, err
 	}
-	userCache := util.UserCache{RoleIDs: roleIDs}
-	err = a.Cache.Set(ctx, config.CacheNSForUser, userID, userCache.String(),
-		time.Duration(config.C.Dictionary.UserCacheExp)*time.Hour)
+
+	// the user is created and logged in to the tenant of the invitation, whatever the tenant header is
+	ctx = util.NewTenantID(ctx, invitation.TenantID)
+	if invitation.TenantID != "" {
+		if err := a.checkTenant(ctx, invitation.TenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	userID, err := a.InvitationService.Accept(ctx, invitation, formItem)
 	if err != nil {
-		logging.Context(ctx).Error("Failed to set cache", zap.Error(err))
+		return nil, err
 	}
-	logging.Context(ctx).Info("Register success", zap.String("email", formItem.Email))
-	return a.genUserToken(ctx, userID)
+	ctx = logging.NewUserID(ctx, userID)
+	return a.completeLogin(ctx, userID)
 }
 
-func (a *Auth) RefreshToken(ctx context.Context) (*model.LoginToken, error) {
-	userID := util.FromUserID(ctx)
+// Exchange the refresh token for a new token pair, the refresh token is rotated on every use.
+func (a *Auth) RefreshToken(ctx context.Context, formItem *model.RefreshTokenForm) (*model.LoginToken, error) {
+	ctx = logging.NewTag(ctx, logging.TagKeyLogin)
+	invalidToken := errors.Unauthorized(config.ErrInvalidTokenID, "Invalid refresh token")
 
-	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
-		QueryOptions: util.QueryOptions{
-			SelectFields: []string{"status"},
-		},
-	})
+	token, err := a.Auth.RefreshToken(ctx, formItem.RefreshToken)
 	if err != nil {
+		if err == jwtx.ErrRefreshTokenReused {
+			logging.Context(ctx).Warn("Refresh token reused, the token family is revoked")
+			return nil, invalidToken
+		} else if err == jwtx.ErrInvalidToken {
+			return nil, invalidToken
+		}
 		return nil, err
-	} else if user == nil {
-		return nil, errors.BadRequest("", "Incorrect user")
-	} else if user.Status != model.UserStatusActive {
-		return nil, errors.BadRequest("", "User status is not activated, please contact the administrator")
 	}
 
-	return a.genUserToken(ctx, userID)
+	userID, err := a.Auth.ParseSubject(ctx, token.GetAccessToken())
+	if err != nil {
+		return nil, err
+	}
+	ctx = logging.NewUserID(ctx, userID)
+
+	if userID != config.C.General.Root.ID {
+		user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+			QueryOptions: util.QueryOptions{
+				SelectFields: []string{"status"},
+			},
+		})
+		if err != nil {
+			return nil, err
+		} else if user == nil || user.Status != model.UserStatusActive {
+			if err := a.Auth.DestroyToken(ctx, token.GetAccessToken()); err != nil {
+				logging.Context(ctx).Error("Failed to destroy token", zap.Error(err))
+			}
+			return nil, invalidToken
+		}
+	}
+
+	if err := a.SessionService.Refresh(ctx, userID, token); err != nil {
+		logging.Context(ctx).Error("Failed to update session", zap.Error(err))
+	}
+	logging.Context(ctx).Info("Refresh token success")
+	return toLoginToken(token), nil
+}
+
+// Public keys to verify access tokens signed with asymmetric keys, empty if tokens are signed with a secret key.
+func (a *Auth) JWKS(ctx context.Context) *jwtx.JSONWebKeySet {
+	return a.Auth.JWKS()
 }
 
 func (a *Auth) Logout(ctx context.Context) error {
@@ -257,11 +587,18 @@ func (a *Auth) Logout(ctx context.Context) error {
 	}
 
 	ctx = logging.NewTag(ctx, logging.TagKeyLogout)
+	_, sessionID, _ := a.Auth.ParseSession(ctx, userToken)
 	if err := a.Auth.DestroyToken(ctx, userToken); err != nil {
 		return err
 	}
 
 	userID := util.FromUserID(ctx)
+	if sessionID != "" {
+		if err := a.SessionService.revoke(ctx, userID, sessionID); err != nil {
+			logging.Context(ctx).Error("Failed to delete session", zap.Error(err))
+		}
+	}
+
 	err := a.Cache.Delete(ctx, config.CacheNSForUser, userID)
 	if err != nil {
 		logging.Context(ctx).Error("Failed to delete user cache", zap.Error(err))
@@ -303,6 +640,34 @@ func (a *Auth) GetUserInfo(ctx context.Context) (*model.User, error) {
 	}
 	user.Roles = userRoleResult.Data
 
+	var roleIDs []string
+	now := time.Now()
+	for _, userRole := range userRoleResult.Data {
+		if userRole.IsValidAt(now) {
+			roleIDs = append(roleIDs, userRole.RoleID)
+		}
+	}
+	inheritedIDs, err := expandRoleIDs(ctx, a.RoleRepo, roleIDs, true)
+	if err != nil {
+		return nil, err
+	} else if inheritedIDs = inheritedIDs[len(roleIDs):]; len(inheritedIDs) > 0 {
+		roleResult, err := a.RoleRepo.Query(ctx, model.RoleQueryParam{
+			InIDs: inheritedIDs,
+		}, model.RoleQueryOptions{
+			QueryOptions: util.QueryOptions{SelectFields: []string{"id", "name"}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range roleResult.Data {
+			user.InheritedRoles = append(user.InheritedRoles, &model.UserRole{
+				UserID:   userID,
+				RoleID:   role.ID,
+				RoleName: role.Name,
+			})
+		}
+	}
+
 	return user, nil
 }
 
@@ -311,11 +676,14 @@ func (a *Auth) UpdatePassword(ctx context.Context, updateItem *model.UpdateLogin
 	if util.FromIsRootUser(ctx) {
 		return errors.BadRequest("", "Root user cannot change password")
 	}
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return err
+	}
 
 	userID := util.FromUserID(ctx)
 	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
 		QueryOptions: util.QueryOptions{
-			SelectFields: []string{"password"},
+			SelectFields: []string{"id", "email", "password"},
 		},
 	})
 	if err != nil {
@@ -329,12 +697,377 @@ func (a *Auth) UpdatePassword(ctx context.Context, updateItem *model.UpdateLogin
 		return errors.BadRequest("", "Incorrect old password")
 	}
 
-	// update password
-	newPassword, err := hash.GeneratePassword(updateItem.NewPassword)
+	return a.changePassword(ctx, user, updateItem.NewPassword)
+}
+
+// Check the new password against the password policy and history, then replace the password of the user.
+func (a *Auth) changePassword(ctx context.Context, user *model.User, password string) error {
+	if err := a.PasswordService.Check(ctx, user.ID, user.Email, password, user.Password); err != nil {
+		return err
+	}
+	return a.setPassword(ctx, user, password)
+}
+
+// Replace the password of the user and record the old one in the password history, the new password must be checked before.
+func (a *Auth) setPassword(ctx context.Context, user *model.User, password string) error {
+	newPassword, err := hash.GeneratePassword(password)
 	if err != nil {
 		return err
 	}
-	return a.UserRepo.UpdatePasswordByID(ctx, userID, newPassword)
+
+	return a.Trans.Exec(ctx, func(ctx context.Context) error {
+		if err := a.UserRepo.UpdatePasswordByID(ctx, user.ID, newPassword); err != nil {
+			return err
+		}
+		return a.PasswordService.Record(ctx, user.ID, user.Password)
+	})
+}
+
+func (a *Auth) newChangePasswordChallenge(ctx context.Context, userID string) (*model.LoginToken, error) {
+	exp := time.Duration(config.C.General.PasswordPolicy.ChangeExp) * time.Second
+	token, err := a.issueToken(ctx, config.CacheNSForChangePwd, userID, exp)
+	if err != nil {
+		return nil, err
+	}
+	logging.Context(ctx).Info("Login requires to change the expired password")
+
+	return &model.LoginToken{
+		PasswordExpired: true,
+		PasswordToken:   token,
+		ExpiresAt:       time.Now().Add(exp).Unix(),
+	}, nil
+}
+
+// Change the expired password with the token returned by the login and complete the login.
+func (a *Auth) LoginChangePassword(ctx context.Context, formItem *model.LoginChangePasswordForm) (*model.LoginToken, error) {
+	ctx = logging.NewTag(ctx, logging.TagKeyLogin)
+	ctx, attempt := newLoginAttempt(ctx, model.LoginMethodPassword, "")
+	token, err := a.loginChangePassword(ctx, formItem)
+	a.LoginHistoryService.Record(ctx, attempt, token, err)
+	return token, err
+}
+
+func (a *Auth) loginChangePassword(ctx context.Context, formItem *model.LoginChangePasswordForm) (*model.LoginToken, error) {
+	invalidToken := errors.BadRequest(config.ErrInvalidChangePwdToken, "Invalid or expired change password token")
+
+	// the token is only consumed once the new password passes the policy, so a rejected password can be retried
+	ctx, userID, ok, err := a.getToken(ctx, config.CacheNSForChangePwd, formItem.PasswordToken)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, invalidToken
+	}
+	ctx = logging.NewUserID(ctx, userID)
+	setLoginAttempt(ctx, "", userID)
+
+	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "email", "password", "status"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	} else if user == nil || user.Status != model.UserStatusActive {
+		return nil, invalidToken
+	}
+
+	if err := a.PasswordService.Check(ctx, user.ID, user.Email, formItem.NewPassword, user.Password); err != nil {
+		return nil, err
+	}
+	// concurrent requests with the same token race here, only the one consuming it can change the password and login
+	if _, consumedID, ok, err := a.consumeToken(ctx, config.CacheNSForChangePwd, formItem.PasswordToken); err != nil {
+		return nil, err
+	} else if !ok || consumedID != userID {
+		return nil, invalidToken
+	}
+	if err := a.setPassword(ctx, user, formItem.NewPassword); err != nil {
+		return nil, err
+	}
+	logging.Context(ctx).Info("Expired password changed")
+
+	return a.completeLogin(ctx, userID)
+}
+
+// Send an email with a single-use reset password link to the user, unknown or inactive
+// emails are ignored silently so the endpoint can not be used to probe registered accounts.
+func (a *Auth) ForgotPassword(ctx context.Context, formItem *model.ForgotPasswordForm) error {
+	ctx = logging.NewTag(ctx, logging.TagKeyResetPwd)
+	ctx = withRequestTenant(ctx)
+	user, err := a.UserRepo.GetByEmail(ctx, formItem.Email, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "email", "full_name", "status"},
+		},
+	})
+	if err != nil {
+		return err
+	} else if user == nil || user.Status != model.UserStatusActive {
+		logging.Context(ctx).Warn("Forgot password for unknown or inactive user", zap.String("email", formItem.Email))
+		return nil
+	}
+	ctx = logging.NewUserID(ctx, user.ID)
+
+	exp := time.Duration(config.C.General.ResetPassword.TokenExp) * time.Second
+	token, err := a.issueToken(ctx, config.CacheNSForResetPwd, user.ID, exp)
+	if err != nil {
+		return err
+	}
+
+	err = sendMail(ctx, user.Email, "Reset your password", resetPasswordMailTpl, map[string]any{
+		"Name":       user.FullName,
+		"Link":       tokenLink(config.C.General.ResetPassword.URL, token),
+		"ExpMinutes": int(exp.Minutes()),
+	})
+	if err != nil {
+		logging.Context(ctx).Error("Failed to send reset password email", zap.Error(err))
+		return err
+	}
+	logging.Context(ctx).Info("Reset password email sent", zap.String("email", user.Email))
+	return nil
+}
+
+// Consume the reset password token and set the new password of the user, the existing sessions of the user are revoked.
+func (a *Auth) ResetPassword(ctx context.Context, formItem *model.ResetPasswordForm) error {
+	ctx = logging.NewTag(ctx, logging.TagKeyResetPwd)
+	invalidToken := errors.BadRequest(config.ErrInvalidResetPwdToken, "Invalid or expired reset password token")
+
+	// the token is only consumed once the new password passes the policy, so a rejected password can be retried
+	ctx, userID, ok, err := a.getToken(ctx, config.CacheNSForResetPwd, formItem.Token)
+	if err != nil {
+		return err
+	} else if !ok {
+		return invalidToken
+	}
+	ctx = logging.NewUserID(ctx, userID)
+
+	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "email", "password", "status"},
+		},
+	})
+	if err != nil {
+		return err
+	} else if user == nil || user.Status != model.UserStatusActive {
+		return invalidToken
+	}
+
+	if err := a.PasswordService.Check(ctx, user.ID, user.Email, formItem.Password, user.Password); err != nil {
+		return err
+	}
+	// concurrent requests with the same token race here, only the one consuming it can change the password
+	if _, consumedID, ok, err := a.consumeToken(ctx, config.CacheNSForResetPwd, formItem.Token); err != nil {
+		return err
+	} else if !ok || consumedID != userID {
+		return invalidToken
+	}
+	if err := a.setPassword(ctx, user, formItem.Password); err != nil {
+		return err
+	}
+
+	// whoever knew the old password must not keep access
+	if err := a.SessionService.RevokeAll(ctx, userID, ""); err != nil {
+		return err
+	}
+	if err := a.Cache.Delete(ctx, config.CacheNSForUser, userID); err != nil {
+		logging.Context(ctx).Error("Failed to delete user cache", zap.Error(err))
+	}
+	logging.Context(ctx).Info("Reset password success")
+	return nil
+}
+
+// Activate the user registered with email verification and login with it.
+func (a *Auth) VerifyEmail(ctx context.Context, formItem *model.VerifyEmailForm) (*model.LoginToken, error) {
+	ctx = logging.NewTag(ctx, logging.TagKeyRegister)
+	invalidToken := errors.BadRequest(config.ErrInvalidVerifyToken, "Invalid or expired verification token")
+
+	// the link may be opened without the tenant of the registration, the user is loaded under the tenant of the token
+	ctx, userID, ok, err := a.consumeToken(ctx, config.CacheNSForVerify, formItem.Token)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, invalidToken
+	}
+	ctx = logging.NewUserID(ctx, userID)
+
+	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "email", "status", "unverified", "tenant_id", "mfa_enabled"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	} else if user == nil || !user.Unverified {
+		return nil, invalidToken
+	}
+
+	user.Status = model.UserStatusActive
+	user.Unverified = false
+	user.UpdatedAt = time.Now()
+	if err := a.UserRepo.Update(ctx, user, "status", "unverified", "updated_at"); err != nil {
+		return nil, err
+	}
+	logging.Context(ctx).Info("Verify email success")
+
+	// the user is logged in like with the password, so the login is recorded and the password expiry is checked
+	ctx, attempt := newLoginAttempt(ctx, model.LoginMethodEmail, user.Email)
+	token, err := a.loginAuthenticated(ctx, user.Email, user, true)
+	a.LoginHistoryService.Record(ctx, attempt, token, err)
+	return token, err
+}
+
+// Send the verification email again, emails of unknown or verified users are ignored silently.
+func (a *Auth) ResendVerification(ctx context.Context, formItem *model.ResendVerificationForm) error {
+	if !config.C.General.RegisterVerification.Enable {
+		return errors.BadRequest("", "Email verification is not enabled")
+	}
+
+	ctx = logging.NewTag(ctx, logging.TagKeyRegister)
+	ctx = withRequestTenant(ctx)
+	user, err := a.UserRepo.GetByEmail(ctx, formItem.Email, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "email", "full_name", "unverified"},
+		},
+	})
+	if err != nil {
+		return err
+	} else if user == nil || !user.Unverified {
+		logging.Context(ctx).Warn("Resend verification for unknown or verified user", zap.String("email", formItem.Email))
+		return nil
+	}
+	ctx = logging.NewUserID(ctx, user.ID)
+
+	return a.sendVerificationMail(ctx, user)
+}
+
+func (a *Auth) sendVerificationMail(ctx context.Context, user *model.User) error {
+	if err := a.limitVerificationMail(ctx, user.Email); err != nil {
+		return err
+	}
+
+	exp := time.Duration(config.C.General.RegisterVerification.TokenExp) * time.Second
+	token, err := a.issueToken(ctx, config.CacheNSForVerify, user.ID, exp)
+	if err != nil {
+		return err
+	}
+
+	err = sendMail(ctx, user.Email, "Verify your email", verifyEmailMailTpl, map[string]any{
+		"Name":       user.FullName,
+		"Link":       tokenLink(config.C.General.RegisterVerification.URL, token),
+		"ExpMinutes": int(exp.Minutes()),
+	})
+	if err != nil {
+		return err
+	}
+	logging.Context(ctx).Info("Verification email sent", zap.String("email", user.Email))
+	return nil
+}
+
+// Allow one verification email per ResendInterval and MaxSendsPerDay emails per day to the same address.
+func (a *Auth) limitVerificationMail(ctx context.Context, email string) error {
+	cfg := config.C.General.RegisterVerification
+	emailKey := hash.SHA256String(strings.ToLower(email))
+
+	lastKey := "last:" + emailKey
+	if exists, err := a.Cache.Exists(ctx, config.CacheNSForVerify, lastKey); err != nil {
+		return err
+	} else if exists {
+		return errors.TooManyRequests(config.ErrTooManyVerifyMails, "Please wait %d seconds before requesting another verification email", cfg.ResendInterval)
+	}
+
+	if cfg.MaxSendsPerDay > 0 {
+		// The value is "count,first send unix time" and expires one day after the first send
+		countKey := "count:" + emailKey
+		count, since := 0, time.Now()
+		if val, ok, err := a.Cache.Get(ctx, config.CacheNSForVerify, countKey); err != nil {
+			return err
+		} else if ok {
+			if c, t, found := strings.Cut(val, ","); found {
+				count, _ = strconv.Atoi(c)
+				if unix, err := strconv.ParseInt(t, 10, 64); err == nil {
+					since = time.Unix(unix, 0)
+				}
+			}
+		}
+
+		if count >= cfg.MaxSendsPerDay {
+			return errors.TooManyRequests(config.ErrTooManyVerifyMails, "Too many verification emails, please try again tomorrow")
+		}
+
+		val := fmt.Sprintf("%d,%d", count+1, since.Unix())
+		if err := a.Cache.Set(ctx, config.CacheNSForVerify, countKey, val, 24*time.Hour-time.Since(since)); err != nil {
+			return err
+		}
+	}
+
+	if cfg.ResendInterval > 0 {
+		exp := time.Duration(cfg.ResendInterval) * time.Second
+		if err := a.Cache.Set(ctx, config.CacheNSForVerify, lastKey, "1", exp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Issue a random token for email links and login challenges, only the latest issued token of a user
+// in the namespace is valid and the cache stores the token hash only. The token is bound to the tenant
+// of ctx, the user of the token must be loaded under that tenant whatever the tenant of the later request.
+func (a *Auth) issueToken(ctx context.Context, ns, userID string, exp time.Duration) (string, error) {
+	token, err := rand.Random(48, rand.LdigitAndLetter)
+	if err != nil {
+		return "", err
+	}
+
+	tokenKey := hash.SHA256String(token)
+	userKey := "user:" + userID
+	if oldTokenKey, ok, err := a.Cache.GetAndDelete(ctx, ns, userKey); err != nil {
+		return "", err
+	} else if ok {
+		if err := a.Cache.Delete(ctx, ns, oldTokenKey); err != nil {
+			return "", err
+		}
+	}
+
+	tenantID, _ := util.FromTenantID(ctx)
+	if err := a.Cache.Set(ctx, ns, tokenKey, userID+" "+tenantID, exp); err != nil {
+		return "", err
+	}
+	if err := a.Cache.Set(ctx, ns, userKey, tokenKey, exp); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Get the user ID of a token issued by issueToken without consuming it, the returned context
+// is switched to the tenant the token was issued in.
+func (a *Auth) getToken(ctx context.Context, ns, token string) (context.Context, string, bool, error) {
+	value, ok, err := a.Cache.Get(ctx, ns, hash.SHA256String(token))
+	if err != nil || !ok {
+		return ctx, "", false, err
+	}
+	userID, tenantID, _ := strings.Cut(value, " ")
+	return util.NewTenantID(ctx, tenantID), userID, true, nil
+}
+
+// Consume a token issued by issueToken and return the user ID, the token can only be used once.
+// The returned context is switched to the tenant the token was issued in.
+func (a *Auth) consumeToken(ctx context.Context, ns, token string) (context.Context, string, bool, error) {
+	value, ok, err := a.Cache.GetAndDelete(ctx, ns, hash.SHA256String(token))
+	if err != nil || !ok {
+		return ctx, "", false, err
+	}
+	userID, tenantID, _ := strings.Cut(value, " ")
+
+	if err := a.Cache.Delete(ctx, ns, "user:"+userID); err != nil {
+		logging.Context(ctx).Error("Failed to delete user token key", zap.Error(err), zap.String("ns", ns))
+	}
+	return util.NewTenantID(ctx, tenantID), userID, true, nil
+}
+
+// Append the token to the link as query parameter `token`.
+func tokenLink(link, token string) string {
+	if strings.Contains(link, "?") {
+		return link + "&token=" + url.QueryEscape(token)
+	}
+	return link + "?token=" + url.QueryEscape(token)
 }
 
 // Query menus based on user permissions
@@ -345,7 +1078,14 @@ func (a *Auth) QueryMenus(ctx context.Context) (model.Menus, error) {
 
 	isRoot := util.FromIsRootUser(ctx)
 	if !isRoot {
-		menuQueryParams.UserID = util.FromUserID(ctx)
+		// the menus of the inherited roles are included
+		roleIDs, err := expandRoleIDs(ctx, a.RoleRepo, util.FromUserCache(ctx).RoleIDs, true)
+		if err != nil {
+			return nil, err
+		} else if len(roleIDs) == 0 {
+			return model.Menus{}, nil
+		}
+		menuQueryParams.InRoleIDs = roleIDs
 	}
 	menuResult, err := a.MenuRepo.Query(ctx, menuQueryParams, model.MenuQueryOptions{
 		QueryOptions: util.QueryOptions{
@@ -403,3 +1143,144 @@ func (a *Auth) UpdateUser(ctx context.Context, updateItem *model.UpdateCurrentUs
 	user.Remark = updateItem.Remark
 	return a.UserRepo.Update(ctx, user, "name", "phone", "email", "remark")
 }
+
+// Query the login sessions of the current user
+func (a *Auth) QuerySessions(ctx context.Context) (model.Sessions, error) {
+	return a.SessionService.Query(ctx, util.FromUserID(ctx))
+}
+
+// Revoke a login session of the current user
+func (a *Auth) RevokeSession(ctx context.Context, sessionID string) error {
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return err
+	}
+	return a.SessionService.Revoke(ctx, util.FromUserID(ctx), sessionID)
+}
+
+// Revoke all login sessions of the current user except the current one
+func (a *Auth) RevokeOtherSessions(ctx context.Context) error {
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return err
+	}
+	_, currentID, err := a.Auth.ParseSession(ctx, util.FromUserToken(ctx))
+	if err != nil {
+		return err
+	}
+	return a.SessionService.RevokeAll(ctx, util.FromUserID(ctx), currentID)
+}
+
+// Credentials can only be managed with the login of the user itself, so neither a leaked key
+// nor an impersonator can take over the account
+func (a *Auth) checkOwnLogin(ctx context.Context) error {
+	if util.FromAPIKeyID(ctx) != "" {
+		return errors.Forbidden("", "Not allowed with an API key")
+	} else if util.FromImpersonatorID(ctx) != "" {
+		return errors.Forbidden("", "Not allowed while impersonating the user")
+	}
+	return nil
+}
+
+// Query the API keys of the current user
+func (a *Auth) QueryAPIKeys(ctx context.Context) (model.APIKeys, error) {
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return nil, err
+	}
+	return a.APIKeyService.Query(ctx, util.FromUserID(ctx))
+}
+
+// Create an API key for the current user, the key is only returned in this response
+func (a *Auth) CreateAPIKey(ctx context.Context, formItem *model.APIKeyForm) (*model.APIKey, error) {
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return nil, err
+	}
+	return a.APIKeyService.Create(ctx, util.FromUserID(ctx), formItem)
+}
+
+// Revoke an API key of the current user
+func (a *Auth) RevokeAPIKey(ctx context.Context, id string) error {
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return err
+	}
+	return a.APIKeyService.Revoke(ctx, util.FromUserID(ctx), id)
+}
+
+// Query the login history of the current user
+func (a *Auth) QueryLoginHistory(ctx context.Context, params model.LoginHistoryQueryParam) (*model.LoginHistoryQueryResult, error) {
+	return a.LoginHistoryService.Query(ctx, util.FromUserID(ctx), params)
+}
+
+// Query the passkeys of the current user
+func (a *Auth) QueryPasskeys(ctx context.Context) (model.Passkeys, error) {
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return nil, err
+	}
+	return a.PasskeyService.Query(ctx, util.FromUserID(ctx))
+}
+
+// Start the registration of a passkey for the current user
+func (a *Auth) BeginPasskeyRegistration(ctx context.Context) (*model.PasskeyChallenge, error) {
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return nil, err
+	}
+	return a.PasskeyService.BeginRegistration(ctx, util.FromUserID(ctx))
+}
+
+// Complete the registration of a passkey for the current user
+func (a *Auth) FinishPasskeyRegistration(ctx context.Context, formItem *model.PasskeyRegisterForm) (*model.Passkey, error) {
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return nil, err
+	}
+	return a.PasskeyService.FinishRegistration(ctx, util.FromUserID(ctx), formItem)
+}
+
+// Delete a passkey of the current user
+func (a *Auth) DeletePasskey(ctx context.Context, id string) error {
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return err
+	}
+	return a.PasskeyService.Delete(ctx, util.FromUserID(ctx), id)
+}
+
+// Start the login with a passkey
+func (a *Auth) PasskeyLoginBegin(ctx context.Context, formItem *model.PasskeyLoginBeginForm) (*model.PasskeyChallenge, error) {
+	return a.PasskeyService.BeginLogin(withRequestTenant(ctx), formItem.Email)
+}
+
+// Complete the login with a passkey. The password and the two-factor authentication are not checked,
+// the passkey is a possession factor verified by the authenticator.
+func (a *Auth) PasskeyLoginFinish(ctx context.Context, formItem *model.PasskeyLoginFinishForm) (*model.LoginToken, error) {
+	ctx = logging.NewTag(ctx, logging.TagKeyLogin)
+	ctx = withRequestTenant(ctx)
+	ctx, attempt := newLoginAttempt(ctx, model.LoginMethodPasskey, "")
+	token, err := a.passkeyLoginFinish(ctx, formItem)
+	a.LoginHistoryService.Record(ctx, attempt, token, err)
+	return token, err
+}
+
+func (a *Auth) passkeyLoginFinish(ctx context.Context, formItem *model.PasskeyLoginFinishForm) (*model.LoginToken, error) {
+	userID, err := a.PasskeyService.FinishLogin(ctx, formItem)
+	if err != nil {
+		return nil, err
+	}
+	ctx = logging.NewUserID(ctx, userID)
+	setLoginAttempt(ctx, "", userID)
+
+	// the passkey only logs in to the tenant of its user
+	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "tenant_id", "status", "unverified"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, errors.BadRequest(config.ErrInvalidPasskey, "Invalid passkey")
+	} else if err := checkUserStatus(user); err != nil {
+		return nil, err
+	} else if err := a.checkTenant(ctx, user.TenantID); err != nil {
+		return nil, err
+	}
+
+	logging.Context(ctx).Info("Login success with passkey")
+	return a.issueLoginToken(ctx, userID)
+}
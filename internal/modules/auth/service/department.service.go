@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/repo"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+)
+
+// Department management of the organization
+type Department struct {
+	Trans              *util.Trans
+	DepartmentRepo     *repo.Department
+	UserDepartmentRepo *repo.UserDepartment
+}
+
+// Query departments from the data access object based on the provided parameters and options.
+func (a *Department) Query(ctx context.Context, params model.DepartmentQueryParam) (*model.DepartmentQueryResult, error) {
+	params.Pagination = false
+
+	result, err := a.DepartmentRepo.Query(ctx, params, model.DepartmentQueryOptions{
+		QueryOptions: util.QueryOptions{
+			OrderFields: model.DepartmentsOrderParams,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if params.LikeName != "" {
+		result.Data, err = a.appendChildren(ctx, result.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := a.fillLeaderIDs(ctx, result.Data...); err != nil {
+		return nil, err
+	}
+
+	result.Data = result.Data.ToTree()
+	return result, nil
+}
+
+func (a *Department) appendChildren(ctx context.Context, data model.Departments) (model.Departments, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	existsInData := func(id string) bool {
+		for _, item := range data {
+			if item.ID == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, item := range data {
+		childResult, err := a.DepartmentRepo.Query(ctx, model.DepartmentQueryParam{
+			ParentPathPrefix: item.ParentPath + item.ID + util.TreePathDelimiter,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range childResult.Data {
+			if existsInData(child.ID) {
+				continue
+			}
+			data = append(data, child)
+		}
+	}
+
+	if parentIDs := data.SplitParentIDs(); len(parentIDs) > 0 {
+		parentResult, err := a.DepartmentRepo.Query(ctx, model.DepartmentQueryParam{
+			InIDs: parentIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range parentResult.Data {
+			if existsInData(p.ID) {
+				continue
+			}
+			data = append(data, p)
+		}
+	}
+	sort.Sort(data)
+
+	return data, nil
+}
+
+func (a *Department) fillLeaderIDs(ctx context.Context, data ...*model.Department) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	leaderResult, err := a.UserDepartmentRepo.Query(ctx, model.UserDepartmentQueryParam{
+		InDepartmentIDs: model.Departments(data).ToIDs(),
+		Leader:          true,
+	})
+	if err != nil {
+		return err
+	}
+
+	m := model.Departments(data).ToMap()
+	for _, leader := range leaderResult.Data {
+		if item, ok := m[leader.DepartmentID]; ok {
+			item.LeaderIDs = append(item.LeaderIDs, leader.UserID)
+		}
+	}
+	return nil
+}
+
+// Get the specified department from the data access object.
+func (a *Department) Get(ctx context.Context, id string) (*model.Department, error) {
+	department, err := a.DepartmentRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if department == nil {
+		return nil, errors.NotFound("", "Department not found")
+	}
+
+	if err := a.fillLeaderIDs(ctx, department); err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// Create a new department in the data access object.
+func (a *Department) Create(ctx context.Context, formItem *model.DepartmentForm) (*model.Department, error) {
+	department := &model.Department{
+		ID:        util.NewXID(),
+		CreatedAt: time.Now(),
+	}
+
+	if parentID := formItem.ParentID; parentID != "" {
+		parent, err := a.DepartmentRepo.Get(ctx, parentID)
+		if err != nil {
+			return nil, err
+		} else if parent == nil {
+			return nil, errors.NotFound("", "Parent not found")
+		}
+		department.ParentPath = parent.ParentPath + parent.ID + util.TreePathDelimiter
+	}
+
+	if exists, err := a.DepartmentRepo.ExistsCodeByParentID(ctx, formItem.Code, formItem.ParentID); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, errors.BadRequest("", "Department code already exists at the same level")
+	}
+
+	if err := formItem.FillTo(department); err != nil {
+		return nil, err
+	}
+
+	if err := a.DepartmentRepo.Create(ctx, department); err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// Update the specified department in the data access object.
+func (a *Department) Update(ctx context.Context, id string, formItem *model.DepartmentForm) error {
+	department, err := a.DepartmentRepo.Get(ctx, id)
+	if err != nil {
+		return err
+	} else if department == nil {
+		return errors.NotFound("", "Department not found")
+	}
+
+	if department.Code != formItem.Code || department.ParentID != formItem.ParentID {
+		if exists, err := a.DepartmentRepo.ExistsCodeByParentID(ctx, formItem.Code, formItem.ParentID); err != nil {
+			return err
+		} else if exists {
+			return errors.BadRequest("", "Department code already exists at the same level")
+		}
+	}
+
+	oldParentPath := department.ParentPath
+	oldStatus := department.Status
+	childData, err := a.changeParent(ctx, department, formItem.ParentID)
+	if err != nil {
+		return err
+	}
+
+	if err := formItem.FillTo(department); err != nil {
+		return err
+	}
+	department.UpdatedAt = time.Now()
+
+	return a.Trans.Exec(ctx, func(ctx context.Context) error {
+		if oldStatus != formItem.Status {
+			oldPath := oldParentPath + department.ID + util.TreePathDelimiter
+			if err := a.DepartmentRepo.UpdateStatusByParentPath(ctx, oldPath, formItem.Status); err != nil {
+				return err
+			}
+		}
+
+		if err := a.updateChildPaths(ctx, department, oldParentPath, childData); err != nil {
+			return err
+		}
+		return a.DepartmentRepo.Update(ctx, department)
+	})
+}
+
+// Move the specified department to another parent and/or position.
+func (a *Department) Move(ctx context.Context, id string, formItem *model.DepartmentMoveForm) error {
+	department, err := a.DepartmentRepo.Get(ctx, id)
+	if err != nil {
+		return err
+	} else if department == nil {
+		return errors.NotFound("", "Department not found")
+	}
+
+	if department.ParentID != formItem.ParentID {
+		if exists, err := a.DepartmentRepo.ExistsCodeByParentID(ctx, department.Code, formItem.ParentID); err != nil {
+			return err
+		} else if exists {
+			return errors.BadRequest("", "Department code already exists at the same level")
+		}
+	}
+
+	oldParentPath := department.ParentPath
+	childData, err := a.changeParent(ctx, department, formItem.ParentID)
+	if err != nil {
+		return err
+	}
+
+	department.ParentID = formItem.ParentID
+	if formItem.Sequence != nil {
+		department.Sequence = *formItem.Sequence
+	}
+	department.UpdatedAt = time.Now()
+
+	return a.Trans.Exec(ctx, func(ctx context.Context) error {
+		if err := a.updateChildPaths(ctx, department, oldParentPath, childData); err != nil {
+			return err
+		}
+		return a.DepartmentRepo.Update(ctx, department)
+	})
+}
+
+// Set the parent path of the department for the new parent and return the sub-departments whose paths must follow,
+// a department can't be moved under itself or one of its sub-departments.
+func (a *Department) changeParent(ctx context.Context, department *model.Department, parentID string) (model.Departments, error) {
+	if department.ParentID == parentID {
+		return nil, nil
+	}
+
+	oldPath := department.ParentPath + department.ID + util.TreePathDelimiter
+
+	if parentID != "" {
+		parent, err := a.DepartmentRepo.Get(ctx, parentID)
+		if err != nil {
+			return nil, err
+		} else if parent == nil {
+			return nil, errors.NotFound("", "Parent not found")
+		}
+
+		parentPath := parent.ParentPath + parent.ID + util.TreePathDelimiter
+		if strings.HasPrefix(parentPath, oldPath) {
+			return nil, errors.BadRequest("", "Department can't be moved under itself or its sub-departments")
+		}
+		department.ParentPath = parentPath
+	} else {
+		department.ParentPath = ""
+	}
+
+	childResult, err := a.DepartmentRepo.Query(ctx, model.DepartmentQueryParam{
+		ParentPathPrefix: oldPath,
+	}, model.DepartmentQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "parent_path"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return childResult.Data, nil
+}
+
+func (a *Department) updateChildPaths(ctx context.Context, department *model.Department, oldParentPath string, childData model.Departments) error {
+	oldPath := oldParentPath + department.ID + util.TreePathDelimiter
+	newPath := department.ParentPath + department.ID + util.TreePathDelimiter
+	for _, child := range childData {
+		err := a.DepartmentRepo.UpdateParentPath(ctx, child.ID, strings.Replace(child.ParentPath, oldPath, newPath, 1))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete the specified department from the data access object.
+func (a *Department) Delete(ctx context.Context, id string) error {
+	exists, err := a.DepartmentRepo.Exists(ctx, id)
+	if err != nil {
+		return err
+	} else if !exists {
+		return errors.NotFound("", "Department not found")
+	}
+
+	if exists, err := a.DepartmentRepo.ExistsChildren(ctx, id); err != nil {
+		return err
+	} else if exists {
+		return errors.BadRequest("", "Department has sub-departments and can't be deleted")
+	}
+
+	return a.Trans.Exec(ctx, func(ctx context.Context) error {
+		if err := a.DepartmentRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return a.UserDepartmentRepo.DeleteByDepartmentID(ctx, id)
+	})
+}
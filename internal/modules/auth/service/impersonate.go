@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// Issue a short-lived access token of the user to the current user to reproduce what the user sees,
+// the token carries the current user as impersonator and can't be refreshed.
+func (a *Auth) Impersonate(ctx context.Context, userID string) (*model.LoginToken, error) {
+	ctx = logging.NewTag(ctx, logging.TagKeyImpersonate)
+	impersonatorID := util.FromUserID(ctx)
+	if util.FromAPIKeyID(ctx) != "" || util.FromImpersonatorID(ctx) != "" {
+		return nil, errors.Forbidden("", "Impersonation is only allowed with your own login")
+	} else if userID == impersonatorID {
+		return nil, errors.BadRequest("", "You can't impersonate yourself")
+	} else if userID == config.C.General.Root.ID {
+		return nil, errors.Forbidden("", "The root user can't be impersonated")
+	}
+
+	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{SelectFields: []string{"id", "status"}},
+	})
+	if err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, errors.NotFound("", "User not found")
+	} else if user.Status != model.UserStatusActive {
+		return nil, errors.BadRequest("", "User is not activated")
+	}
+
+	// other administrators can't gain roles by impersonating a user
+	if !util.FromIsRootUser(ctx) {
+		roleIDs, err := a.UserService.GetRoleIDs(ctx, userID)
+		if err != nil {
+			return nil, err
+		} else if !hasAllRoles(util.FromUserCache(ctx).RoleIDs, roleIDs) {
+			return nil, errors.Forbidden("", "You can't impersonate a user with roles you don't have")
+		}
+	}
+
+	exp := time.Duration(config.C.General.Impersonation.TokenExp) * time.Second
+	token, err := a.Auth.GenerateActorToken(ctx, userID, impersonatorID, exp)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Context(ctx).Info("Start impersonation", zap.String("impersonated_id", userID),
+		zap.String("session_id", token.GetSessionID()), zap.Int64("expires_at", token.GetExpiresAt()))
+	return toLoginToken(token), nil
+}
+
+// Report whether the impersonator roles include all the roles of the impersonated user.
+func hasAllRoles(impersonatorRoleIDs, roleIDs []string) bool {
+	has := make(map[string]bool, len(impersonatorRoleIDs))
+	for _, roleID := range impersonatorRoleIDs {
+		has[roleID] = true
+	}
+	for _, roleID := range roleIDs {
+		if !has[roleID] {
+			return false
+		}
+	}
+	return true
+}
+
+// End the impersonation of the current access token, the impersonator continues with its own token.
+func (a *Auth) EndImpersonation(ctx context.Context) error {
+	impersonatorID := util.FromImpersonatorID(ctx)
+	if impersonatorID == "" {
+		return errors.BadRequest("", "Not impersonating a user")
+	}
+
+	ctx = logging.NewTag(ctx, logging.TagKeyImpersonate)
+	if err := a.Auth.DestroyToken(ctx, util.FromUserToken(ctx)); err != nil {
+		return err
+	}
+	logging.Context(ctx).Info("End impersonation", zap.String("impersonator_id", impersonatorID))
+	return nil
+}
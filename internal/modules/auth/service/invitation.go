@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"html/template"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/repo"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/crypto/rand"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+var invitationMailTpl = template.Must(template.New("invitation").Parse(`<p>Hello,</p>
+<p>You have been invited to join {{.AppName}}.</p>
+<p><a href="{{.Link}}">Click here to accept the invitation</a> and set your name and password, the link expires in {{.ExpHours}} hours.</p>
+<p>If you don't expect the invitation, you can safely ignore this email.</p>`))
+
+// Invitations of users by email with pre-assigned roles
+type Invitation struct {
+	Trans          *util.Trans
+	InvitationRepo *repo.Invitation
+	UserRepo       *repo.User
+	RoleRepo       *repo.Role
+	UserService    *User
+}
+
+// Query invitations, the latest first.
+func (a *Invitation) Query(ctx context.Context, params model.InvitationQueryParam) (*model.InvitationQueryResult, error) {
+	params.Pagination = true
+
+	return a.InvitationRepo.Query(ctx, params, model.InvitationQueryOptions{
+		QueryOptions: util.QueryOptions{
+			OrderFields: []util.OrderByParam{
+				{Field: "created_at", Direction: util.DESC},
+			},
+		},
+	})
+}
+
+// Create an invitation and mail the invite token to the email.
+func (a *Invitation) Create(ctx context.Context, formItem *model.InvitationForm) (*model.Invitation, error) {
+	if existsEmail, err := a.UserRepo.ExistsEmail(ctx, formItem.Email); err != nil {
+		return nil, err
+	} else if existsEmail {
+		return nil, errors.BadRequest("", "Email already exists")
+	}
+
+	if exists, err := a.InvitationRepo.ExistsPendingEmail(ctx, formItem.Email); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, errors.BadRequest("", "The email is already invited, resend or revoke the pending invitation")
+	}
+
+	for _, roleID := range formItem.RoleIDs {
+		if exists, err := a.RoleRepo.Exists(ctx, roleID); err != nil {
+			return nil, err
+		} else if !exists {
+			return nil, errors.BadRequest("", "Role not found: %s", roleID)
+		}
+	}
+
+	invitation := &model.Invitation{
+		ID:        util.NewXID(),
+		Email:     formItem.Email,
+		RoleIDs:   formItem.RoleIDs,
+		Status:    model.InvitationStatusPending,
+		InvitedBy: util.FromUserID(ctx),
+		CreatedAt: time.Now(),
+	}
+	token, err := a.renewToken(invitation)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.InvitationRepo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	// the invitation is kept if the mail fails, it can be resent
+	if err := a.sendMail(ctx, invitation, token); err != nil {
+		logging.Context(ctx).Error("Failed to send invitation email", zap.Error(err), zap.String("email", invitation.Email))
+	}
+	return invitation, nil
+}
+
+// Mail a new invite token of the pending invitation, the previous token is invalidated.
+func (a *Invitation) Resend(ctx context.Context, id string) error {
+	invitation, err := a.getPending(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	token, err := a.renewToken(invitation)
+	if err != nil {
+		return err
+	}
+	invitation.UpdatedAt = time.Now()
+	if err := a.InvitationRepo.Update(ctx, invitation, "token_hash", "expires_at", "sent_at", "updated_at"); err != nil {
+		return err
+	}
+	return a.sendMail(ctx, invitation, token)
+}
+
+// Revoke the pending invitation, the invite token can't be accepted anymore.
+func (a *Invitation) Revoke(ctx context.Context, id string) error {
+	invitation, err := a.getPending(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	invitation.Status = model.InvitationStatusRevoked
+	invitation.UpdatedAt = time.Now()
+	if err := a.InvitationRepo.Update(ctx, invitation, "status", "updated_at"); err != nil {
+		return err
+	}
+	logging.Context(ctx).Info("Invitation revoked", zap.String("email", invitation.Email))
+	return nil
+}
+
+// Get the invitation of the invite token if it can still be accepted. The invite link carries no tenant,
+// so the invitation is looked up across the tenants.
+func (a *Invitation) GetAcceptable(ctx context.Context, token string) (*model.Invitation, error) {
+	invitation, err := a.InvitationRepo.GetByTokenHash(util.NewCrossTenant(ctx), hash.SHA256String(token))
+	if err != nil {
+		return nil, err
+	} else if invitation == nil || !invitation.IsAcceptableAt(time.Now()) {
+		return nil, errors.BadRequest(config.ErrInvalidInviteToken, "Invalid or expired invitation")
+	}
+	return invitation, nil
+}
+
+// Create the user of the invitation with the pre-assigned roles in the tenant of the invitation and return its ID.
+func (a *Invitation) Accept(ctx context.Context, invitation *model.Invitation, formItem *model.AcceptInvitationForm) (string, error) {
+	invalidToken := errors.BadRequest(config.ErrInvalidInviteToken, "Invalid or expired invitation")
+	ctx = util.NewTenantID(ctx, invitation.TenantID)
+
+	userForm := &model.UserForm{
+		Email:     invitation.Email,
+		FirstName: formItem.FirstName,
+		LastName:  formItem.LastName,
+		Password:  formItem.Password,
+		Status:    model.UserStatusActive,
+	}
+	for _, roleID := range invitation.RoleIDs {
+		userForm.Roles = append(userForm.Roles, &model.UserRole{RoleID: roleID})
+	}
+
+	var userID string
+	err := a.Trans.Exec(ctx, func(ctx context.Context) error {
+		user, err := a.UserService.Create(ctx, userForm)
+		if err != nil {
+			return err
+		}
+
+		// the invitation may be accepted or revoked concurrently
+		if ok, err := a.InvitationRepo.Accept(ctx, invitation.ID, user.ID, time.Now()); err != nil {
+			return err
+		} else if !ok {
+			return invalidToken
+		}
+		userID = user.ID
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	logging.Context(ctx).Info("Invitation accepted", zap.String("email", invitation.Email), zap.String("user_id", userID))
+	return userID, nil
+}
+
+func (a *Invitation) getPending(ctx context.Context, id string) (*model.Invitation, error) {
+	invitation, err := a.InvitationRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if invitation == nil {
+		return nil, errors.NotFound("", "Invitation not found")
+	} else if invitation.Status != model.InvitationStatusPending {
+		return nil, errors.BadRequest("", "Invitation is already %s", invitation.Status)
+	}
+	return invitation, nil
+}
+
+// Generate a new invite token of the invitation, only its hash is stored.
+func (a *Invitation) renewToken(invitation *model.Invitation) (string, error) {
+	token, err := rand.Random(48, rand.LdigitAndLetter)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	invitation.TokenHash = hash.SHA256String(token)
+	invitation.ExpiresAt = now.Add(time.Duration(config.C.General.Invitation.TokenExp) * time.Second)
+	invitation.SentAt = now
+	return token, nil
+}
+
+func (a *Invitation) sendMail(ctx context.Context, invitation *model.Invitation, token string) error {
+	err := sendMail(ctx, invitation.Email, "You are invited to "+config.C.General.AppName, invitationMailTpl, map[string]any{
+		"Link":     tokenLink(config.C.General.Invitation.URL, token),
+		"ExpHours": config.C.General.Invitation.TokenExp / 3600,
+	})
+	if err != nil {
+		return err
+	}
+	logging.Context(ctx).Info("Invitation email sent", zap.String("email", invitation.Email))
+	return nil
+}
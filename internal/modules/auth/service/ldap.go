@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/repo"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/ldapx"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// Login with the password of an LDAP directory
+type LDAP struct {
+	Trans        *util.Trans
+	UserRepo     *repo.User
+	UserRoleRepo *repo.UserRole
+}
+
+// Verify the password of the email in the directory and return the local user, it is created
+// on the first login if enabled. Returns ldapx.ErrUserNotFound or ldapx.ErrInvalidCredentials
+// if the login fails.
+func (a *LDAP) Authenticate(ctx context.Context, email, password string) (*model.User, error) {
+	cfg := config.C.General.LDAP
+	client := ldapx.NewClient(ldapx.Config{
+		URL:                cfg.URL,
+		StartTLS:           cfg.StartTLS,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		BindDN:             cfg.BindDN,
+		BindPassword:       cfg.BindPassword,
+		BaseDN:             cfg.BaseDN,
+		UserFilter:         cfg.UserFilter,
+		Timeout:            time.Duration(cfg.Timeout) * time.Second,
+	})
+
+	entry, err := client.Authenticate(email, password,
+		cfg.FirstNameAttribute, cfg.LastNameAttribute, cfg.FullNameAttribute, cfg.GroupAttribute)
+	if err != nil {
+		if errors.Is(err, ldapx.ErrUserNotFound) || errors.Is(err, ldapx.ErrInvalidCredentials) {
+			return nil, err
+		}
+		logging.Context(ctx).Error("Failed to authenticate with LDAP", zap.Error(err))
+		return nil, errors.InternalServerError("", "Directory is not available, please try again later")
+	}
+
+	user, err := a.UserRepo.GetByEmail(ctx, email, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "tenant_id", "status", "unverified", "mfa_enabled"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	} else if user == nil && !cfg.AutoCreate {
+		return nil, errors.BadRequest("", "User not found, please contact the administrator")
+	}
+
+	var userRoles model.UserRoles
+	if user != nil {
+		result, err := a.UserRoleRepo.Query(ctx, model.UserRoleQueryParam{UserID: user.ID})
+		if err != nil {
+			return nil, err
+		}
+		userRoles = result.Data
+	}
+
+	err = a.Trans.Exec(ctx, func(ctx context.Context) error {
+		if user == nil {
+			user = newLDAPUser(email, entry)
+			if err := a.UserRepo.Create(ctx, user); err != nil {
+				return err
+			}
+			logging.Context(ctx).Info("Create user from LDAP", zap.String("user_id", user.ID), zap.String("dn", entry.DN))
+		}
+		return a.syncRoles(ctx, user.ID, userRoles, entry.Values(cfg.GroupAttribute))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Users created from the directory have no local password, so they can only login with the directory.
+func newLDAPUser(email string, entry *ldapx.Entry) *model.User {
+	cfg := config.C.General.LDAP
+	firstName := entry.Get(cfg.FirstNameAttribute)
+	lastName := entry.Get(cfg.LastNameAttribute)
+	fullName := entry.Get(cfg.FullNameAttribute)
+	if fullName == "" {
+		fullName = strings.TrimSpace(firstName + " " + lastName)
+	}
+	if fullName == "" {
+		fullName = email
+	}
+
+	return &model.User{
+		ID:        util.NewXID(),
+		Email:     email,
+		FirstName: firstName,
+		LastName:  lastName,
+		FullName:  fullName,
+		Status:    model.UserStatusActive,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Grant the roles mapped from the groups of the user and revoke the mapped roles of other groups,
+// roles without a group mapping are managed locally and kept.
+func (a *LDAP) syncRoles(ctx context.Context, userID string, userRoles model.UserRoles, groups []string) error {
+	mapped := make(map[string]bool)
+	granted := make(map[string]bool)
+	for _, item := range config.C.General.LDAP.GroupRoles {
+		mapped[item.RoleID] = true
+		for _, group := range groups {
+			if strings.EqualFold(item.Group, group) {
+				granted[item.RoleID] = true
+			}
+		}
+	}
+	if len(mapped) == 0 {
+		return nil
+	}
+
+	for _, userRole := range userRoles {
+		if !mapped[userRole.RoleID] {
+			continue
+		} else if granted[userRole.RoleID] {
+			delete(granted, userRole.RoleID)
+			continue
+		}
+		if err := a.UserRoleRepo.Delete(ctx, userRole.ID); err != nil {
+			return err
+		}
+		logging.Context(ctx).Info("Revoke role of LDAP group", zap.String("role_id", userRole.RoleID))
+	}
+
+	for roleID := range granted {
+		userRole := &model.UserRole{
+			ID:        util.NewXID(),
+			UserID:    userID,
+			RoleID:    roleID,
+			CreatedAt: time.Now(),
+		}
+		if err := a.UserRoleRepo.Create(ctx, userRole); err != nil {
+			return err
+		}
+		logging.Context(ctx).Info("Grant role of LDAP group", zap.String("role_id", roleID))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/pkg/cachex"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// Failed login tracking, an email or a client IP is locked for a while after too many failed logins
+type Lockout struct {
+	Cache cachex.Cacher
+}
+
+type lockoutSubject struct {
+	kind        string // email/ip
+	value       string
+	maxAttempts int
+}
+
+func (s lockoutSubject) key(prefix string) string {
+	return prefix + ":" + s.kind + ":" + s.value
+}
+
+func (a *Lockout) subjects(ctx context.Context, email string) []lockoutSubject {
+	cfg := config.C.General.Lockout
+	var subjects []lockoutSubject
+	if email != "" && cfg.MaxAttempts > 0 {
+		subjects = append(subjects, lockoutSubject{kind: "email", value: strings.ToLower(email), maxAttempts: cfg.MaxAttempts})
+	}
+	if ip := util.FromClientInfo(ctx).IP; ip != "" && cfg.MaxIPAttempts > 0 {
+		subjects = append(subjects, lockoutSubject{kind: "ip", value: ip, maxAttempts: cfg.MaxIPAttempts})
+	}
+	return subjects
+}
+
+func (a *Lockout) getInt(ctx context.Context, key string) (int, error) {
+	val, ok, err := a.Cache.Get(ctx, config.CacheNSForLockout, key)
+	if err != nil || !ok {
+		return 0, err
+	}
+	n, _ := strconv.Atoi(val)
+	return n, nil
+}
+
+func lockedError(d time.Duration) error {
+	d = d.Round(time.Second)
+	if d < time.Second {
+		d = time.Second
+	}
+	err := errors.Forbidden(config.ErrLoginLocked, "Too many failed logins, please try again in %s", d)
+	return errors.WithRetryAfter(err, d)
+}
+
+// Return a Forbidden error with the retry time if the email or the client IP is locked.
+func (a *Lockout) Check(ctx context.Context, email string) error {
+	for _, subject := range a.subjects(ctx, email) {
+		until, err := a.getInt(ctx, subject.key("lock"))
+		if err != nil {
+			return err
+		}
+		if d := time.Until(time.Unix(int64(until), 0)); d > 0 {
+			return lockedError(d)
+		}
+	}
+	return nil
+}
+
+// Record a failed login of the email from the client IP, return a Forbidden error if it causes a lockout.
+func (a *Lockout) Fail(ctx context.Context, email string) error {
+	cfg := config.C.General.Lockout
+	window := time.Duration(cfg.Window) * time.Second
+	maxDuration := time.Duration(cfg.MaxDuration) * time.Second
+
+	var lockedFor time.Duration
+	for _, subject := range a.subjects(ctx, email) {
+		// The counters are incremented atomically, so concurrent failures are all counted
+		fails, err := a.Cache.Incr(ctx, config.CacheNSForLockout, subject.key("fails"), window)
+		if err != nil {
+			return err
+		} else if fails < int64(subject.maxAttempts) {
+			continue
+		}
+
+		// Every consecutive lockout doubles the duration, the lockouts are remembered for the longest lockout after the last one
+		d := time.Duration(cfg.Duration) * time.Second
+		longest := d
+		if maxDuration > longest {
+			longest = maxDuration
+		}
+		locks, err := a.Cache.Incr(ctx, config.CacheNSForLockout, subject.key("locks"), longest+maxDuration)
+		if err != nil {
+			return err
+		}
+
+		for i := int64(1); i < locks && d < maxDuration; i++ {
+			d *= 2
+		}
+		if maxDuration > 0 && d > maxDuration {
+			d = maxDuration
+		}
+
+		until := strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+		if err := a.Cache.Set(ctx, config.CacheNSForLockout, subject.key("lock"), until, d); err != nil {
+			return err
+		}
+		if err := a.Cache.Delete(ctx, config.CacheNSForLockout, subject.key("fails")); err != nil {
+			return err
+		}
+
+		logging.Context(logging.NewTag(ctx, logging.TagKeyLockout)).Warn("Login locked after too many failures",
+			zap.String("type", subject.kind),
+			zap.String("value", subject.value),
+			zap.Int64("lockouts", locks),
+			zap.Duration("duration", d),
+		)
+		if d > lockedFor {
+			lockedFor = d
+		}
+	}
+
+	if lockedFor > 0 {
+		return lockedError(lockedFor)
+	}
+	return nil
+}
+
+// Forget the failed logins of the email after a successful login, failures of the IP are kept.
+func (a *Lockout) Reset(ctx context.Context, email string) error {
+	subject := lockoutSubject{kind: "email", value: strings.ToLower(email)}
+	for _, prefix := range []string{"fails", "locks"} {
+		if err := a.Cache.Delete(ctx, config.CacheNSForLockout, subject.key(prefix)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unlock the email and forget its failed logins and lockouts.
+func (a *Lockout) Unlock(ctx context.Context, email string) error {
+	subject := lockoutSubject{kind: "email", value: strings.ToLower(email)}
+	for _, prefix := range []string{"fails", "locks", "lock"} {
+		if err := a.Cache.Delete(ctx, config.CacheNSForLockout, subject.key(prefix)); err != nil {
+			return err
+		}
+	}
+	logging.Context(logging.NewTag(ctx, logging.TagKeyLockout)).Info("Login unlocked", zap.String("email", subject.value))
+	return nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/repo"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+var unusualLoginMailTpl = template.Must(template.New("unusual_login").Parse(`<p>Hello {{.Name}},</p>
+<p>Your {{.AppName}} account was just logged in from a new {{.Source}}.</p>
+<p>Time: {{.Time}}<br>IP: {{.IP}}<br>Device: {{.Device}}</p>
+<p>If this was you, you can safely ignore this email. Otherwise please change your password and revoke the unknown sessions.</p>`))
+
+// Called after an unusual login (new IP or user agent) is recorded, e.g. to alert the user or the security team.
+// The hooks run synchronously in the login request, the errors are logged.
+type LoginAlertHook func(ctx context.Context, item *model.LoginHistory) error
+
+type loginAttemptCtx struct{}
+
+// Start the login attempt of the request, the login steps fill in the user.
+func newLoginAttempt(ctx context.Context, method, email string) (context.Context, *model.LoginHistory) {
+	attempt := &model.LoginHistory{Method: method, Email: email}
+	return context.WithValue(ctx, loginAttemptCtx{}, attempt), attempt
+}
+
+// Set the method and user of the login attempt of the request, if any.
+func setLoginAttempt(ctx context.Context, method, userID string) {
+	if attempt, ok := ctx.Value(loginAttemptCtx{}).(*model.LoginHistory); ok {
+		if method != "" {
+			attempt.Method = method
+		}
+		if userID != "" {
+			attempt.UserID = userID
+		}
+	}
+}
+
+// Login history and alerts of unusual logins
+type LoginHistory struct {
+	LoginHistoryRepo *repo.LoginHistory
+	UserRepo         *repo.User
+
+	lock  sync.RWMutex     `wire:"-"`
+	hooks []LoginAlertHook `wire:"-"`
+}
+
+// Add a hook called after unusual logins.
+func (a *LoginHistory) AddAlertHook(hook LoginAlertHook) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.hooks = append(a.hooks, hook)
+}
+
+// Query the login history of the user, the latest first.
+func (a *LoginHistory) Query(ctx context.Context, userID string, params model.LoginHistoryQueryParam) (*model.LoginHistoryQueryResult, error) {
+	params.Pagination = true
+	params.UserID = userID
+
+	return a.LoginHistoryRepo.Query(ctx, params, model.LoginHistoryQueryOptions{
+		QueryOptions: util.QueryOptions{
+			OrderFields: []util.OrderByParam{
+				{Field: "created_at", Direction: util.DESC},
+			},
+		},
+	})
+}
+
+// Record the result of the login attempt. Pending logins (two-factor challenge or expired password)
+// are recorded by the next step, server errors are not recorded. Recording never fails the login.
+func (a *LoginHistory) Record(ctx context.Context, attempt *model.LoginHistory, token *model.LoginToken, err error) {
+	if err == nil && token != nil && (token.MFARequired || token.PasswordExpired) {
+		return
+	}
+	if err != nil {
+		e := errors.FromError(err)
+		if e.Code >= http.StatusInternalServerError {
+			return
+		}
+		attempt.Reason = e.Detail
+	}
+
+	client := util.FromClientInfo(ctx)
+	attempt.ID = util.NewXID()
+	attempt.IP = client.IP
+	attempt.UserAgent = client.UserAgent
+	attempt.Success = err == nil
+	attempt.CreatedAt = time.Now()
+
+	if err := a.record(ctx, attempt); err != nil {
+		logging.Context(ctx).Error("Failed to record login history", zap.Error(err))
+	}
+}
+
+func (a *LoginHistory) record(ctx context.Context, attempt *model.LoginHistory) error {
+	if attempt.UserID == "" && attempt.Email == "" {
+		return nil
+	}
+
+	// failed attempts of known emails belong to the history of the user
+	if attempt.UserID == "" && attempt.Email != "" && attempt.Email != config.C.General.Root.Email {
+		user, err := a.UserRepo.GetByEmail(ctx, attempt.Email, model.UserQueryOptions{
+			QueryOptions: util.QueryOptions{SelectFields: []string{"id"}},
+		})
+		if err != nil {
+			return err
+		} else if user != nil {
+			attempt.UserID = user.ID
+		}
+	}
+
+	if attempt.Success && attempt.UserID != "" {
+		if err := a.detectUnusual(ctx, attempt); err != nil {
+			return err
+		}
+	}
+
+	if err := a.LoginHistoryRepo.Create(ctx, attempt); err != nil {
+		return err
+	}
+
+	if attempt.Unusual() {
+		logging.Context(ctx).Warn("Unusual login", zap.String("user_id", attempt.UserID), zap.String("ip", attempt.IP),
+			zap.Bool("new_ip", attempt.NewIP), zap.Bool("new_user_agent", attempt.NewUserAgent))
+		a.alert(ctx, attempt)
+	}
+	return nil
+}
+
+// Compare the successful login with the previous ones of the user, the first login is not unusual.
+func (a *LoginHistory) detectUnusual(ctx context.Context, attempt *model.LoginHistory) error {
+	exists, err := a.LoginHistoryRepo.ExistsSuccess(ctx, attempt.UserID, "", "")
+	if err != nil || !exists {
+		return err
+	}
+
+	knownIP, err := a.LoginHistoryRepo.ExistsSuccess(ctx, attempt.UserID, attempt.IP, "")
+	if err != nil {
+		return err
+	}
+	knownUserAgent, err := a.LoginHistoryRepo.ExistsSuccess(ctx, attempt.UserID, "", attempt.UserAgent)
+	if err != nil {
+		return err
+	}
+	attempt.NewIP = !knownIP
+	attempt.NewUserAgent = !knownUserAgent
+	return nil
+}
+
+func (a *LoginHistory) alert(ctx context.Context, attempt *model.LoginHistory) {
+	a.lock.RLock()
+	hooks := a.hooks
+	a.lock.RUnlock()
+
+	if config.C.General.LoginHistory.AlertMail {
+		hooks = append([]LoginAlertHook{a.mailAlert}, hooks...)
+	}
+	for _, hook := range hooks {
+		if err := hook(ctx, attempt); err != nil {
+			logging.Context(ctx).Error("Failed to alert unusual login", zap.Error(err), zap.String("user_id", attempt.UserID))
+		}
+	}
+}
+
+// Email the user about the unusual login.
+func (a *LoginHistory) mailAlert(ctx context.Context, attempt *model.LoginHistory) error {
+	user, err := a.UserRepo.Get(ctx, attempt.UserID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{SelectFields: []string{"email", "full_name"}},
+	})
+	if err != nil || user == nil {
+		return err
+	}
+
+	source := "device"
+	if attempt.NewIP && !attempt.NewUserAgent {
+		source = "IP address"
+	}
+	return sendMail(ctx, user.Email, "New login to your account", unusualLoginMailTpl, map[string]any{
+		"Name":   user.FullName,
+		"Source": source,
+		"Time":   attempt.CreatedAt.Format(time.RFC1123),
+		"IP":     attempt.IP,
+		"Device": parseDevice(attempt.UserAgent),
+	})
+}
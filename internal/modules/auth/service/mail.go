@@ -0,0 +1,34 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+
+	"go-admin/internal/config"
+	"go-admin/pkg/mail"
+)
+
+var resetPasswordMailTpl = template.Must(template.New("reset_password").Parse(`<p>Hello {{.Name}},</p>
+<p>We received a request to reset the password of your {{.AppName}} account.</p>
+<p><a href="{{.Link}}">Click here to reset your password</a>, the link expires in {{.ExpMinutes}} minutes and can only be used once.</p>
+<p>If you did not request a password reset, you can safely ignore this email.</p>`))
+
+var verifyEmailMailTpl = template.Must(template.New("verify_email").Parse(`<p>Hello {{.Name}},</p>
+<p>Thanks for registering a {{.AppName}} account.</p>
+<p><a href="{{.Link}}">Click here to verify your email</a> and activate the account, the link expires in {{.ExpMinutes}} minutes.</p>
+<p>If you did not register, you can safely ignore this email.</p>`))
+
+// Render the mail template with data and send it to the recipient.
+func sendMail(ctx context.Context, to, subject string, tpl *template.Template, data map[string]any) error {
+	if data == nil {
+		data = make(map[string]any)
+	}
+	data["AppName"] = config.C.General.AppName
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	return mail.SendTo(ctx, []string{to}, subject, buf.String())
+}
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -358,6 +357,8 @@ func (a *Menu) Update(ctx context.Context, id string, formItem *model.MenuForm)
 		return err
 	} else if menu == nil {
 		return errors.NotFound("", "Menu not found")
+	} else if tenantID, ok := util.FromTenantID(ctx); ok && menu.TenantID != tenantID {
+		return errors.Forbidden("", "Menus of the default tenant can't be changed")
 	}
 
 	oldParentPath := menu.ParentPath
@@ -401,7 +402,12 @@ func (a *Menu) Update(ctx context.Context, id string, formItem *model.MenuForm)
 		return err
 	}
 
-	return a.Trans.Exec(ctx, func(ctx context.Context) error {
+	roleIDs, err := a.queryRoleIDs(ctx, menu.ID, oldParentPath+menu.ID+util.TreePathDelimiter)
+	if err != nil {
+		return err
+	}
+
+	err = a.Trans.Exec(ctx, func(ctx context.Context) error {
 		if oldStatus != formItem.Status {
 			oldPath := oldParentPath + menu.ID + util.TreePathDelimiter
 			if err := a.MenuRepo.UpdateStatusByParentPath(ctx, oldPath, formItem.Status); err != nil {
@@ -439,8 +445,12 @@ func (a *Menu) Update(ctx context.Context, id string, formItem *model.MenuForm)
 			}
 		}
 
-		return a.syncToCasbin(ctx)
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+	return a.syncToCasbin(ctx, roleIDs...)
 }
 
 // Delete the specified menu from the data access object.
@@ -454,6 +464,8 @@ func (a *Menu) Delete(ctx context.Context, id string) error {
 		return err
 	} else if menu == nil {
 		return errors.NotFound("", "Menu not found")
+	} else if tenantID, ok := util.FromTenantID(ctx); ok && menu.TenantID != tenantID {
+		return errors.Forbidden("", "Menus of the default tenant can't be changed")
 	}
 
 	childResult, err := a.MenuRepo.Query(ctx, model.MenuQueryParam{
@@ -467,7 +479,12 @@ func (a *Menu) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
-	return a.Trans.Exec(ctx, func(ctx context.Context) error {
+	roleIDs, err := a.queryRoleIDs(ctx, menu.ID, menu.ParentPath+menu.ID+util.TreePathDelimiter)
+	if err != nil {
+		return err
+	}
+
+	err = a.Trans.Exec(ctx, func(ctx context.Context) error {
 		if err := a.delete(ctx, id); err != nil {
 			return err
 		}
@@ -478,8 +495,12 @@ func (a *Menu) Delete(ctx context.Context, id string) error {
 			}
 		}
 
-		return a.syncToCasbin(ctx)
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+	return a.syncToCasbin(ctx, roleIDs...)
 }
 
 func (a *Menu) delete(ctx context.Context, id string) error {
@@ -495,6 +516,34 @@ func (a *Menu) delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (a *Menu) syncToCasbin(ctx context.Context) error {
-	return a.Cache.Set(ctx, config.CacheNSForRole, config.CacheKeyForSyncToCasbin, fmt.Sprintf("%d", time.Now().Unix()))
+// Query the IDs of the roles that are granted the menu or any of its children,
+// since a role's policy includes the resources of the ancestors of its menus.
+func (a *Menu) queryRoleIDs(ctx context.Context, id, childPathPrefix string) ([]string, error) {
+	childResult, err := a.MenuRepo.Query(ctx, model.MenuQueryParam{
+		ParentPathPrefix: childPathPrefix,
+	}, model.MenuQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	menuIDs := append([]string{id}, childResult.Data.ToIDs()...)
+	roleMenuResult, err := a.RoleMenuRepo.Query(ctx, model.RoleMenuQueryParam{
+		InMenuIDs: menuIDs,
+	}, model.RoleMenuQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"role_id"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roleMenuResult.Data.ToRoleIDs(), nil
+}
+
+func (a *Menu) syncToCasbin(ctx context.Context, roleIDs ...string) error {
+	return syncRolesToCasbin(ctx, a.Cache, roleIDs...)
 }
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/aes"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/crypto/rand"
+	"go-admin/pkg/crypto/totp"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// Start the two-factor login challenge, the returned token is exchanged for the access token by LoginMFA.
+func (a *Auth) newMFAChallenge(ctx context.Context, userID string) (*model.LoginToken, error) {
+	exp := time.Duration(config.C.General.MFA.ChallengeExp) * time.Second
+	token, err := a.issueToken(ctx, config.CacheNSForMFA, userID, exp)
+	if err != nil {
+		return nil, err
+	}
+	logging.Context(ctx).Info("Login requires two-factor authentication")
+
+	return &model.LoginToken{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresAt:   time.Now().Add(exp).Unix(),
+	}, nil
+}
+
+// Complete the two-factor login challenge with a TOTP code or a recovery code.
+func (a *Auth) LoginMFA(ctx context.Context, formItem *model.LoginMFAForm) (*model.LoginToken, error) {
+	ctx = logging.NewTag(ctx, logging.TagKeyLogin)
+	ctx, attempt := newLoginAttempt(ctx, model.LoginMethodPassword, "")
+	attempt.MFAUsed = true
+	token, err := a.loginMFA(ctx, formItem)
+	a.LoginHistoryService.Record(ctx, attempt, token, err)
+	return token, err
+}
+
+func (a *Auth) loginMFA(ctx context.Context, formItem *model.LoginMFAForm) (*model.LoginToken, error) {
+	invalidToken := errors.BadRequest(config.ErrInvalidMFAToken, "Invalid or expired two-factor authentication token")
+
+	tokenKey := hash.SHA256String(formItem.MFAToken)
+	ctx, userID, ok, err := a.getToken(ctx, config.CacheNSForMFA, formItem.MFAToken)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, invalidToken
+	}
+	ctx = logging.NewUserID(ctx, userID)
+	setLoginAttempt(ctx, "", userID)
+
+	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "email", "status", "mfa_enabled", "mfa_secret", "mfa_codes"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	} else if user == nil || user.Status != model.UserStatusActive || !user.MFAEnabled {
+		return nil, invalidToken
+	}
+
+	if err := a.LockoutService.Check(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	if ok, err := a.verifyMFACode(ctx, user, formItem.Code); err != nil {
+		return nil, err
+	} else if !ok {
+		// Drop the challenge after too many failures, the user has to login with the password again
+		attemptsKey := "attempts:" + tokenKey
+		attempts := 1
+		if val, ok, err := a.Cache.Get(ctx, config.CacheNSForMFA, attemptsKey); err != nil {
+			return nil, err
+		} else if ok {
+			n, _ := strconv.Atoi(val)
+			attempts += n
+		}
+
+		if attempts >= config.C.General.MFA.MaxAttempts {
+			if _, _, _, err := a.consumeToken(ctx, config.CacheNSForMFA, formItem.MFAToken); err != nil {
+				return nil, err
+			}
+			_ = a.Cache.Delete(ctx, config.CacheNSForMFA, attemptsKey)
+			logging.Context(ctx).Warn("Too many failed two-factor codes, challenge dropped")
+			// A dropped challenge counts as a failed login, so new challenges can't be used to guess codes endlessly
+			if err := a.LockoutService.Fail(ctx, user.Email); err != nil {
+				return nil, err
+			}
+			return nil, invalidToken
+		}
+
+		exp := time.Duration(config.C.General.MFA.ChallengeExp) * time.Second
+		if err := a.Cache.Set(ctx, config.CacheNSForMFA, attemptsKey, strconv.Itoa(attempts), exp); err != nil {
+			return nil, err
+		}
+		return nil, errors.BadRequest(config.ErrInvalidMFACode, "Incorrect two-factor authentication code")
+	}
+
+	if _, _, ok, err := a.consumeToken(ctx, config.CacheNSForMFA, formItem.MFAToken); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, invalidToken
+	}
+	_ = a.Cache.Delete(ctx, config.CacheNSForMFA, "attempts:"+tokenKey)
+	if err := a.LockoutService.Reset(ctx, user.Email); err != nil {
+		return nil, err
+	}
+	logging.Context(ctx).Info("Login success with two-factor authentication")
+
+	return a.completeLogin(ctx, userID)
+}
+
+// Generate a new TOTP secret for the current user, it takes effect after being confirmed by ConfirmMFA.
+func (a *Auth) EnrollMFA(ctx context.Context) (*model.MFAEnrollment, error) {
+	if util.FromIsRootUser(ctx) {
+		return nil, errors.BadRequest("", "Root user cannot enable two-factor authentication")
+	}
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx = logging.NewTag(ctx, logging.TagKeyMFA)
+	userID := util.FromUserID(ctx)
+	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"email", "mfa_enabled"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, errors.NotFound("", "User not found")
+	} else if user.MFAEnabled {
+		return nil, errors.BadRequest("", "Two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := encryptMFASecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	exp := time.Duration(config.C.General.MFA.EnrollExp) * time.Second
+	if err := a.Cache.Set(ctx, config.CacheNSForMFA, "enroll:"+userID, encrypted, exp); err != nil {
+		return nil, err
+	}
+	logging.Context(ctx).Info("Two-factor authentication enrollment started")
+
+	issuer := config.C.General.MFA.Issuer
+	if issuer == "" {
+		issuer = config.C.General.AppName
+	}
+	return &model.MFAEnrollment{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(secret, issuer, user.Email),
+	}, nil
+}
+
+// Confirm the enrolled TOTP secret with a code, enable two-factor authentication and return the recovery codes.
+func (a *Auth) ConfirmMFA(ctx context.Context, formItem *model.MFACodeForm) (*model.MFARecoveryCodes, error) {
+	if util.FromIsRootUser(ctx) {
+		return nil, errors.BadRequest("", "Root user cannot enable two-factor authentication")
+	}
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx = logging.NewTag(ctx, logging.TagKeyMFA)
+	userID := util.FromUserID(ctx)
+	encrypted, ok, err := a.Cache.Get(ctx, config.CacheNSForMFA, "enroll:"+userID)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.BadRequest("", "Two-factor authentication enrollment not found or expired")
+	}
+
+	secret, err := decryptMFASecret(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(secret, formItem.Code, time.Now())
+	if !ok {
+		return nil, errors.BadRequest(config.ErrInvalidMFACode, "Incorrect two-factor authentication code")
+	}
+
+	codes, hashes, err := genRecoveryCodes(config.C.General.MFA.RecoveryCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		ID:         userID,
+		MFAEnabled: true,
+		MFASecret:  encrypted,
+		MFACodes:   strings.Join(hashes, ","),
+		UpdatedAt:  time.Now(),
+	}
+	if err := a.UserRepo.Update(ctx, user, "mfa_enabled", "mfa_secret", "mfa_codes", "updated_at"); err != nil {
+		return nil, err
+	}
+
+	if err := a.Cache.Delete(ctx, config.CacheNSForMFA, "enroll:"+userID); err != nil {
+		logging.Context(ctx).Error("Failed to delete enrollment", zap.Error(err))
+	}
+	a.markTOTPStep(ctx, userID, step)
+	logging.Context(ctx).Info("Two-factor authentication enabled")
+
+	return &model.MFARecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// Disable two-factor authentication of the current user, a valid TOTP or recovery code is required.
+func (a *Auth) DisableMFA(ctx context.Context, formItem *model.MFACodeForm) error {
+	if util.FromIsRootUser(ctx) {
+		return errors.BadRequest("", "Root user cannot disable two-factor authentication")
+	}
+	if err := a.checkOwnLogin(ctx); err != nil {
+		return err
+	}
+
+	ctx = logging.NewTag(ctx, logging.TagKeyMFA)
+	userID := util.FromUserID(ctx)
+	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "mfa_enabled", "mfa_secret", "mfa_codes"},
+		},
+	})
+	if err != nil {
+		return err
+	} else if user == nil {
+		return errors.NotFound("", "User not found")
+	} else if !user.MFAEnabled {
+		return errors.BadRequest("", "Two-factor authentication is not enabled")
+	}
+
+	if ok, err := a.verifyMFACode(ctx, user, formItem.Code); err != nil {
+		return err
+	} else if !ok {
+		return errors.BadRequest(config.ErrInvalidMFACode, "Incorrect two-factor authentication code")
+	}
+
+	user.MFAEnabled = false
+	user.MFASecret = ""
+	user.MFACodes = ""
+	user.UpdatedAt = time.Now()
+	if err := a.UserRepo.Update(ctx, user, "mfa_enabled", "mfa_secret", "mfa_codes", "updated_at"); err != nil {
+		return err
+	}
+	logging.Context(ctx).Info("Two-factor authentication disabled")
+	return nil
+}
+
+// Verify a TOTP code or consume a recovery code of the user.
+func (a *Auth) verifyMFACode(ctx context.Context, user *model.User, code string) (bool, error) {
+	if len(code) == totp.Digits {
+		secret, err := decryptMFASecret(user.MFASecret)
+		if err != nil {
+			return false, err
+		}
+		step, ok := totp.Validate(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return a.useTOTPStep(ctx, user.ID, step)
+	}
+
+	codeHash := hash.SHA256String(normalizeRecoveryCode(code))
+	hashes := strings.Split(user.MFACodes, ",")
+	for i, h := range hashes {
+		if h == "" || h != codeHash {
+			continue
+		}
+
+		// The codes are only replaced if no concurrent request has consumed one in the meantime
+		codes := strings.Join(append(hashes[:i:i], hashes[i+1:]...), ",")
+		if ok, err := a.UserRepo.ReplaceMFACodes(ctx, user.ID, user.MFACodes, codes); err != nil {
+			return false, err
+		} else if !ok {
+			logging.Context(ctx).Warn("Reused recovery code")
+			return false, nil
+		}
+		user.MFACodes = codes
+		logging.Context(ctx).Info("Recovery code used", zap.Int("remaining", len(hashes)-1))
+		return true, nil
+	}
+	return false, nil
+}
+
+// A TOTP code can only be used once within its validity window, neither can the codes of the steps before the last used one.
+func (a *Auth) useTOTPStep(ctx context.Context, userID string, step uint64) (bool, error) {
+	exp := time.Duration((2*totp.Skew+1)*totp.Period) * time.Second
+
+	// Only the first of the concurrent requests with the same code gets 1
+	n, err := a.Cache.Incr(ctx, config.CacheNSForMFA, "step:"+userID+":"+strconv.FormatUint(step, 10), exp)
+	if err != nil {
+		return false, err
+	} else if n > 1 {
+		logging.Context(ctx).Warn("Reused two-factor code")
+		return false, nil
+	}
+
+	if val, ok, err := a.Cache.Get(ctx, config.CacheNSForMFA, "step:"+userID); err != nil {
+		return false, err
+	} else if ok {
+		if last, err := strconv.ParseUint(val, 10, 64); err == nil && step <= last {
+			logging.Context(ctx).Warn("Reused two-factor code")
+			return false, nil
+		}
+	}
+	a.markTOTPStep(ctx, userID, step)
+	return true, nil
+}
+
+func (a *Auth) markTOTPStep(ctx context.Context, userID string, step uint64) {
+	exp := time.Duration((2*totp.Skew+1)*totp.Period) * time.Second
+	stepValue := strconv.FormatUint(step, 10)
+	err := a.Cache.Set(ctx, config.CacheNSForMFA, "step:"+userID+":"+stepValue, "1", exp)
+	if err == nil {
+		err = a.Cache.Set(ctx, config.CacheNSForMFA, "step:"+userID, stepValue, exp)
+	}
+	if err != nil {
+		logging.Context(ctx).Error("Failed to set used TOTP step", zap.Error(err))
+	}
+}
+
+// Generate recovery codes formatted as `xxxxx-xxxxx` and their hashes to store.
+func genRecoveryCodes(n int) ([]string, []string, error) {
+	codes := make([]string, 0, n)
+	hashes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		code, err := rand.Random(10, rand.LdigitAndLowerCase)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hash.SHA256String(code))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	return strings.ToLower(code)
+}
+
+// The key must be configured, the built-in key of the aes package is public.
+func mfaSecretKey() ([]byte, error) {
+	key := config.C.General.MFA.SecretKey
+	if key == "" {
+		return nil, errors.BadRequest("", "Two-factor authentication is not available, the secret key is not configured")
+	}
+	return []byte(key), nil
+}
+
+func encryptMFASecret(secret string) (string, error) {
+	key, err := mfaSecretKey()
+	if err != nil {
+		return "", err
+	}
+	return aes.EncryptGCMToBase64([]byte(secret), key)
+}
+
+func decryptMFASecret(encrypted string) (string, error) {
+	key, err := mfaSecretKey()
+	if err != nil {
+		return "", err
+	}
+	secret, err := aes.DecryptGCMFromBase64(encrypted, key)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/repo"
+	"go-admin/pkg/cachex"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/crypto/rand"
+	"go-admin/pkg/encoding/json"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/oidc"
+	"go-admin/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// Time to exchange the login code of the callback for the access token
+const oidcLoginCodeExp = time.Minute
+
+// Login state stored until the provider redirects back
+type oidcState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	TenantID     string `json:"tenant_id,omitempty"` // Tenant of the login request, the callback of the provider has none
+}
+
+// Single sign-on with external OpenID Connect providers
+type OIDC struct {
+	Cache            cachex.Cacher
+	Trans            *util.Trans
+	UserRepo         *repo.User
+	UserRoleRepo     *repo.UserRole
+	RoleRepo         *repo.Role
+	UserIdentityRepo *repo.UserIdentity
+	AuthService      *Auth
+
+	mu        sync.Mutex                `wire:"-"`
+	providers map[string]*oidc.Provider `wire:"-"`
+}
+
+func (a *OIDC) baseURL() string {
+	return strings.TrimSuffix(config.C.General.OIDC.BaseURL, "/")
+}
+
+func (a *OIDC) providerConfig(name string) (*config.OIDCProvider, error) {
+	for i, item := range config.C.General.OIDC.Providers {
+		if item.Name == name {
+			return &config.C.General.OIDC.Providers[i], nil
+		}
+	}
+	return nil, errors.NotFound("", "Identity provider not found")
+}
+
+// Discover the provider on first use, the discovery is retried on the next login if it fails.
+func (a *OIDC) provider(ctx context.Context, cfg *config.OIDCProvider) (*oidc.Provider, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := cfg.Name + "|" + cfg.Issuer + "|" + cfg.ClientID
+	if p, ok := a.providers[key]; ok {
+		return p, nil
+	}
+
+	p, err := oidc.NewProvider(ctx, oidc.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  a.baseURL() + "/" + cfg.Name + "/callback",
+		Scopes:       cfg.Scopes,
+	})
+	if err != nil {
+		logging.Context(ctx).Error("Failed to discover identity provider", zap.Error(err), zap.String("provider", cfg.Name))
+		return nil, errors.InternalServerError("", "Identity provider is not available")
+	}
+
+	if a.providers == nil {
+		a.providers = make(map[string]*oidc.Provider)
+	}
+	a.providers[key] = p
+	return p, nil
+}
+
+// Query the configured identity providers for the login page.
+func (a *OIDC) Providers(ctx context.Context) []*model.OIDCProvider {
+	list := make([]*model.OIDCProvider, 0, len(config.C.General.OIDC.Providers))
+	for _, item := range config.C.General.OIDC.Providers {
+		displayName := item.DisplayName
+		if displayName == "" {
+			displayName = item.Name
+		}
+		list = append(list, &model.OIDCProvider{
+			Name:         item.Name,
+			DisplayName:  displayName,
+			AuthorizeURL: a.baseURL() + "/" + item.Name + "/authorize",
+		})
+	}
+	return list
+}
+
+// Start the login with the provider and return the URL of its authorization endpoint.
+func (a *OIDC) AuthCodeURL(ctx context.Context, name string) (string, error) {
+	cfg, err := a.providerConfig(name)
+	if err != nil {
+		return "", err
+	}
+	p, err := a.provider(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	state := oidcState{
+		Provider:     name,
+		Nonce:        oidc.NewCodeVerifier(),
+		CodeVerifier: oidc.NewCodeVerifier(),
+	}
+	state.TenantID, _ = util.FromTenantID(withRequestTenant(ctx))
+	if err := a.AuthService.checkTenant(ctx, state.TenantID); err != nil {
+		return "", err
+	}
+	stateID := oidc.NewCodeVerifier()
+	exp := time.Duration(config.C.General.OIDC.StateExp) * time.Second
+	if err := a.Cache.Set(ctx, config.CacheNSForOIDC, "state:"+stateID, json.MarshalToString(state), exp); err != nil {
+		return "", err
+	}
+	return p.AuthCodeURL(stateID, state.Nonce, state.CodeVerifier), nil
+}
+
+// Complete the login at the provider and return a one-time login code for the login page,
+// users are linked by their identity at the provider, or provisioned on their first login.
+func (a *OIDC) Callback(ctx context.Context, name string, query url.Values) (string, error) {
+	ctx = logging.NewTag(ctx, logging.TagKeyLogin)
+
+	val, ok, err := a.Cache.GetAndDelete(ctx, config.CacheNSForOIDC, "state:"+query.Get("state"))
+	if err != nil {
+		return "", err
+	}
+	var state oidcState
+	if !ok || json.Unmarshal([]byte(val), &state) != nil || state.Provider != name {
+		return "", errors.BadRequest("", "Invalid or expired login state, please try again")
+	}
+	// the users are resolved in the tenant the login started in
+	ctx = util.NewTenantID(ctx, state.TenantID)
+
+	if v := query.Get("error"); v != "" {
+		logging.Context(ctx).Warn("Login is denied by the identity provider", zap.String("provider", name),
+			zap.String("error", v), zap.String("description", query.Get("error_description")))
+		return "", errors.BadRequest("", "Login is denied by the identity provider")
+	}
+
+	cfg, err := a.providerConfig(name)
+	if err != nil {
+		return "", err
+	}
+	p, err := a.provider(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := p.Exchange(ctx, query.Get("code"), state.CodeVerifier)
+	if err != nil {
+		logging.Context(ctx).Warn("Failed to exchange the authorization code", zap.Error(err), zap.String("provider", name))
+		return "", errors.BadRequest("", "Failed to login with the identity provider")
+	}
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, state.Nonce)
+	if err != nil {
+		logging.Context(ctx).Warn("Failed to verify the ID token", zap.Error(err), zap.String("provider", name))
+		return "", errors.BadRequest("", "Failed to login with the identity provider")
+	}
+
+	user, err := a.resolveUser(ctx, cfg, claims)
+	if err != nil {
+		return "", err
+	} else if user.Status != model.UserStatusActive {
+		return "", errors.BadRequest("", "User status is not active, please contact the administrator")
+	}
+	ctx = logging.NewUserID(ctx, user.ID)
+	logging.Context(ctx).Info("Login with identity provider", zap.String("provider", name), zap.String("subject", claims.Subject))
+
+	return a.AuthService.issueToken(ctx, config.CacheNSForOIDC, user.ID, oidcLoginCodeExp)
+}
+
+// Find the user of the identity, link it by the verified email or create the user.
+func (a *OIDC) resolveUser(ctx context.Context, cfg *config.OIDCProvider, claims *oidc.IDToken) (*model.User, error) {
+	userOpts := model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "status"},
+		},
+	}
+
+	identity, err := a.UserIdentityRepo.GetBySubject(ctx, cfg.Name, claims.Subject)
+	if err != nil {
+		return nil, err
+	} else if identity != nil {
+		user, err := a.UserRepo.Get(ctx, identity.UserID, userOpts)
+		if err != nil {
+			return nil, err
+		} else if user == nil {
+			return nil, errors.BadRequest("", "User not found, please contact the administrator")
+		}
+		if err := a.UserIdentityRepo.UpdateLastLogin(ctx, identity.ID, claims.Email); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	// unverified emails could be claimed by anyone at the provider
+	email := strings.TrimSpace(claims.Email)
+	if email == "" || !claims.EmailVerified {
+		return nil, errors.BadRequest("", "A verified email is required to login with the identity provider")
+	} else if email == config.C.General.Root.Email {
+		return nil, errors.BadRequest("", "The root user can't login with an identity provider")
+	}
+
+	user, err := a.UserRepo.GetByEmail(ctx, email, userOpts)
+	if err != nil {
+		return nil, err
+	} else if user != nil && !cfg.LinkByEmail {
+		return nil, errors.BadRequest("", "Email already exists, please login with your password")
+	} else if user == nil && !cfg.AutoCreate {
+		return nil, errors.BadRequest("", "User not found, please contact the administrator")
+	}
+
+	now := time.Now()
+	identity = &model.UserIdentity{
+		ID:          util.NewXID(),
+		Provider:    cfg.Name,
+		Subject:     claims.Subject,
+		Email:       email,
+		LastLoginAt: &now,
+		CreatedAt:   now,
+	}
+
+	if user != nil {
+		identity.UserID = user.ID
+		if err := a.UserIdentityRepo.Create(ctx, identity); err != nil {
+			return nil, err
+		}
+		logging.Context(ctx).Info("Link identity to user", zap.String("provider", cfg.Name), zap.String("user_id", user.ID))
+		return user, nil
+	}
+
+	user, err = a.newUser(email, claims)
+	if err != nil {
+		return nil, err
+	}
+	identity.UserID = user.ID
+
+	err = a.Trans.Exec(ctx, func(ctx context.Context) error {
+		if err := a.UserRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		for _, roleID := range cfg.DefaultRoleIDs {
+			// the default roles may not exist in the tenant of the user
+			if exists, err := a.RoleRepo.Exists(ctx, roleID); err != nil {
+				return err
+			} else if !exists {
+				logging.Context(ctx).Warn("Default role of identity provider not found", zap.String("provider", cfg.Name), zap.String("role_id", roleID))
+				continue
+			}
+			userRole := &model.UserRole{
+				ID:        util.NewXID(),
+				UserID:    user.ID,
+				RoleID:    roleID,
+				CreatedAt: now,
+			}
+			if err := a.UserRoleRepo.Create(ctx, userRole); err != nil {
+				return err
+			}
+		}
+		return a.UserIdentityRepo.Create(ctx, identity)
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.Context(ctx).Info("Create user with identity", zap.String("provider", cfg.Name), zap.String("user_id", user.ID))
+	return user, nil
+}
+
+// New active user with a random password, it can only login with the provider until the password is reset.
+func (a *OIDC) newUser(email string, claims *oidc.IDToken) (*model.User, error) {
+	password, err := rand.Random(32, rand.LdigitAndLetter)
+	if err != nil {
+		return nil, err
+	}
+	hashPass, err := hash.GeneratePassword(hash.MD5String(password))
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := strings.TrimSpace(claims.Name)
+	if fullName == "" {
+		fullName = strings.TrimSpace(claims.GivenName + " " + claims.FamilyName)
+	}
+	if fullName == "" {
+		fullName = email
+	}
+
+	return &model.User{
+		ID:        util.NewXID(),
+		Email:     email,
+		FirstName: claims.GivenName,
+		LastName:  claims.FamilyName,
+		FullName:  fullName,
+		Password:  hashPass,
+		Status:    model.UserStatusActive,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Return the URL of the login page with the login code, or with the error message if the login failed.
+func (a *OIDC) LoginURL(ctx context.Context, code string, err error) string {
+	v := url.Values{}
+	if err != nil {
+		msg := "Failed to login with the identity provider"
+		if e, ok := errors.As(err); ok && e.Code < http.StatusInternalServerError {
+			msg = e.Detail
+		} else {
+			logging.Context(ctx).Error("Failed to login with identity provider", zap.Error(err))
+		}
+		v.Set("error", msg)
+	} else {
+		v.Set("code", code)
+	}
+
+	link := config.C.General.OIDC.LoginURL
+	if strings.Contains(link, "?") {
+		return link + "&" + v.Encode()
+	}
+	return link + "?" + v.Encode()
+}
+
+// Exchange the login code of the callback for the access token. The password is not checked, the user
+// is authenticated by the identity provider, but the users with two-factor authentication must still complete it.
+func (a *OIDC) Login(ctx context.Context, formItem *model.OIDCLoginForm) (*model.LoginToken, error) {
+	ctx = logging.NewTag(ctx, logging.TagKeyLogin)
+	ctx, attempt := newLoginAttempt(ctx, model.LoginMethodOIDC, "")
+	token, err := a.login(ctx, formItem)
+	a.AuthService.LoginHistoryService.Record(ctx, attempt, token, err)
+	return token, err
+}
+
+func (a *OIDC) login(ctx context.Context, formItem *model.OIDCLoginForm) (*model.LoginToken, error) {
+	ctx, userID, ok, err := a.AuthService.consumeToken(ctx, config.CacheNSForOIDC, formItem.Code)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.BadRequest(config.ErrInvalidOIDCCode, "Invalid or expired login code")
+	}
+	ctx = logging.NewUserID(ctx, userID)
+	setLoginAttempt(ctx, "", userID)
+
+	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "email", "status", "tenant_id", "mfa_enabled"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	} else if user == nil || user.Status != model.UserStatusActive {
+		return nil, errors.BadRequest(config.ErrInvalidOIDCCode, "Invalid or expired login code")
+	}
+	return a.AuthService.loginAuthenticated(ctx, user.Email, user, false)
+}
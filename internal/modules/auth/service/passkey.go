@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/repo"
+	"go-admin/pkg/cachex"
+	"go-admin/pkg/crypto/rand"
+	"go-admin/pkg/encoding/json"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+	"go-admin/pkg/webauthn"
+
+	"go.uber.org/zap"
+)
+
+// Ceremony state stored until the response of the authenticator is sent back
+type passkeyState struct {
+	UserID    string `json:"user_id"`
+	Challenge []byte `json:"challenge"`
+}
+
+// Passwordless login with WebAuthn credentials
+type Passkey struct {
+	Cache       cachex.Cacher
+	UserRepo    *repo.User
+	PasskeyRepo *repo.Passkey
+}
+
+func (a *Passkey) relyingParty() *webauthn.RelyingParty {
+	cfg := config.C.General.WebAuthn
+	name := cfg.RPName
+	if name == "" {
+		name = config.C.General.AppName
+	}
+	return webauthn.New(webauthn.Config{
+		RPID:             cfg.RPID,
+		RPName:           name,
+		Origins:          cfg.Origins,
+		Timeout:          time.Duration(cfg.ChallengeExp) * time.Second,
+		UserVerification: cfg.UserVerification,
+	})
+}
+
+// Store a new challenge of the ceremony and return its ID.
+func (a *Passkey) newChallenge(ctx context.Context, kind, userID string) (string, []byte, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", nil, err
+	}
+	challengeID, err := rand.Random(32, rand.LdigitAndLetter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	state := passkeyState{UserID: userID, Challenge: challenge}
+	exp := time.Duration(config.C.General.WebAuthn.ChallengeExp) * time.Second
+	if err := a.Cache.Set(ctx, config.CacheNSForWebAuthn, kind+":"+challengeID, json.MarshalToString(state), exp); err != nil {
+		return "", nil, err
+	}
+	return challengeID, challenge, nil
+}
+
+// Consume the challenge of the ceremony, each challenge can be answered only once.
+func (a *Passkey) consumeChallenge(ctx context.Context, kind, challengeID string) (*passkeyState, error) {
+	val, ok, err := a.Cache.GetAndDelete(ctx, config.CacheNSForWebAuthn, kind+":"+challengeID)
+	if err != nil {
+		return nil, err
+	}
+	var state passkeyState
+	if !ok || json.Unmarshal([]byte(val), &state) != nil {
+		return nil, errors.BadRequest(config.ErrInvalidPasskey, "Invalid or expired challenge, please try again")
+	}
+	return &state, nil
+}
+
+func toCredentialDescriptors(passkeys model.Passkeys) []webauthn.CredentialDescriptor {
+	list := make([]webauthn.CredentialDescriptor, 0, len(passkeys))
+	for _, item := range passkeys {
+		id, err := base64.RawURLEncoding.DecodeString(item.CredentialID)
+		if err != nil {
+			continue
+		}
+		list = append(list, webauthn.CredentialDescriptor{
+			Type:       webauthn.PublicKeyType,
+			ID:         id,
+			Transports: item.Transports,
+		})
+	}
+	return list
+}
+
+func formatAAGUID(b []byte) string {
+	if len(b) != 16 {
+		return ""
+	}
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Query the passkeys of the user.
+func (a *Passkey) Query(ctx context.Context, userID string) (model.Passkeys, error) {
+	return a.PasskeyRepo.QueryByUserID(ctx, userID)
+}
+
+// Start the registration of a passkey for the user, the existing passkeys are excluded
+// so an authenticator can't be registered twice.
+func (a *Passkey) BeginRegistration(ctx context.Context, userID string) (*model.PasskeyChallenge, error) {
+	if userID == config.C.General.Root.ID {
+		return nil, errors.BadRequest("", "The root user can't register passkeys")
+	}
+
+	user, err := a.UserRepo.Get(ctx, userID, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{SelectFields: []string{"id", "email", "full_name"}},
+	})
+	if err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, errors.NotFound("", "User not found")
+	}
+	passkeys, err := a.PasskeyRepo.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeID, challenge, err := a.newChallenge(ctx, "register", userID)
+	if err != nil {
+		return nil, err
+	}
+	options := a.relyingParty().CreationOptions(challenge, webauthn.UserEntity{
+		ID:          []byte(user.ID),
+		Name:        user.Email,
+		DisplayName: user.FullName,
+	}, toCredentialDescriptors(passkeys))
+	return &model.PasskeyChallenge{ChallengeID: challengeID, Options: options}, nil
+}
+
+// Verify the credential created by the authenticator and store it as passkey of the user.
+func (a *Passkey) FinishRegistration(ctx context.Context, userID string, formItem *model.PasskeyRegisterForm) (*model.Passkey, error) {
+	state, err := a.consumeChallenge(ctx, "register", formItem.ChallengeID)
+	if err != nil {
+		return nil, err
+	} else if state.UserID != userID {
+		return nil, errors.BadRequest(config.ErrInvalidPasskey, "Invalid or expired challenge, please try again")
+	}
+
+	cred, err := a.relyingParty().FinishRegistration(state.Challenge, &formItem.Credential)
+	if err != nil {
+		logging.Context(ctx).Warn("Failed to verify the passkey registration", zap.Error(err))
+		return nil, errors.BadRequest(config.ErrInvalidPasskey, "Failed to verify the passkey")
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(cred.ID)
+	if exists, err := a.PasskeyRepo.GetByCredentialID(ctx, credentialID); err != nil {
+		return nil, err
+	} else if exists != nil {
+		return nil, errors.BadRequest("", "Passkey is already registered")
+	}
+
+	passkey := &model.Passkey{
+		ID:           util.NewXID(),
+		UserID:       userID,
+		Name:         formItem.Name,
+		CredentialID: credentialID,
+		PublicKey:    cred.PublicKey,
+		AAGUID:       formatAAGUID(cred.AAGUID),
+		SignCount:    cred.SignCount,
+		Transports:   cred.Transports,
+		CreatedAt:    time.Now(),
+	}
+	if err := a.PasskeyRepo.Create(ctx, passkey); err != nil {
+		return nil, err
+	}
+	logging.Context(ctx).Info("Register passkey", zap.String("passkey_id", passkey.ID), zap.String("user_id", userID))
+	return passkey, nil
+}
+
+// Delete the passkey of the user, it can't be used to login anymore.
+func (a *Passkey) Delete(ctx context.Context, userID, id string) error {
+	passkey, err := a.PasskeyRepo.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	} else if passkey == nil {
+		return errors.NotFound("", "Passkey not found")
+	}
+
+	if err := a.PasskeyRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	logging.Context(ctx).Info("Delete passkey", zap.String("passkey_id", id), zap.String("user_id", userID))
+	return nil
+}
+
+// Delete all passkeys of the user.
+func (a *Passkey) Clear(ctx context.Context, userID string) error {
+	return a.PasskeyRepo.DeleteByUserID(ctx, userID)
+}
+
+// Start the login with a passkey. The passkeys of the user are allowed if the email is given, otherwise
+// the authenticator offers its discoverable passkeys. Unknown emails get the same options as the users
+// without passkeys, so the response doesn't reveal the registered emails.
+func (a *Passkey) BeginLogin(ctx context.Context, email string) (*model.PasskeyChallenge, error) {
+	var userID string
+	var allow []webauthn.CredentialDescriptor
+	if email != "" {
+		user, err := a.UserRepo.GetByEmail(ctx, email, model.UserQueryOptions{
+			QueryOptions: util.QueryOptions{SelectFields: []string{"id"}},
+		})
+		if err != nil {
+			return nil, err
+		} else if user != nil {
+			passkeys, err := a.PasskeyRepo.QueryByUserID(ctx, user.ID)
+			if err != nil {
+				return nil, err
+			} else if len(passkeys) > 0 {
+				userID = user.ID
+				allow = toCredentialDescriptors(passkeys)
+			}
+		}
+	}
+
+	challengeID, challenge, err := a.newChallenge(ctx, "login", userID)
+	if err != nil {
+		return nil, err
+	}
+	options := a.relyingParty().RequestOptions(challenge, allow)
+	return &model.PasskeyChallenge{ChallengeID: challengeID, Options: options}, nil
+}
+
+// Verify the assertion of the authenticator and return the ID of the user who owns the passkey.
+func (a *Passkey) FinishLogin(ctx context.Context, formItem *model.PasskeyLoginFinishForm) (string, error) {
+	state, err := a.consumeChallenge(ctx, "login", formItem.ChallengeID)
+	if err != nil {
+		return "", err
+	}
+
+	resp := &formItem.Credential
+	passkey, err := a.PasskeyRepo.GetByCredentialID(ctx, base64.RawURLEncoding.EncodeToString(resp.RawID))
+	if err != nil {
+		return "", err
+	} else if passkey == nil ||
+		(state.UserID != "" && state.UserID != passkey.UserID) ||
+		(len(resp.Response.UserHandle) > 0 && string(resp.Response.UserHandle) != passkey.UserID) {
+		return "", errors.BadRequest(config.ErrInvalidPasskey, "Invalid passkey")
+	}
+
+	signCount, err := a.relyingParty().FinishLogin(state.Challenge, &webauthn.Credential{
+		ID:        resp.RawID,
+		PublicKey: passkey.PublicKey,
+		SignCount: passkey.SignCount,
+	}, resp)
+	if err != nil {
+		logging.Context(ctx).Warn("Failed to verify the passkey assertion", zap.Error(err),
+			zap.String("passkey_id", passkey.ID), zap.String("user_id", passkey.UserID))
+		return "", errors.BadRequest(config.ErrInvalidPasskey, "Invalid passkey")
+	}
+
+	if err := a.PasskeyRepo.UpdateSignCount(ctx, passkey.ID, signCount, time.Now()); err != nil {
+		return "", err
+	}
+	return passkey.UserID, nil
+}
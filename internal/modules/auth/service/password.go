@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/repo"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+)
+
+// Password policy and password history of users
+type Password struct {
+	PasswordHistoryRepo *repo.PasswordHistory
+	policy              *util.PasswordPolicy `wire:"-"`
+}
+
+// Load the password policy from the config, including the banned passwords file.
+func (a *Password) Load(ctx context.Context) error {
+	cfg := config.C.General.PasswordPolicy
+	policy := &util.PasswordPolicy{
+		MinLength:     cfg.MinLength,
+		RequireUpper:  cfg.RequireUpper,
+		RequireLower:  cfg.RequireLower,
+		RequireDigit:  cfg.RequireDigit,
+		RequireSymbol: cfg.RequireSymbol,
+	}
+
+	if name := cfg.BannedFile; name != "" {
+		data, err := os.ReadFile(filepath.Join(config.C.General.WorkDir, name))
+		if err != nil {
+			return err
+		}
+		policy.Ban(strings.Split(string(data), "\n")...)
+	}
+
+	a.policy = policy
+	return nil
+}
+
+// Check the new password against the policy and the password history of the user, `currentHash` is
+// the current password of the user. New users have no history, use empty `userID` and `currentHash`.
+func (a *Password) Check(ctx context.Context, userID, email, password, currentHash string) error {
+	cfg := config.C.General.PasswordPolicy
+	if cfg.Enable && a.policy != nil {
+		if violations := a.policy.Check(password, email); len(violations) > 0 {
+			return errors.BadRequest(config.ErrInvalidPassword, "Password must %s", strings.Join(violations, ", "))
+		}
+	}
+
+	if cfg.HistorySize <= 0 || userID == "" {
+		return nil
+	}
+
+	reused := errors.BadRequest(config.ErrPasswordReused, "Password must not be one of the last %d passwords", cfg.HistorySize)
+	if currentHash != "" && hash.CompareHashAndPassword(currentHash, password) == nil {
+		return reused
+	}
+
+	histories, err := a.PasswordHistoryRepo.QueryRecent(ctx, userID, cfg.HistorySize)
+	if err != nil {
+		return err
+	}
+	for _, item := range histories {
+		if hash.CompareHashAndPassword(item.Password, password) == nil {
+			return reused
+		}
+	}
+	return nil
+}
+
+// Record the replaced password of the user in the history, only the last `HistorySize` passwords are kept.
+func (a *Password) Record(ctx context.Context, userID, oldHash string) error {
+	size := config.C.General.PasswordPolicy.HistorySize
+	if size <= 0 || oldHash == "" {
+		return nil
+	}
+
+	err := a.PasswordHistoryRepo.Create(ctx, &model.PasswordHistory{
+		ID:        util.NewXID(),
+		UserID:    userID,
+		Password:  oldHash,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return a.PasswordHistoryRepo.Prune(ctx, userID, size)
+}
+
+// Delete the password history of the user.
+func (a *Password) Clear(ctx context.Context, userID string) error {
+	return a.PasswordHistoryRepo.DeleteByUserID(ctx, userID)
+}
+
+// Check if the password of the user is older than `MaxAge` and must be changed.
+func (a *Password) Expired(user *model.User) bool {
+	// users of the directory have no local password
+	maxAge := config.C.General.PasswordPolicy.MaxAge
+	if maxAge <= 0 || user.Password == "" {
+		return false
+	}
+
+	changedAt := user.CreatedAt
+	if user.PwdChangedAt != nil {
+		changedAt = *user.PwdChangedAt
+	}
+	return time.Since(changedAt) > time.Duration(maxAge)*24*time.Hour
+}
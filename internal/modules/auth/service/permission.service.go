@@ -2,10 +2,8 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"time"
 
-	"go-admin/internal/config"
 	"go-admin/internal/modules/auth/model"
 	"go-admin/internal/modules/auth/repo"
 	"go-admin/pkg/cachex"
@@ -74,10 +72,7 @@ func (a *Permission) Create(ctx context.Context, formItem *model.PermissionForm)
 	}
 
 	err := a.Trans.Exec(ctx, func(ctx context.Context) error {
-		if err := a.PermissionRepo.Create(ctx, role); err != nil {
-			return err
-		}
-		return a.syncToCasbin(ctx)
+		return a.PermissionRepo.Create(ctx, role)
 	})
 	if err != nil {
 		return nil, err
@@ -92,6 +87,8 @@ func (a *Permission) Update(ctx context.Context, id string, formItem *model.Perm
 		return err
 	} else if role == nil {
 		return errors.NotFound("", "Permission not found")
+	} else if tenantID, ok := util.FromTenantID(ctx); ok && role.TenantID != tenantID {
+		return errors.Forbidden("", "Permissions of the default tenant can't be changed")
 	} else if role.Code != formItem.Code {
 		if exists, err := a.PermissionRepo.ExistsCode(ctx, formItem.Code); err != nil {
 			return err
@@ -104,31 +101,64 @@ func (a *Permission) Update(ctx context.Context, id string, formItem *model.Perm
 		return err
 	}
 	role.UpdatedAt = time.Now()
-	return a.Trans.Exec(ctx, func(ctx context.Context) error {
-		if err := a.PermissionRepo.Update(ctx, role); err != nil {
-			return err
-		}
-		return a.syncToCasbin(ctx)
+
+	roleIDs, err := a.queryRoleIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = a.Trans.Exec(ctx, func(ctx context.Context) error {
+		return a.PermissionRepo.Update(ctx, role)
 	})
+	if err != nil {
+		return err
+	}
+	return a.syncToCasbin(ctx, roleIDs...)
 }
 
 // Delete the specified role from the data access object.
 func (a *Permission) Delete(ctx context.Context, id string) error {
-	exists, err := a.PermissionRepo.Exists(ctx, id)
+	permission, err := a.PermissionRepo.Get(ctx, id)
 	if err != nil {
 		return err
-	} else if !exists {
+	} else if permission == nil {
 		return errors.NotFound("", "Permission not found")
+	} else if tenantID, ok := util.FromTenantID(ctx); ok && permission.TenantID != tenantID {
+		return errors.Forbidden("", "Permissions of the default tenant can't be changed")
 	}
 
-	return a.Trans.Exec(ctx, func(ctx context.Context) error {
+	roleIDs, err := a.queryRoleIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = a.Trans.Exec(ctx, func(ctx context.Context) error {
 		if err := a.PermissionRepo.Delete(ctx, id); err != nil {
 			return err
 		}
-		return a.syncToCasbin(ctx)
+		return a.RolePermissionRepo.DeleteByPermissionID(ctx, id)
 	})
+	if err != nil {
+		return err
+	}
+	return a.syncToCasbin(ctx, roleIDs...)
+}
+
+// Query the IDs of the roles that are granted the permission.
+func (a *Permission) queryRoleIDs(ctx context.Context, id string) ([]string, error) {
+	rolePermissionResult, err := a.RolePermissionRepo.Query(ctx, model.RolePermissionQueryParam{
+		PermissionID: id,
+	}, model.RolePermissionQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"role_id"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rolePermissionResult.Data.ToRoleIDs(), nil
 }
 
-func (a *Permission) syncToCasbin(ctx context.Context) error {
-	return a.Cache.Set(ctx, config.CacheNSForPermission, config.CacheKeyForSyncToCasbin, fmt.Sprintf("%d", time.Now().Unix()))
+func (a *Permission) syncToCasbin(ctx context.Context, roleIDs ...string) error {
+	return syncRolesToCasbin(ctx, a.Cache, roleIDs...)
 }
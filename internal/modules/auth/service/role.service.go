@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"slices"
 	"time"
 
 	"go-admin/internal/config"
@@ -65,6 +65,14 @@ func (a *Role) Get(ctx context.Context, id string) (*model.Role, error) {
 	}
 	role.Menus = roleMenuResult.Data
 
+	rolePermissionResult, err := a.RolePermissionRepo.Query(ctx, model.RolePermissionQueryParam{
+		RoleID: id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	role.Permissions = rolePermissionResult.Data
+
 	return role, nil
 }
 
@@ -82,6 +90,8 @@ func (a *Role) Create(ctx context.Context, formItem *model.RoleForm) (*model.Rol
 	}
 	if err := formItem.FillTo(role); err != nil {
 		return nil, err
+	} else if err := a.checkParents(ctx, role); err != nil {
+		return nil, err
 	}
 
 	err := a.Trans.Exec(ctx, func(ctx context.Context) error {
@@ -105,12 +115,15 @@ func (a *Role) Create(ctx context.Context, formItem *model.RoleForm) (*model.Rol
 				return err
 			}
 		}
-		return a.syncToCasbin(ctx)
+		return nil
 	})
 	if err != nil {
 		return nil, err
+	} else if err := a.syncToCasbin(ctx, role.ID); err != nil {
+		return nil, err
 	}
 	role.Menus = formItem.Menus
+	role.Permissions = formItem.Permissions
 
 	return role, nil
 }
@@ -130,12 +143,16 @@ func (a *Role) Update(ctx context.Context, id string, formItem *model.RoleForm)
 		}
 	}
 
+	oldStatus, oldDataScope, oldDataUserIDs := role.Status, role.DataScope, role.DataUserIDs
+	oldDataDepartmentIDs, oldParentIDs := role.DataDepartmentIDs, role.ParentIDs
 	if err := formItem.FillTo(role); err != nil {
 		return err
+	} else if err := a.checkParents(ctx, role); err != nil {
+		return err
 	}
 	role.UpdatedAt = time.Now()
 
-	return a.Trans.Exec(ctx, func(ctx context.Context) error {
+	err = a.Trans.Exec(ctx, func(ctx context.Context) error {
 		if err := a.RoleRepo.Update(ctx, role); err != nil {
 			return err
 		}
@@ -171,8 +188,23 @@ func (a *Role) Update(ctx context.Context, id string, formItem *model.RoleForm)
 				return err
 			}
 		}
-		return a.syncToCasbin(ctx)
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if role.Status != oldStatus || role.DataScope != oldDataScope || !slices.Equal(role.DataUserIDs, oldDataUserIDs) ||
+		!slices.Equal(role.DataDepartmentIDs, oldDataDepartmentIDs) || !slices.Equal(role.ParentIDs, oldParentIDs) {
+		userIDs, err := a.queryUserIDs(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := a.clearUserCaches(ctx, userIDs...); err != nil {
+			return err
+		}
+	}
+	return a.syncToCasbin(ctx, id)
 }
 
 // Delete the specified role from the data access object.
@@ -184,21 +216,200 @@ func (a *Role) Delete(ctx context.Context, id string) error {
 		return errors.NotFound("", "Role not found")
 	}
 
-	return a.Trans.Exec(ctx, func(ctx context.Context) error {
+	userIDs, err := a.queryUserIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	childResult, err := a.RoleRepo.Query(ctx, model.RoleQueryParam{
+		ParentID: id,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = a.Trans.Exec(ctx, func(ctx context.Context) error {
 		if err := a.RoleRepo.Delete(ctx, id); err != nil {
 			return err
 		}
+		for _, child := range childResult.Data {
+			child.ParentIDs = slices.DeleteFunc(child.ParentIDs, func(parentID string) bool { return parentID == id })
+			child.UpdatedAt = time.Now()
+			if err := a.RoleRepo.Update(ctx, child); err != nil {
+				return err
+			}
+		}
 		if err := a.RoleMenuRepo.DeleteByRoleID(ctx, id); err != nil {
 			return err
 		}
+		if err := a.RolePermissionRepo.DeleteByRoleID(ctx, id); err != nil {
+			return err
+		}
 		if err := a.UserRoleRepo.DeleteByRoleID(ctx, id); err != nil {
 			return err
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-		return a.syncToCasbin(ctx)
+	if err := a.clearUserCaches(ctx, userIDs...); err != nil {
+		return err
+	}
+	roleIDs := []string{id}
+	for _, child := range childResult.Data {
+		roleIDs = append(roleIDs, child.ID)
+	}
+	return a.syncToCasbin(ctx, roleIDs...)
+}
+
+// Check the parents of the role exist and don't inherit the role itself, directly or through their own parents.
+func (a *Role) checkParents(ctx context.Context, role *model.Role) error {
+	if len(role.ParentIDs) == 0 {
+		return nil
+	} else if slices.Contains(role.ParentIDs, role.ID) {
+		return errors.BadRequest("", "Role can't inherit itself")
+	}
+
+	parentResult, err := a.RoleRepo.Query(ctx, model.RoleQueryParam{
+		InIDs: role.ParentIDs,
+	}, model.RoleQueryOptions{
+		QueryOptions: util.QueryOptions{SelectFields: []string{"id"}},
 	})
+	if err != nil {
+		return err
+	} else if len(parentResult.Data) != len(role.ParentIDs) {
+		return errors.BadRequest("", "Parent role not found")
+	}
+
+	ancestorIDs, err := expandRoleIDs(ctx, a.RoleRepo, role.ParentIDs, false)
+	if err != nil {
+		return err
+	} else if slices.Contains(ancestorIDs, role.ID) {
+		return errors.BadRequest("", "Role inheritance can't be circular")
+	}
+	return nil
+}
+
+// Query the users of the role and of the roles inheriting it directly or through their own parents,
+// their data scopes all depend on the role.
+func (a *Role) queryUserIDs(ctx context.Context, roleID string) ([]string, error) {
+	roleIDs := []string{roleID}
+	for next := []string{roleID}; len(next) > 0; {
+		var children []string
+		for _, parentID := range next {
+			childResult, err := a.RoleRepo.Query(ctx, model.RoleQueryParam{
+				ParentID: parentID,
+			}, model.RoleQueryOptions{
+				QueryOptions: util.QueryOptions{SelectFields: []string{"id"}},
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range childResult.Data {
+				if !slices.Contains(roleIDs, child.ID) {
+					roleIDs = append(roleIDs, child.ID)
+					children = append(children, child.ID)
+				}
+			}
+		}
+		next = children
+	}
+
+	var userIDs []string
+	for _, id := range roleIDs {
+		userRoleResult, err := a.UserRoleRepo.Query(ctx, model.UserRoleQueryParam{
+			RoleID: id,
+		}, model.UserRoleQueryOptions{
+			QueryOptions: util.QueryOptions{
+				SelectFields: []string{"user_id"},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, userRole := range userRoleResult.Data {
+			if !slices.Contains(userIDs, userRole.UserID) {
+				userIDs = append(userIDs, userRole.UserID)
+			}
+		}
+	}
+	return userIDs, nil
+}
+
+// Clear the caches of the users, so their data scopes are rebuilt with their current roles.
+// It must be called after the transaction is committed, otherwise the caches may be rebuilt with stale data.
+func (a *Role) clearUserCaches(ctx context.Context, userIDs ...string) error {
+	for _, userID := range userIDs {
+		if err := a.Cache.Delete(ctx, config.CacheNSForUser, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Role) syncToCasbin(ctx context.Context, roleIDs ...string) error {
+	return syncRolesToCasbin(ctx, a.Cache, roleIDs...)
+}
+
+// Expand the roles with the roles they inherit, directly or through their parents.
+// The disabled roles and their parents are not inherited if enabledOnly is set, like the casbin policy does.
+func expandRoleIDs(ctx context.Context, roleRepo *repo.Role, roleIDs []string, enabledOnly bool) ([]string, error) {
+	ids := slices.Clone(roleIDs)
+	seen := make(map[string]struct{}, len(roleIDs))
+	for _, id := range roleIDs {
+		seen[id] = struct{}{}
+	}
+
+	var status string
+	if enabledOnly {
+		status = model.RoleStatusEnabled
+	}
+	for next, inherited := roleIDs, false; len(next) > 0; inherited = true {
+		roleResult, err := roleRepo.Query(ctx, model.RoleQueryParam{
+			InIDs:  next,
+			Status: status,
+		}, model.RoleQueryOptions{
+			QueryOptions: util.QueryOptions{SelectFields: []string{"id", "parent_ids"}},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		next = nil
+		for _, role := range roleResult.Data {
+			if inherited {
+				ids = append(ids, role.ID)
+			}
+			for _, parentID := range role.ParentIDs {
+				if _, ok := seen[parentID]; !ok {
+					seen[parentID] = struct{}{}
+					next = append(next, parentID)
+				}
+			}
+		}
+	}
+	return ids, nil
 }
 
-func (a *Role) syncToCasbin(ctx context.Context) error {
-	return a.Cache.Set(ctx, config.CacheNSForRole, config.CacheKeyForSyncToCasbin, fmt.Sprintf("%d", time.Now().Unix()))
+// Notify Casbinx to reload the policies of the roles by marking the roles for the next poll,
+// and also by publishing the role IDs when the cache supports it so the reload is immediate.
+// It must be called after the transaction is committed, otherwise the reload may read stale data.
+func syncRolesToCasbin(ctx context.Context, cache cachex.Cacher, roleIDs ...string) error {
+	mark := util.NewXID()
+	for _, roleID := range roleIDs {
+		if err := cache.Set(ctx, config.CacheNSForCasbin, roleID, mark, config.CacheExpForSyncToCasbin); err != nil {
+			return err
+		}
+	}
+
+	if notifier, ok := cache.(cachex.Notifier); ok {
+		for _, roleID := range roleIDs {
+			if err := notifier.Publish(ctx, config.CacheNSForCasbin, roleID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
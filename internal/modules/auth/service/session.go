@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/cachex"
+	"go-admin/pkg/encoding/json"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/jwtx"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// Minimum interval between two updates of the last seen time of a session
+const sessionTouchInterval = time.Minute
+
+// Login sessions of users, a session lives as long as its refresh token
+type Session struct {
+	Cache cachex.Cacher
+	Auth  jwtx.Auther
+}
+
+// Sessions of a user are kept in their own namespace so they can be listed without scanning all sessions.
+func sessionNS(userID string) string {
+	return config.CacheNSForSession + ":" + userID
+}
+
+// Record the session of the token generated by a login.
+func (a *Session) Create(ctx context.Context, userID string, token jwtx.TokenInfo) error {
+	if token.GetSessionID() == "" {
+		return nil
+	}
+
+	client := util.FromClientInfo(ctx)
+	now := time.Now()
+	session := &model.Session{
+		ID:         token.GetSessionID(),
+		UserID:     userID,
+		Device:     parseDevice(client.UserAgent),
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		IssuedAt:   now,
+		LastSeenAt: now,
+		ExpiresAt:  time.Unix(token.GetRefreshExpiresAt(), 0),
+	}
+	return a.save(ctx, session)
+}
+
+func (a *Session) save(ctx context.Context, session *model.Session) error {
+	exp := time.Until(session.ExpiresAt)
+	if exp <= 0 {
+		return nil
+	}
+	return a.Cache.Set(ctx, sessionNS(session.UserID), session.ID, json.MarshalToString(session), exp)
+}
+
+func (a *Session) get(ctx context.Context, userID, sessionID string) (*model.Session, error) {
+	val, ok, err := a.Cache.Get(ctx, sessionNS(userID), sessionID)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var session model.Session
+	if err := json.Unmarshal([]byte(val), &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Update the last seen time and client of the session, at most once per sessionTouchInterval from the same IP.
+func (a *Session) Touch(ctx context.Context, userID, sessionID string) error {
+	return a.update(ctx, userID, sessionID, false, 0)
+}
+
+// Extend the session to the expiration of the rotated refresh token.
+func (a *Session) Refresh(ctx context.Context, userID string, token jwtx.TokenInfo) error {
+	return a.update(ctx, userID, token.GetSessionID(), true, token.GetRefreshExpiresAt())
+}
+
+func (a *Session) update(ctx context.Context, userID, sessionID string, force bool, expiresAt int64) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	session, err := a.get(ctx, userID, sessionID)
+	if err != nil || session == nil {
+		return err
+	}
+
+	client := util.FromClientInfo(ctx)
+	if !force && time.Since(session.LastSeenAt) < sessionTouchInterval && client.IP == session.IP {
+		return nil
+	}
+
+	session.LastSeenAt = time.Now()
+	if client.UserAgent != "" {
+		session.IP = client.IP
+		session.UserAgent = client.UserAgent
+		session.Device = parseDevice(client.UserAgent)
+	}
+	if expiresAt > 0 {
+		session.ExpiresAt = time.Unix(expiresAt, 0)
+	}
+	return a.save(ctx, session)
+}
+
+// Query the sessions of the user, the most recently used first.
+func (a *Session) Query(ctx context.Context, userID string) (model.Sessions, error) {
+	_, currentID, _ := a.Auth.ParseSession(ctx, util.FromUserToken(ctx))
+
+	var sessions model.Sessions
+	err := a.Cache.Iterator(ctx, sessionNS(userID), func(ctx context.Context, key, value string) bool {
+		var session model.Session
+		if err := json.Unmarshal([]byte(value), &session); err != nil {
+			logging.Context(ctx).Warn("Invalid session", zap.Error(err), zap.String("key", key))
+			return true
+		}
+		session.Current = session.ID == currentID
+		sessions = append(sessions, &session)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Sort(sessions)
+	return sessions, nil
+}
+
+// Revoke a session of the user, its tokens become invalid immediately.
+func (a *Session) Revoke(ctx context.Context, userID, sessionID string) error {
+	exists, err := a.Cache.Exists(ctx, sessionNS(userID), sessionID)
+	if err != nil {
+		return err
+	} else if !exists {
+		return errors.NotFound("", "Session not found")
+	}
+	return a.revoke(ctx, userID, sessionID)
+}
+
+// Revoke all sessions of the user except the session `exceptID` (if not empty).
+func (a *Session) RevokeAll(ctx context.Context, userID, exceptID string) error {
+	var sessionIDs []string
+	err := a.Cache.Iterator(ctx, sessionNS(userID), func(ctx context.Context, key, value string) bool {
+		if key != exceptID {
+			sessionIDs = append(sessionIDs, key)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if err := a.revoke(ctx, userID, sessionID); err != nil {
+			return err
+		}
+	}
+	if len(sessionIDs) > 0 {
+		logging.Context(ctx).Info("Revoke user sessions", zap.String("user_id", userID), zap.Int("count", len(sessionIDs)))
+	}
+	return nil
+}
+
+func (a *Session) revoke(ctx context.Context, userID, sessionID string) error {
+	if err := a.Auth.RevokeSession(ctx, sessionID); err != nil {
+		return err
+	}
+	return a.Cache.Delete(ctx, sessionNS(userID), sessionID)
+}
+
+var (
+	deviceOSNames = [][2]string{
+		{"iPhone", "iPhone"}, {"iPad", "iPad"}, {"Android", "Android"},
+		{"Windows", "Windows"}, {"Mac OS X", "macOS"}, {"CrOS", "ChromeOS"}, {"Linux", "Linux"},
+	}
+	deviceBrowserNames = [][2]string{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"}, {"Safari/", "Safari"},
+	}
+)
+
+// Parse a readable device name like "Chrome on Windows" from the user agent.
+func parseDevice(userAgent string) string {
+	var os, browser string
+	for _, item := range deviceOSNames {
+		if strings.Contains(userAgent, item[0]) {
+			os = item[1]
+			break
+		}
+	}
+	for _, item := range deviceBrowserNames {
+		if strings.Contains(userAgent, item[0]) {
+			browser = item[1]
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	case userAgent != "":
+		// Non-browser clients, e.g. "curl/8.0.1"
+		name, _, _ := strings.Cut(userAgent, " ")
+		name, _, _ = strings.Cut(name, "/")
+		return name
+	}
+	return "Unknown"
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/internal/modules/auth/repo"
+	"go-admin/pkg/cachex"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/util"
+)
+
+// Tenant management of the deployment
+type Tenant struct {
+	Cache      cachex.Cacher
+	TenantRepo *repo.Tenant
+	UserRepo   *repo.User
+}
+
+// Tenants are managed across tenants only, e.g. by root users without a tenant header.
+func checkCrossTenant(ctx context.Context) error {
+	if _, ok := util.FromTenantID(ctx); ok {
+		return errors.Forbidden("", "Tenants can't be managed within a tenant")
+	}
+	return nil
+}
+
+// Query tenants from the data access object based on the provided parameters and options.
+func (a *Tenant) Query(ctx context.Context, params model.TenantQueryParam) (*model.TenantQueryResult, error) {
+	if err := checkCrossTenant(ctx); err != nil {
+		return nil, err
+	}
+	params.Pagination = true
+
+	result, err := a.TenantRepo.Query(ctx, params, model.TenantQueryOptions{
+		QueryOptions: util.QueryOptions{
+			OrderFields: []util.OrderByParam{
+				{Field: "created_at", Direction: util.DESC},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Get the specified tenant from the data access object.
+func (a *Tenant) Get(ctx context.Context, id string) (*model.Tenant, error) {
+	if err := checkCrossTenant(ctx); err != nil {
+		return nil, err
+	}
+
+	tenant, err := a.TenantRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if tenant == nil {
+		return nil, errors.NotFound("", "Tenant not found")
+	}
+	return tenant, nil
+}
+
+// Create a new tenant in the data access object.
+func (a *Tenant) Create(ctx context.Context, formItem *model.TenantForm) (*model.Tenant, error) {
+	if err := checkCrossTenant(ctx); err != nil {
+		return nil, err
+	}
+
+	if exists, err := a.TenantRepo.ExistsCode(ctx, formItem.Code); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, errors.BadRequest("", "Tenant code already exists")
+	}
+
+	tenant := &model.Tenant{
+		ID:        util.NewXID(),
+		CreatedAt: time.Now(),
+	}
+	if err := formItem.FillTo(tenant); err != nil {
+		return nil, err
+	}
+
+	if err := a.TenantRepo.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// Update the specified tenant in the data access object.
+func (a *Tenant) Update(ctx context.Context, id string, formItem *model.TenantForm) error {
+	if err := checkCrossTenant(ctx); err != nil {
+		return err
+	}
+
+	tenant, err := a.TenantRepo.Get(ctx, id)
+	if err != nil {
+		return err
+	} else if tenant == nil {
+		return errors.NotFound("", "Tenant not found")
+	} else if tenant.Code != formItem.Code {
+		if exists, err := a.TenantRepo.ExistsCode(ctx, formItem.Code); err != nil {
+			return err
+		} else if exists {
+			return errors.BadRequest("", "Tenant code already exists")
+		}
+	}
+
+	oldStatus := tenant.Status
+	if err := formItem.FillTo(tenant); err != nil {
+		return err
+	}
+	tenant.UpdatedAt = time.Now()
+
+	if err := a.TenantRepo.Update(ctx, tenant); err != nil {
+		return err
+	}
+
+	// The users of a disabled tenant are logged out with the next request
+	if oldStatus != tenant.Status {
+		return a.clearUserCaches(ctx, id)
+	}
+	return nil
+}
+
+// Delete the specified tenant from the data access object.
+func (a *Tenant) Delete(ctx context.Context, id string) error {
+	if err := checkCrossTenant(ctx); err != nil {
+		return err
+	}
+
+	exists, err := a.TenantRepo.Exists(ctx, id)
+	if err != nil {
+		return err
+	} else if !exists {
+		return errors.NotFound("", "Tenant not found")
+	}
+
+	userResult, err := a.UserRepo.Query(util.NewTenantID(ctx, id), model.UserQueryParam{
+		PaginationParam: util.PaginationParam{OnlyCount: true},
+	})
+	if err != nil {
+		return err
+	} else if userResult.PageResult.Total > 0 {
+		return errors.BadRequest("", "Tenant has users and can't be deleted")
+	}
+
+	return a.TenantRepo.Delete(ctx, id)
+}
+
+func (a *Tenant) clearUserCaches(ctx context.Context, id string) error {
+	userResult, err := a.UserRepo.Query(util.NewTenantID(ctx, id), model.UserQueryParam{}, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, user := range userResult.Data {
+		if err := a.Cache.Delete(ctx, config.CacheNSForUser, user.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
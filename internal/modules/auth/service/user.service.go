@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"go-admin/internal/config"
@@ -15,10 +16,20 @@ import (
 
 // User management for RBAC
 type User struct {
-	Cache        cachex.Cacher
-	Trans        *util.Trans
-	UserRepo     *repo.User
-	UserRoleRepo *repo.UserRole
+	Cache               cachex.Cacher
+	Trans               *util.Trans
+	UserRepo            *repo.User
+	UserRoleRepo        *repo.UserRole
+	UserDepartmentRepo  *repo.UserDepartment
+	RoleRepo            *repo.Role
+	DepartmentRepo      *repo.Department
+	UserIdentityRepo    *repo.UserIdentity
+	SessionService      *Session
+	LockoutService      *Lockout
+	PasswordService     *Password
+	APIKeyService       *APIKey
+	PasskeyService      *Passkey
+	LoginHistoryService *LoginHistory
 }
 
 // Query users from the data access object based on the provided parameters and options.
@@ -47,8 +58,20 @@ func (a *User) Query(ctx context.Context, params model.UserQueryParam) (*model.U
 			return nil, err
 		}
 		userRolesMap := userRoleResult.Data.ToUserIDMap()
+
+		userDepartmentResult, err := a.UserDepartmentRepo.Query(ctx, model.UserDepartmentQueryParam{
+			InUserIDs: userIDs,
+		}, model.UserDepartmentQueryOptions{
+			JoinDepartment: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		userDepartmentsMap := userDepartmentResult.Data.ToUserIDMap()
+
 		for _, user := range result.Data {
 			user.Roles = userRolesMap[user.ID]
+			user.Departments = userDepartmentsMap[user.ID]
 		}
 	}
 
@@ -76,6 +99,16 @@ func (a *User) Get(ctx context.Context, id string) (*model.User, error) {
 	}
 	user.Roles = userRoleResult.Data
 
+	userDepartmentResult, err := a.UserDepartmentRepo.Query(ctx, model.UserDepartmentQueryParam{
+		UserID: id,
+	}, model.UserDepartmentQueryOptions{
+		JoinDepartment: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	user.Departments = userDepartmentResult.Data
+
 	return user, nil
 }
 
@@ -95,6 +128,14 @@ func (a *User) Create(ctx context.Context, formItem *model.UserForm) (*model.Use
 
 	if formItem.Password == "" {
 		formItem.Password = config.C.General.DefaultLoginPwd
+	} else if err := a.PasswordService.Check(ctx, "", formItem.Email, formItem.Password, ""); err != nil {
+		return nil, err
+	}
+
+	// the user is created in the tenant of the request, the default tenant if the request has none
+	tenantID, _ := util.FromTenantID(ctx)
+	if err := a.checkRolesAndDepartments(ctx, tenantID, formItem); err != nil {
+		return nil, err
 	}
 
 	if err := formItem.FillTo(user); err != nil {
@@ -114,12 +155,22 @@ func (a *User) Create(ctx context.Context, formItem *model.UserForm) (*model.Use
 				return err
 			}
 		}
+
+		for _, userDepartment := range formItem.Departments {
+			userDepartment.ID = util.NewXID()
+			userDepartment.UserID = user.ID
+			userDepartment.CreatedAt = time.Now()
+			if err := a.UserDepartmentRepo.Create(ctx, userDepartment); err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 	user.Roles = formItem.Roles
+	user.Departments = formItem.Departments
 
 	return user, nil
 }
@@ -140,15 +191,36 @@ func (a *User) Update(ctx context.Context, id string, formItem *model.UserForm)
 		}
 	}
 
+	// The form may carry the unchanged password, only a new password is checked and recorded
+	oldStatus, oldPassword := user.Status, user.Password
+	pwdChanged := formItem.Password != "" && hash.CompareHashAndPassword(oldPassword, formItem.Password) != nil
+	if pwdChanged {
+		if err := a.PasswordService.Check(ctx, id, formItem.Email, formItem.Password, oldPassword); err != nil {
+			return err
+		}
+	}
+
+	if err := a.checkRolesAndDepartments(ctx, user.TenantID, formItem); err != nil {
+		return err
+	}
+
 	if err := formItem.FillTo(user); err != nil {
 		return err
 	}
 	user.UpdatedAt = time.Now()
+	if pwdChanged {
+		user.PwdChangedAt = &user.UpdatedAt
+	}
 
-	return a.Trans.Exec(ctx, func(ctx context.Context) error {
+	err = a.Trans.Exec(ctx, func(ctx context.Context) error {
 		if err := a.UserRepo.Update(ctx, user); err != nil {
 			return err
 		}
+		if pwdChanged {
+			if err := a.PasswordService.Record(ctx, id, oldPassword); err != nil {
+				return err
+			}
+		}
 
 		if err := a.UserRoleRepo.DeleteByUserID(ctx, id); err != nil {
 			return err
@@ -167,8 +239,55 @@ func (a *User) Update(ctx context.Context, id string, formItem *model.UserForm)
 			}
 		}
 
+		if err := a.UserDepartmentRepo.DeleteByUserID(ctx, id); err != nil {
+			return err
+		}
+		for _, userDepartment := range formItem.Departments {
+			if userDepartment.ID == "" {
+				userDepartment.ID = util.NewXID()
+			}
+			userDepartment.UserID = user.ID
+			if userDepartment.CreatedAt.IsZero() {
+				userDepartment.CreatedAt = time.Now()
+			}
+			userDepartment.UpdatedAt = time.Now()
+			if err := a.UserDepartmentRepo.Create(ctx, userDepartment); err != nil {
+				return err
+			}
+		}
+
 		return a.Cache.Delete(ctx, config.CacheNSForUser, id)
 	})
+	if err != nil {
+		return err
+	}
+
+	// Deactivated users are logged out everywhere
+	if oldStatus == model.UserStatusActive && user.Status != model.UserStatusActive {
+		return a.SessionService.RevokeAll(ctx, id, "")
+	}
+	return nil
+}
+
+// Check the roles and departments of the form exist in the tenant of the user, the IDs of other tenants are rejected
+// as the data scopes of their roles would be merged into the user cache.
+func (a *User) checkRolesAndDepartments(ctx context.Context, tenantID string, formItem *model.UserForm) error {
+	ctx = util.NewTenantID(ctx, tenantID)
+	for _, userRole := range formItem.Roles {
+		if exists, err := a.RoleRepo.Exists(ctx, userRole.RoleID); err != nil {
+			return err
+		} else if !exists {
+			return errors.BadRequest("", "Role not found: %s", userRole.RoleID)
+		}
+	}
+	for _, userDepartment := range formItem.Departments {
+		if exists, err := a.DepartmentRepo.Exists(ctx, userDepartment.DepartmentID); err != nil {
+			return err
+		} else if !exists {
+			return errors.BadRequest("", "Department not found: %s", userDepartment.DepartmentID)
+		}
+	}
+	return nil
 }
 
 // Delete the specified user from the data access object.
@@ -180,15 +299,95 @@ func (a *User) Delete(ctx context.Context, id string) error {
 		return errors.NotFound("", "User not found")
 	}
 
-	return a.Trans.Exec(ctx, func(ctx context.Context) error {
+	err = a.Trans.Exec(ctx, func(ctx context.Context) error {
 		if err := a.UserRepo.Delete(ctx, id); err != nil {
 			return err
 		}
 		if err := a.UserRoleRepo.DeleteByUserID(ctx, id); err != nil {
 			return err
 		}
+		if err := a.UserDepartmentRepo.DeleteByUserID(ctx, id); err != nil {
+			return err
+		}
+		if err := a.PasswordService.Clear(ctx, id); err != nil {
+			return err
+		}
+		if err := a.UserIdentityRepo.DeleteByUserID(ctx, id); err != nil {
+			return err
+		}
+		if err := a.APIKeyService.Clear(ctx, id); err != nil {
+			return err
+		}
+		if err := a.PasskeyService.Clear(ctx, id); err != nil {
+			return err
+		}
 		return a.Cache.Delete(ctx, config.CacheNSForUser, id)
 	})
+	if err != nil {
+		return err
+	}
+	return a.SessionService.RevokeAll(ctx, id, "")
+}
+
+// Query the login sessions of the specified user.
+func (a *User) QuerySessions(ctx context.Context, id string) (model.Sessions, error) {
+	exists, err := a.UserRepo.Exists(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, errors.NotFound("", "User not found")
+	}
+	return a.SessionService.Query(ctx, id)
+}
+
+// Revoke a login session of the specified user, all sessions are revoked if `sessionID` is empty.
+func (a *User) RevokeSessions(ctx context.Context, id, sessionID string) error {
+	if sessionID != "" {
+		return a.SessionService.Revoke(ctx, id, sessionID)
+	}
+	return a.SessionService.RevokeAll(ctx, id, "")
+}
+
+// Query the API keys of the specified user.
+func (a *User) QueryAPIKeys(ctx context.Context, id string) (model.APIKeys, error) {
+	exists, err := a.UserRepo.Exists(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, errors.NotFound("", "User not found")
+	}
+	return a.APIKeyService.Query(ctx, id)
+}
+
+// Query the login history of the specified user.
+func (a *User) QueryLoginHistory(ctx context.Context, id string, params model.LoginHistoryQueryParam) (*model.LoginHistoryQueryResult, error) {
+	exists, err := a.UserRepo.Exists(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, errors.NotFound("", "User not found")
+	}
+	return a.LoginHistoryService.Query(ctx, id, params)
+}
+
+// Revoke an API key of the specified user.
+func (a *User) RevokeAPIKey(ctx context.Context, id, keyID string) error {
+	return a.APIKeyService.Revoke(ctx, id, keyID)
+}
+
+// Unlock the login of the specified user locked by too many failed logins.
+func (a *User) Unlock(ctx context.Context, id string) error {
+	user, err := a.UserRepo.Get(ctx, id, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"email"},
+		},
+	})
+	if err != nil {
+		return err
+	} else if user == nil {
+		return errors.NotFound("", "User not found")
+	}
+	return a.LockoutService.Unlock(ctx, user.Email)
 }
 
 func (a *User) ResetPassword(ctx context.Context, id string) error {
@@ -212,9 +411,12 @@ func (a *User) ResetPassword(ctx context.Context, id string) error {
 	})
 }
 
+// Get the IDs of the roles assigned to the user now, the assignments not started yet or ended are ignored.
 func (a *User) GetRoleIDs(ctx context.Context, id string) ([]string, error) {
+	now := time.Now()
 	userRoleResult, err := a.UserRoleRepo.Query(ctx, model.UserRoleQueryParam{
-		UserID: id,
+		UserID:  id,
+		ValidAt: &now,
 	}, model.UserRoleQueryOptions{
 		QueryOptions: util.QueryOptions{
 			SelectFields: []string{"role_id"},
@@ -225,3 +427,104 @@ func (a *User) GetRoleIDs(ctx context.Context, id string) ([]string, error) {
 	}
 	return userRoleResult.Data.ToRoleIDs(), nil
 }
+
+// Get the next time a role assignment of the user starts or ends, nil if none will.
+func (a *User) GetNextRoleBoundary(ctx context.Context, id string) (*time.Time, error) {
+	now := time.Now()
+	userRoleResult, err := a.UserRoleRepo.Query(ctx, model.UserRoleQueryParam{
+		UserID:       id,
+		GtBoundaryAt: &now,
+	}, model.UserRoleQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"valid_from", "valid_until"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var next *time.Time
+	for _, userRole := range userRoleResult.Data {
+		for _, boundary := range []*time.Time{userRole.ValidFrom, userRole.ValidUntil} {
+			if boundary != nil && boundary.After(now) && (next == nil || boundary.Before(*next)) {
+				next = boundary
+			}
+		}
+	}
+	return next, nil
+}
+
+// Build the cache of the user with the roles, the data scope is the union of the data scopes of its enabled roles
+// and of the enabled roles they inherit.
+func (a *User) NewUserCache(ctx context.Context, id string, roleIDs []string) (util.UserCache, error) {
+	// the user and its roles are in the tenant of the user, not necessarily the tenant of the request
+	ctx = util.NewCrossTenant(ctx)
+	user, err := a.UserRepo.Get(ctx, id, model.UserQueryOptions{
+		QueryOptions: util.QueryOptions{SelectFields: []string{"tenant_id"}},
+	})
+	if err != nil {
+		return util.UserCache{}, err
+	}
+
+	userCache := util.UserCache{RoleIDs: roleIDs}
+	if user != nil {
+		userCache.TenantID = user.TenantID
+	}
+	dataScope := &util.DataScope{UserIDs: []string{id}}
+	if len(roleIDs) == 0 {
+		userCache.DataScope = dataScope
+		return userCache, nil
+	}
+
+	expandedRoleIDs, err := expandRoleIDs(ctx, a.RoleRepo, roleIDs, true)
+	if err != nil {
+		return userCache, err
+	}
+	roleResult, err := a.RoleRepo.Query(ctx, model.RoleQueryParam{
+		InIDs:  expandedRoleIDs,
+		Status: model.RoleStatusEnabled,
+	}, model.RoleQueryOptions{
+		QueryOptions: util.QueryOptions{
+			SelectFields: []string{"id", "data_scope", "data_user_ids", "data_department_ids"},
+		},
+	})
+	if err != nil {
+		return userCache, err
+	}
+
+	addDepartmentIDs := func(departmentIDs ...string) {
+		for _, departmentID := range departmentIDs {
+			if !slices.Contains(dataScope.DepartmentIDs, departmentID) {
+				dataScope.DepartmentIDs = append(dataScope.DepartmentIDs, departmentID)
+			}
+		}
+	}
+
+	for _, role := range roleResult.Data {
+		switch role.DataScope {
+		case model.RoleDataScopeAll, "":
+			return userCache, nil
+		case model.RoleDataScopeCustom:
+			for _, userID := range role.DataUserIDs {
+				if !slices.Contains(dataScope.UserIDs, userID) {
+					dataScope.UserIDs = append(dataScope.UserIDs, userID)
+				}
+			}
+			addDepartmentIDs(role.DataDepartmentIDs...)
+		case model.RoleDataScopeDepartment:
+			userDepartmentResult, err := a.UserDepartmentRepo.Query(ctx, model.UserDepartmentQueryParam{
+				UserID: id,
+			}, model.UserDepartmentQueryOptions{
+				QueryOptions: util.QueryOptions{SelectFields: []string{"department_id"}},
+			})
+			if err != nil {
+				return userCache, err
+			}
+			for _, userDepartment := range userDepartmentResult.Data {
+				addDepartmentIDs(userDepartment.DepartmentID)
+			}
+		}
+	}
+	userCache.DataScope = dataScope
+	return userCache, nil
+}
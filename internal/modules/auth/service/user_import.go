@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/encoding/xlsx"
+	"go-admin/pkg/errors"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+const userExportPageSize = 500
+
+// Rows of an import or export file
+type userFileWriter interface {
+	Write(record []string) error
+	Close() error
+}
+
+type csvFileWriter struct {
+	*csv.Writer
+}
+
+func (w csvFileWriter) Close() error {
+	w.Flush()
+	return w.Error()
+}
+
+// Leading characters of the values spreadsheets evaluate as formulas
+const formulaPrefixes = "=+-@\t\r"
+
+// Prefix the exported values which would be evaluated as formulas with a quote, e.g. a user named `=HYPERLINK(...)`.
+// Signed numbers like phone numbers are left alone. The quote isn't removed on import, a value may start with one.
+func escapeFormula(v string) string {
+	if v == "" || strings.IndexByte(formulaPrefixes, v[0]) < 0 {
+		return v
+	}
+	if (v[0] == '-' || v[0] == '+') && isNumber(v[1:]) {
+		return v
+	}
+	return "'" + v
+}
+
+func isNumber(v string) bool {
+	if v == "" || v[0] < '0' || v[0] > '9' {
+		return false
+	}
+	return strings.Trim(v, "0123456789.") == "" && strings.Count(v, ".") <= 1
+}
+
+// Read the rows of the file, the rows are numbered as in the file and the empty lines are nil.
+func readUserFile(format string, file io.ReaderAt, size int64) ([][]string, error) {
+	if format == model.UserFileFormatXLSX {
+		return xlsx.ReadAll(file, size)
+	}
+
+	r := csv.NewReader(io.NewSectionReader(file, 0, size))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	var rows [][]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		for line > len(rows)+1 {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, record)
+	}
+
+	// the files saved by Excel start with the UTF-8 BOM
+	for _, row := range rows {
+		if len(row) > 0 {
+			row[0] = strings.TrimPrefix(row[0], "\ufeff")
+			break
+		}
+	}
+	return rows, nil
+}
+
+func isBlankRow(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// The client errors are reported by row, the others abort the import.
+func asRowError(err error) (*errors.Error, bool) {
+	e, ok := errors.As(err)
+	return e, ok && e.Code < http.StatusInternalServerError
+}
+
+// Import users from a CSV or XLSX file. All rows are validated first, the users are created in one
+// transaction only if every row is valid and it's not a dry run. The users get the default login password.
+func (a *User) Import(ctx context.Context, format string, file io.ReaderAt, size int64, dryRun bool) (*model.UserImportResult, error) {
+	if format != model.UserFileFormatCSV && format != model.UserFileFormatXLSX {
+		return nil, errors.BadRequest("", "Unsupported file format, expected csv or xlsx")
+	}
+
+	rows, err := readUserFile(format, file, size)
+	if err != nil {
+		return nil, errors.BadRequest("", "Invalid %s file: %s", format, err.Error())
+	}
+
+	header := -1
+	for i, record := range rows {
+		if !isBlankRow(record) {
+			header = i
+			break
+		}
+	}
+	if header < 0 {
+		return nil, errors.BadRequest("", "The file is empty")
+	}
+	columns := make(map[string]int)
+	for i, name := range rows[header] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"email", "first_name", "last_name"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.BadRequest("", "Missing column %s, the header is %s", name, strings.Join(model.UserFileColumns, ","))
+		}
+	}
+
+	type importRow struct {
+		row  int
+		form *model.UserForm
+	}
+	var items []importRow
+	for i := header + 1; i < len(rows); i++ {
+		if !isBlankRow(rows[i]) {
+			items = append(items, importRow{row: i + 1})
+		}
+	}
+	if maxRows := config.C.General.UserImport.MaxRows; maxRows > 0 && len(items) > maxRows {
+		return nil, errors.BadRequest("", "Too many users in the file, the limit is %d", maxRows)
+	}
+
+	roleIDs, err := a.getRoleIDsByCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.UserImportResult{DryRun: dryRun, Total: len(items)}
+	emailRows := make(map[string]int)
+	for i := range items {
+		item := &items[i]
+		record := rows[item.row-1]
+		get := func(name string) string {
+			if idx, ok := columns[name]; ok && idx < len(record) {
+				return strings.TrimSpace(record[idx])
+			}
+			return ""
+		}
+
+		form, err := newImportUserForm(get, roleIDs)
+		if err == nil {
+			err = a.checkImportEmail(ctx, form.Email, emailRows)
+		}
+		if err != nil {
+			e, ok := asRowError(err)
+			if !ok {
+				return nil, err
+			}
+			result.Errors = append(result.Errors, &model.UserImportError{
+				Row:     item.row,
+				Email:   get("email"),
+				Message: e.Detail,
+			})
+			continue
+		}
+		emailRows[strings.ToLower(form.Email)] = item.row
+		item.form = form
+	}
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	err = a.Trans.Exec(ctx, func(ctx context.Context) error {
+		for _, item := range items {
+			if _, err := a.Create(ctx, item.form); err != nil {
+				if e, ok := asRowError(err); ok {
+					return errors.BadRequest("", "Row %d: %s", item.row, e.Detail)
+				}
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Created = len(items)
+	logging.Context(ctx).Info("Users imported", zap.Int("count", result.Created))
+	return result, nil
+}
+
+// Role IDs by role code of the tenant
+func (a *User) getRoleIDsByCode(ctx context.Context) (map[string]string, error) {
+	roleResult, err := a.RoleRepo.Query(ctx, model.RoleQueryParam{}, model.RoleQueryOptions{
+		QueryOptions: util.QueryOptions{SelectFields: []string{"id", "code"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := make(map[string]string, len(roleResult.Data))
+	for _, role := range roleResult.Data {
+		roleIDs[role.Code] = role.ID
+	}
+	return roleIDs, nil
+}
+
+// Validate the values of the row with the rules of the user form.
+func newImportUserForm(get func(name string) string, roleIDs map[string]string) (*model.UserForm, error) {
+	form := &model.UserForm{
+		Email:     get("email"),
+		FirstName: get("first_name"),
+		LastName:  get("last_name"),
+		Phone:     get("phone"),
+		Status:    get("status"),
+	}
+	if form.Status == "" {
+		form.Status = model.UserStatusActive
+	}
+
+	switch {
+	case form.Email == "":
+		return nil, errors.BadRequest("", "Email is required")
+	case len(form.Email) > 128:
+		return nil, errors.BadRequest("", "Email is too long")
+	case form.FirstName == "" || form.LastName == "":
+		return nil, errors.BadRequest("", "First name and last name are required")
+	case len(form.FirstName) > 64 || len(form.LastName) > 64:
+		return nil, errors.BadRequest("", "Name is too long")
+	case len(form.Phone) > 32:
+		return nil, errors.BadRequest("", "Phone is too long")
+	case form.Status != model.UserStatusActive && form.Status != model.UserStatusInactive:
+		return nil, errors.BadRequest("", "Invalid status %s, expected active or inactive", form.Status)
+	}
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	var seen []string
+	for _, code := range strings.FieldsFunc(get("roles"), func(r rune) bool { return r == ';' || r == ',' }) {
+		code = strings.TrimSpace(code)
+		if code == "" || slices.Contains(seen, code) {
+			continue
+		}
+		roleID, ok := roleIDs[code]
+		if !ok {
+			return nil, errors.BadRequest("", "Role not found: %s", code)
+		}
+		seen = append(seen, code)
+		form.Roles = append(form.Roles, &model.UserRole{RoleID: roleID})
+	}
+	return form, nil
+}
+
+func (a *User) checkImportEmail(ctx context.Context, email string, emailRows map[string]int) error {
+	if row, ok := emailRows[strings.ToLower(email)]; ok {
+		return errors.BadRequest("", "Duplicate email of row %d", row)
+	}
+
+	exists, err := a.UserRepo.ExistsEmail(ctx, email)
+	if err != nil {
+		return err
+	} else if exists {
+		return errors.BadRequest("", "Email already exists")
+	}
+	return nil
+}
+
+// Export the users matching the query parameters to a CSV or XLSX file. The users are queried and
+// written page by page, nothing is written if the first query fails.
+func (a *User) Export(ctx context.Context, params model.UserExportParam, w io.Writer) error {
+	query := params.UserQueryParam
+	query.PageSize = userExportPageSize
+
+	var fw userFileWriter
+	for query.Current = 1; ; query.Current++ {
+		result, err := a.Query(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		if fw == nil {
+			if params.Format == model.UserFileFormatXLSX {
+				xw := xlsx.NewWriter(w)
+				xw.SheetName = "Users"
+				fw = xw
+			} else {
+				// Excel detects UTF-8 by the BOM
+				if _, err := io.WriteString(w, "\ufeff"); err != nil {
+					return err
+				}
+				fw = csvFileWriter{csv.NewWriter(w)}
+			}
+			if err := fw.Write(append(slices.Clone(model.UserFileColumns), "created_at")); err != nil {
+				return err
+			}
+		}
+
+		for _, user := range result.Data {
+			var roleCodes []string
+			for _, userRole := range user.Roles {
+				if userRole.RoleCode != "" {
+					roleCodes = append(roleCodes, userRole.RoleCode)
+				}
+			}
+			record := []string{
+				user.Email,
+				user.FirstName,
+				user.LastName,
+				user.Phone,
+				user.Status,
+				strings.Join(roleCodes, ";"),
+				user.CreatedAt.Format(time.RFC3339),
+			}
+			for i, v := range record {
+				record[i] = escapeFormula(v)
+			}
+			if err := fw.Write(record); err != nil {
+				return err
+			}
+		}
+		if len(result.Data) < query.PageSize {
+			break
+		}
+	}
+	return fw.Close()
+}
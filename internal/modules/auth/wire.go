@@ -12,6 +12,7 @@ import (
 var Set = wire.NewSet(
 	wire.Struct(new(Auth), "*"),
 	wire.Struct(new(Casbinx), "*"),
+	wire.Struct(new(RoleSweeper), "*"),
 	wire.Struct(new(repo.Menu), "*"),
 	wire.Struct(new(service.Menu), "*"),
 	wire.Struct(new(api.Menu), "*"),
@@ -24,6 +25,34 @@ var Set = wire.NewSet(
 	wire.Struct(new(service.User), "*"),
 	wire.Struct(new(api.User), "*"),
 	wire.Struct(new(repo.UserRole), "*"),
+	wire.Struct(new(repo.Permission), "*"),
+	wire.Struct(new(service.Permission), "*"),
+	wire.Struct(new(api.Permission), "*"),
+	wire.Struct(new(repo.RolePermission), "*"),
+	wire.Struct(new(service.Session), "*"),
+	wire.Struct(new(service.Lockout), "*"),
+	wire.Struct(new(repo.PasswordHistory), "*"),
+	wire.Struct(new(service.Password), "*"),
+	wire.Struct(new(service.LDAP), "*"),
 	wire.Struct(new(service.Auth), "*"),
 	wire.Struct(new(api.Auth), "*"),
+	wire.Struct(new(repo.UserIdentity), "*"),
+	wire.Struct(new(service.OIDC), "*"),
+	wire.Struct(new(api.OIDC), "*"),
+	wire.Struct(new(repo.APIKey), "*"),
+	wire.Struct(new(service.APIKey), "*"),
+	wire.Struct(new(repo.Passkey), "*"),
+	wire.Struct(new(service.Passkey), "*"),
+	wire.Struct(new(repo.LoginHistory), "*"),
+	wire.Struct(new(service.LoginHistory), "*"),
+	wire.Struct(new(repo.Department), "*"),
+	wire.Struct(new(service.Department), "*"),
+	wire.Struct(new(api.Department), "*"),
+	wire.Struct(new(repo.UserDepartment), "*"),
+	wire.Struct(new(repo.Tenant), "*"),
+	wire.Struct(new(service.Tenant), "*"),
+	wire.Struct(new(api.Tenant), "*"),
+	wire.Struct(new(repo.Invitation), "*"),
+	wire.Struct(new(service.Invitation), "*"),
+	wire.Struct(new(api.Invitation), "*"),
 )
@@ -11,7 +11,8 @@ import (
 )
 
 const (
-	apiPrefix = "/api/"
+	apiPrefix       = "/api/"
+	wellKnownPrefix = "/.well-known/"
 )
 
 // Collection of wire providers
@@ -54,6 +55,11 @@ func (a *Modules) RegisterRouters(ctx context.Context, e *gin.Engine) error {
 		return err
 	}
 
+	wellKnown := e.Group(wellKnownPrefix)
+	if err := a.Auth.RegisterWellKnownRouters(ctx, wellKnown); err != nil {
+		return err
+	}
+
 	return nil
 }
 
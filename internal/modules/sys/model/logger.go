@@ -10,6 +10,7 @@ import (
 // Logger management
 type Logger struct {
 	ID         string    `gorm:"size:20;primaryKey;" json:"id"`            // Unique ID
+	TenantID   string    `gorm:"size:20;index;" json:"tenant_id"`          // Tenant ID
 	Level      string    `gorm:"size:20;index;" json:"level"`              // Log level
 	TraceID    string    `gorm:"size:64;index;" json:"trace_id"`           // Trace ID
 	UserID     string    `gorm:"size:20;index;" json:"user_id"`            // User ID
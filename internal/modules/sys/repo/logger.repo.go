@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	authModel "go-admin/internal/modules/auth/model"
+	authRepo "go-admin/internal/modules/auth/repo"
 	schema "go-admin/internal/modules/sys/model"
 	"go-admin/pkg/errors"
 	"go-admin/pkg/util"
@@ -29,9 +30,10 @@ func (a *Logger) Query(ctx context.Context, params schema.LoggerQueryParam, opts
 		opt = opts[0]
 	}
 
-	db := a.DB.Table(fmt.Sprintf("%s AS a", new(schema.Logger).TableName()))
+	db := util.GetDB(ctx, a.DB).Table(fmt.Sprintf("%s AS a", new(schema.Logger).TableName()))
 	db = db.Joins(fmt.Sprintf("left join %s b on a.user_id=b.id", new(authModel.User).TableName()))
 	db = db.Select("a.*,b.full_name as user_name,b.email as login_email")
+	db = db.Scopes(util.DataScopeFunc(ctx, "a.user_id", authRepo.DataScopeDepartmentUsers(ctx, a.DB)))
 
 	if v := params.Level; v != "" {
 		db = db.Where("a.level = ?", v)
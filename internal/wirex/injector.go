@@ -2,6 +2,8 @@ package wirex
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"time"
 
 	"go-admin/internal/config"
@@ -9,6 +11,7 @@ import (
 	"go-admin/pkg/cachex"
 	"go-admin/pkg/gormx"
 	"go-admin/pkg/jwtx"
+	"go-admin/pkg/util"
 
 	"github.com/golang-jwt/jwt"
 	"gorm.io/gorm"
@@ -50,6 +53,11 @@ func InitDB(ctx context.Context) (*gorm.DB, func(), error) {
 		return nil, nil, err
 	}
 
+	// Isolate the rows of the tenants for the statements built by util.GetDB
+	if err := db.Use(util.TenantPlugin{}); err != nil {
+		return nil, nil, err
+	}
+
 	return db, func() {
 		sqlDB, err := db.DB()
 		if err == nil {
@@ -70,15 +78,15 @@ func InitCacher(ctx context.Context) (cachex.Cacher, func(), error) {
 			DB:       cfg.Redis.DB,
 			Username: cfg.Redis.Username,
 			Password: cfg.Redis.Password,
-		}, cachex.WithDelimiter(cfg.Delimiter))
+		}, cachex.WithDelimiter(cfg.Delimiter), cachex.WithKeyPrefix(cfg.KeyPrefix))
 	case "badger":
 		cache = cachex.NewBadgerCache(cachex.BadgerConfig{
 			Path: cfg.Badger.Path,
-		}, cachex.WithDelimiter(cfg.Delimiter))
+		}, cachex.WithDelimiter(cfg.Delimiter), cachex.WithKeyPrefix(cfg.KeyPrefix))
 	default:
 		cache = cachex.NewMemoryCache(cachex.MemoryConfig{
 			CleanupInterval: time.Second * time.Duration(cfg.Memory.CleanupInterval),
-		}, cachex.WithDelimiter(cfg.Delimiter))
+		}, cachex.WithDelimiter(cfg.Delimiter), cachex.WithKeyPrefix(cfg.KeyPrefix))
 	}
 
 	return cache, func() {
@@ -90,7 +98,38 @@ func InitAuth(ctx context.Context) (jwtx.Auther, func(), error) {
 	cfg := config.C.Middleware.Auth
 	var opts []jwtx.Option
 	opts = append(opts, jwtx.SetExpired(cfg.Expired))
-	opts = append(opts, jwtx.SetSigningKey(cfg.SigningKey, cfg.OldSigningKey))
+	opts = append(opts, jwtx.SetRefreshExpired(cfg.RefreshExpired))
+	if len(cfg.Keys) > 0 {
+		var keys []*jwtx.Key
+		for _, item := range cfg.Keys {
+			method := item.Method
+			if method == "" {
+				method = cfg.SigningMethod
+			}
+
+			var privatePEM, publicPEM []byte
+			var err error
+			if item.PrivateKeyFile != "" {
+				privatePEM, err = os.ReadFile(filepath.Join(config.C.General.WorkDir, item.PrivateKeyFile))
+			} else if item.PublicKeyFile != "" {
+				publicPEM, err = os.ReadFile(filepath.Join(config.C.General.WorkDir, item.PublicKeyFile))
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+
+			key, err := jwtx.ParseKey(item.ID, method, privatePEM, publicPEM)
+			if err != nil {
+				return nil, nil, err
+			}
+			keys = append(keys, key)
+		}
+		opts = append(opts, jwtx.SetKeys(keys...))
+		// The secret key only verifies tokens issued before the migration to asymmetric keys
+		opts = append(opts, jwtx.SetSigningKey(cfg.OldSigningKey, ""))
+	} else {
+		opts = append(opts, jwtx.SetSigningKey(cfg.SigningKey, cfg.OldSigningKey))
+	}
 
 	var method jwt.SigningMethod
 	switch cfg.SigningMethod {
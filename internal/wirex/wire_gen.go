@@ -1,6 +1,6 @@
 // Code generated by Wire. DO NOT EDIT.
 
-//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:generate go run github.com/google/wire/cmd/wire
 //go:build !wireinject
 // +build !wireinject
 
@@ -63,15 +63,19 @@ func BuildInjector(ctx context.Context) (*Injector, func(), error) {
 	role := &repo.Role{
 		DB: db,
 	}
+	rolePermission := &repo.RolePermission{
+		DB: db,
+	}
 	userRole := &repo.UserRole{
 		DB: db,
 	}
 	serviceRole := &service.Role{
-		Cache:        cacher,
-		Trans:        trans,
-		RoleRepo:     role,
-		RoleMenuRepo: roleMenu,
-		UserRoleRepo: userRole,
+		Cache:              cacher,
+		Trans:              trans,
+		RoleRepo:           role,
+		RoleMenuRepo:       roleMenu,
+		RolePermissionRepo: rolePermission,
+		UserRoleRepo:       userRole,
 	}
 	apiRole := &api.Role{
 		RoleService: serviceRole,
@@ -79,39 +83,178 @@ func BuildInjector(ctx context.Context) (*Injector, func(), error) {
 	user := &repo.User{
 		DB: db,
 	}
-	serviceUser := &service.User{
-		Cache:        cacher,
-		Trans:        trans,
-		UserRepo:     user,
+	userDepartment := &repo.UserDepartment{
+		DB: db,
+	}
+	department := &repo.Department{
+		DB: db,
+	}
+	userIdentity := &repo.UserIdentity{
+		DB: db,
+	}
+	session := &service.Session{
+		Cache: cacher,
+		Auth:  auther,
+	}
+	lockout := &service.Lockout{
+		Cache: cacher,
+	}
+	passwordHistory := &repo.PasswordHistory{
+		DB: db,
+	}
+	password := &service.Password{
+		PasswordHistoryRepo: passwordHistory,
+	}
+	apiKey := &repo.APIKey{
+		DB: db,
+	}
+	serviceAPIKey := &service.APIKey{
+		APIKeyRepo:   apiKey,
 		UserRoleRepo: userRole,
 	}
+	passkey := &repo.Passkey{
+		DB: db,
+	}
+	servicePasskey := &service.Passkey{
+		Cache:       cacher,
+		UserRepo:    user,
+		PasskeyRepo: passkey,
+	}
+	loginHistory := &repo.LoginHistory{
+		DB: db,
+	}
+	serviceLoginHistory := &service.LoginHistory{
+		LoginHistoryRepo: loginHistory,
+		UserRepo:         user,
+	}
+	serviceUser := &service.User{
+		Cache:               cacher,
+		Trans:               trans,
+		UserRepo:            user,
+		UserRoleRepo:        userRole,
+		UserDepartmentRepo:  userDepartment,
+		RoleRepo:            role,
+		DepartmentRepo:      department,
+		UserIdentityRepo:    userIdentity,
+		SessionService:      session,
+		LockoutService:      lockout,
+		PasswordService:     password,
+		APIKeyService:       serviceAPIKey,
+		PasskeyService:      servicePasskey,
+		LoginHistoryService: serviceLoginHistory,
+	}
 	apiUser := &api.User{
 		UserService: serviceUser,
 	}
-	serviceAuth := &service.Auth{
-		Cache:        cacher,
-		Auth:         auther,
+	ldap := &service.LDAP{
+		Trans:        trans,
 		UserRepo:     user,
 		UserRoleRepo: userRole,
-		MenuRepo:     menu,
-		UserService:  serviceUser,
+	}
+	invitation := &repo.Invitation{
+		DB: db,
+	}
+	serviceInvitation := &service.Invitation{
+		Trans:          trans,
+		InvitationRepo: invitation,
+		UserRepo:       user,
+		RoleRepo:       role,
+		UserService:    serviceUser,
+	}
+	tenant := &repo.Tenant{
+		DB: db,
+	}
+	serviceAuth := &service.Auth{
+		Cache:               cacher,
+		Auth:                auther,
+		UserRepo:            user,
+		UserRoleRepo:        userRole,
+		RoleRepo:            role,
+		MenuRepo:            menu,
+		UserService:         serviceUser,
+		SessionService:      session,
+		LockoutService:      lockout,
+		PasswordService:     password,
+		LDAPService:         ldap,
+		APIKeyService:       serviceAPIKey,
+		PasskeyService:      servicePasskey,
+		LoginHistoryService: serviceLoginHistory,
+		InvitationService:   serviceInvitation,
+		TenantRepo:          tenant,
+		Trans:               trans,
 	}
 	apiAuth := &api.Auth{
 		AuthService: serviceAuth,
 	}
-	casbinx := &auth.Casbinx{
+	permission := &repo.Permission{
+		DB: db,
+	}
+	servicePermission := &service.Permission{
+		Cache:              cacher,
+		Trans:              trans,
+		PermissionRepo:     permission,
+		RolePermissionRepo: rolePermission,
+	}
+	apiPermission := &api.Permission{
+		PermissionService: servicePermission,
+	}
+	oidc := &service.OIDC{
 		Cache:            cacher,
-		MenuRepo:         menu,
-		MenuResourceRepo: menuResource,
+		Trans:            trans,
+		UserRepo:         user,
+		UserRoleRepo:     userRole,
 		RoleRepo:         role,
+		UserIdentityRepo: userIdentity,
+		AuthService:      serviceAuth,
+	}
+	apiOIDC := &api.OIDC{
+		OIDCService: oidc,
+	}
+	serviceDepartment := &service.Department{
+		Trans:              trans,
+		DepartmentRepo:     department,
+		UserDepartmentRepo: userDepartment,
+	}
+	apiDepartment := &api.Department{
+		DepartmentService: serviceDepartment,
+	}
+	serviceTenant := &service.Tenant{
+		Cache:      cacher,
+		TenantRepo: tenant,
+		UserRepo:   user,
+	}
+	apiTenant := &api.Tenant{
+		TenantService: serviceTenant,
+	}
+	apiInvitation := &api.Invitation{
+		InvitationService: serviceInvitation,
+	}
+	casbinx := &auth.Casbinx{
+		Cache:              cacher,
+		MenuRepo:           menu,
+		MenuResourceRepo:   menuResource,
+		RoleRepo:           role,
+		PermissionRepo:     permission,
+		RolePermissionRepo: rolePermission,
+	}
+	roleSweeper := &auth.RoleSweeper{
+		Cache:        cacher,
+		UserRoleRepo: userRole,
 	}
 	authAuth := &auth.Auth{
-		DB:      db,
-		MenuAPI: apiMenu,
-		RoleAPI: apiRole,
-		UserAPI: apiUser,
-		AuthAPI: apiAuth,
-		Casbinx: casbinx,
+		DB:              db,
+		MenuAPI:         apiMenu,
+		RoleAPI:         apiRole,
+		UserAPI:         apiUser,
+		AuthAPI:         apiAuth,
+		PermissionAPI:   apiPermission,
+		OIDCAPI:         apiOIDC,
+		DepartmentAPI:   apiDepartment,
+		TenantAPI:       apiTenant,
+		InvitationAPI:   apiInvitation,
+		Casbinx:         casbinx,
+		RoleSweeper:     roleSweeper,
+		PasswordService: password,
 	}
 	logger := &repo2.Logger{
 		DB: db,
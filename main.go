@@ -27,6 +27,7 @@ func main() {
 	app.Commands = []*cli.Command{
 		cmd.StartCmd(),
 		cmd.StopCmd(),
+		cmd.HashPasswordCmd(),
 		cmd.VersionCmd(VERSION),
 	}
 	err := app.Run(os.Args)
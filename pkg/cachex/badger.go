@@ -3,6 +3,7 @@ package cachex
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 	"unsafe"
@@ -43,7 +44,7 @@ type badgerCache struct {
 }
 
 func (a *badgerCache) getKey(ns, key string) string {
-	return fmt.Sprintf("%s%s%s", ns, a.opts.Delimiter, key)
+	return fmt.Sprintf("%s%s%s%s", a.opts.KeyPrefix, ns, a.opts.Delimiter, key)
 }
 
 func (a *badgerCache) strToBytes(s string) []byte {
@@ -121,21 +122,66 @@ func (a *badgerCache) Delete(ctx context.Context, ns, key string) error {
 }
 
 func (a *badgerCache) GetAndDelete(ctx context.Context, ns, key string) (string, bool, error) {
-	value, ok, err := a.Get(ctx, ns, key)
-	if err != nil {
-		return "", false, err
-	} else if !ok {
-		return "", false, nil
-	}
-
-	err = a.db.Update(func(txn *badger.Txn) error {
-		return txn.Delete(a.strToBytes(a.getKey(ns, key)))
+	value := ""
+	ok := false
+	err := a.db.Update(func(txn *badger.Txn) error {
+		k := a.strToBytes(a.getKey(ns, key))
+		item, err := txn.Get(k)
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			return err
+		}
+		val, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		value, ok = a.bytesToStr(val), true
+		return txn.Delete(k)
 	})
-	if err != nil {
+	if err == badger.ErrConflict {
+		// a concurrent transaction has taken or replaced the value
+		return "", false, nil
+	} else if err != nil {
 		return "", false, err
 	}
+	return value, ok, nil
+}
+
+func (a *badgerCache) Incr(ctx context.Context, ns, key string, expiration time.Duration) (int64, error) {
+	for {
+		var n int64
+		err := a.db.Update(func(txn *badger.Txn) error {
+			k := a.strToBytes(a.getKey(ns, key))
+			item, err := txn.Get(k)
+			if err != nil && err != badger.ErrKeyNotFound {
+				return err
+			} else if err == nil {
+				val, err := item.ValueCopy(nil)
+				if err != nil {
+					return err
+				}
+				if n, err = strconv.ParseInt(a.bytesToStr(val), 10, 64); err != nil {
+					return err
+				}
+			}
+			n++
 
-	return value, true, nil
+			entry := badger.NewEntry(k, []byte(strconv.FormatInt(n, 10)))
+			if expiration > 0 {
+				entry = entry.WithTTL(expiration)
+			}
+			return txn.SetEntry(entry)
+		})
+		if err == badger.ErrConflict {
+			// a concurrent transaction has incremented the value, retry with the new one
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
 }
 
 func (a *badgerCache) Iterator(ctx context.Context, ns string, fn func(ctx context.Context, key, value string) bool) error {
@@ -3,7 +3,9 @@ package cachex
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/patrickmn/go-cache"
@@ -13,17 +15,30 @@ import (
 type Cacher interface {
 	Set(ctx context.Context, ns, key, value string, expiration ...time.Duration) error
 	Get(ctx context.Context, ns, key string) (string, bool, error)
+	// GetAndDelete is atomic, only one of the concurrent callers gets the value.
 	GetAndDelete(ctx context.Context, ns, key string) (string, bool, error)
+	// Incr is atomic, it increments the integer value of the key (0 if missing) and resets its expiration.
+	Incr(ctx context.Context, ns, key string, expiration time.Duration) (int64, error)
 	Exists(ctx context.Context, ns, key string) (bool, error)
 	Delete(ctx context.Context, ns, key string) error
 	Iterator(ctx context.Context, ns string, fn func(ctx context.Context, key, value string) bool) error
 	Close(ctx context.Context) error
 }
 
+// Notifier is the interface implemented by caches that can push messages to every instance sharing the cache.
+type Notifier interface {
+	Publish(ctx context.Context, ns, message string) error
+	// Subscribe delivers the messages published to ns to fn in a background goroutine until ctx is done.
+	// Messages published while the connection is lost are dropped, so onResubscribe (optional) is called
+	// every time the subscription is re-established to let the subscriber resync its state.
+	Subscribe(ctx context.Context, ns string, fn func(ctx context.Context, message string), onResubscribe func(ctx context.Context)) error
+}
+
 var defaultDelimiter = ":"
 
 type options struct {
 	Delimiter string
+	KeyPrefix string
 }
 
 type Option func(*options)
@@ -34,6 +49,13 @@ func WithDelimiter(delimiter string) Option {
 	}
 }
 
+// WithKeyPrefix prepends the prefix to every key and pub/sub channel, so apps sharing a store don't collide.
+func WithKeyPrefix(prefix string) Option {
+	return func(o *options) {
+		o.KeyPrefix = prefix
+	}
+}
+
 type MemoryConfig struct {
 	CleanupInterval time.Duration
 }
@@ -56,10 +78,11 @@ func NewMemoryCache(cfg MemoryConfig, opts ...Option) Cacher {
 type memCache struct {
 	opts  *options
 	cache *cache.Cache
+	mu    sync.Mutex // serializes GetAndDelete and Incr, so a value is only taken or incremented once
 }
 
 func (a *memCache) getKey(ns, key string) string {
-	return fmt.Sprintf("%s%s%s", ns, a.opts.Delimiter, key)
+	return fmt.Sprintf("%s%s%s%s", a.opts.KeyPrefix, ns, a.opts.Delimiter, key)
 }
 
 func (a *memCache) Set(ctx context.Context, ns, key, value string, expiration ...time.Duration) error {
@@ -91,6 +114,9 @@ func (a *memCache) Delete(ctx context.Context, ns, key string) error {
 }
 
 func (a *memCache) GetAndDelete(ctx context.Context, ns, key string) (string, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	value, ok, err := a.Get(ctx, ns, key)
 	if err != nil {
 		return "", false, err
@@ -102,6 +128,24 @@ func (a *memCache) GetAndDelete(ctx context.Context, ns, key string) (string, bo
 	return value, true, nil
 }
 
+func (a *memCache) Incr(ctx context.Context, ns, key string, expiration time.Duration) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var n int64
+	if val, ok := a.cache.Get(a.getKey(ns, key)); ok {
+		v, err := strconv.ParseInt(val.(string), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		n = v
+	}
+	n++
+
+	a.cache.Set(a.getKey(ns, key), strconv.FormatInt(n, 10), expiration)
+	return n, nil
+}
+
 func (a *memCache) Iterator(ctx context.Context, ns string, fn func(ctx context.Context, key, value string) bool) error {
 	for k, v := range a.cache.Items() {
 		if strings.HasPrefix(k, a.getKey(ns, "")) {
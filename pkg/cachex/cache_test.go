@@ -0,0 +1,92 @@
+package cachex
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAndDeleteOnce(t *testing.T) {
+	badgerCache := NewBadgerCache(BadgerConfig{
+		Path: "./tmp/badger-once",
+	})
+	defer badgerCache.Close(context.Background())
+
+	for name, cache := range map[string]Cacher{
+		"memory": NewMemoryCache(MemoryConfig{}),
+		"badger": badgerCache,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			ctx := context.Background()
+
+			for round := 0; round < 20; round++ {
+				err := cache.Set(ctx, "tt", "once", "bar")
+				assert.Nil(err)
+
+				var taken int32
+				var wg sync.WaitGroup
+				for i := 0; i < 8; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						val, ok, err := cache.GetAndDelete(ctx, "tt", "once")
+						assert.Nil(err)
+						if ok {
+							assert.Equal("bar", val)
+							atomic.AddInt32(&taken, 1)
+						}
+					}()
+				}
+				wg.Wait()
+				assert.Equal(int32(1), taken)
+			}
+		})
+	}
+}
+
+func TestIncr(t *testing.T) {
+	badgerCache := NewBadgerCache(BadgerConfig{
+		Path: "./tmp/badger-incr",
+	})
+	defer badgerCache.Close(context.Background())
+
+	for name, cache := range map[string]Cacher{
+		"memory": NewMemoryCache(MemoryConfig{}),
+		"badger": badgerCache,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			ctx := context.Background()
+
+			err := cache.Delete(ctx, "tt", "counter")
+			assert.Nil(err)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 10; j++ {
+						_, err := cache.Incr(ctx, "tt", "counter", time.Minute)
+						assert.Nil(err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			val, ok, err := cache.Get(ctx, "tt", "counter")
+			assert.Nil(err)
+			assert.True(ok)
+			assert.Equal("80", val)
+
+			n, err := cache.Incr(ctx, "tt", "counter", time.Minute)
+			assert.Nil(err)
+			assert.Equal(int64(81), n)
+		})
+	}
+}
@@ -56,19 +56,25 @@ func newRedisCache(cli redisClienter, opts ...Option) Cacher {
 type redisClienter interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
 	Get(ctx context.Context, key string) *redis.StringCmd
+	GetDel(ctx context.Context, key string) *redis.StringCmd
 	Exists(ctx context.Context, keys ...string) *redis.IntCmd
+	TxPipeline() redis.Pipeliner
 	Del(ctx context.Context, keys ...string) *redis.IntCmd
 	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+	Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
 	Close() error
 }
 
+var _ Notifier = (*redisCache)(nil)
+
 type redisCache struct {
 	opts *options
 	cli  redisClienter
 }
 
 func (a *redisCache) getKey(ns, key string) string {
-	return fmt.Sprintf("%s%s%s", ns, a.opts.Delimiter, key)
+	return fmt.Sprintf("%s%s%s%s", a.opts.KeyPrefix, ns, a.opts.Delimiter, key)
 }
 
 func (a *redisCache) Set(ctx context.Context, ns, key, value string, expiration ...time.Duration) error {
@@ -116,18 +122,30 @@ func (a *redisCache) Delete(ctx context.Context, ns, key string) error {
 }
 
 func (a *redisCache) GetAndDelete(ctx context.Context, ns, key string) (string, bool, error) {
-	value, ok, err := a.Get(ctx, ns, key)
-	if err != nil {
+	// GETDEL is atomic, it requires redis 6.2+
+	cmd := a.cli.GetDel(ctx, a.getKey(ns, key))
+	if err := cmd.Err(); err != nil {
+		if err == redis.Nil {
+			return "", false, nil
+		}
 		return "", false, err
-	} else if !ok {
-		return "", false, nil
 	}
+	return cmd.Val(), true, nil
+}
 
-	cmd := a.cli.Del(ctx, a.getKey(ns, key))
-	if err := cmd.Err(); err != nil && err != redis.Nil {
-		return "", false, err
+func (a *redisCache) Incr(ctx context.Context, ns, key string, expiration time.Duration) (int64, error) {
+	// INCR and EXPIRE run in one MULTI/EXEC, so the key is never left without the expiration
+	pipe := a.cli.TxPipeline()
+	incr := pipe.Incr(ctx, a.getKey(ns, key))
+	if expiration > 0 {
+		pipe.Expire(ctx, a.getKey(ns, key), expiration)
+	} else {
+		pipe.Persist(ctx, a.getKey(ns, key))
 	}
-	return value, true, nil
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
 }
 
 func (a *redisCache) Iterator(ctx context.Context, ns string, fn func(ctx context.Context, key, value string) bool) error {
@@ -167,6 +185,49 @@ LB_LOOP:
 	return nil
 }
 
+func (a *redisCache) getChannel(ns string) string {
+	return a.opts.KeyPrefix + ns
+}
+
+func (a *redisCache) Publish(ctx context.Context, ns, message string) error {
+	cmd := a.cli.Publish(ctx, a.getChannel(ns), message)
+	return cmd.Err()
+}
+
+func (a *redisCache) Subscribe(ctx context.Context, ns string, fn func(ctx context.Context, message string), onResubscribe func(ctx context.Context)) error {
+	pubsub := a.cli.Subscribe(ctx, a.getChannel(ns))
+	// Wait for the subscription to be confirmed, so no message published afterwards is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return err
+	}
+
+	go func() {
+		defer pubsub.Close()
+		// The client reconnects and resubscribes by itself, the confirmations tell when it happened
+		ch := pubsub.ChannelWithSubscriptions()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				switch msg := msg.(type) {
+				case *redis.Message:
+					fn(ctx, msg.Payload)
+				case *redis.Subscription:
+					if msg.Kind == "subscribe" && onResubscribe != nil {
+						onResubscribe(ctx)
+					}
+				}
+			}
+		}
+	}()
+	return nil
+}
+
 func (a *redisCache) Close(ctx context.Context) error {
 	return a.cli.Close()
 }
@@ -0,0 +1,188 @@
+package cachex
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A minimal in-process redis server that only speaks the pub/sub subset of RESP2,
+// so the notifier can be tested without a local redis instance.
+type redisStub struct {
+	ln   net.Listener
+	mu   sync.Mutex
+	subs map[string][]net.Conn
+}
+
+func newRedisStub(t *testing.T) *redisStub {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &redisStub{ln: ln, subs: make(map[string][]net.Conn)}
+	go s.serve()
+	t.Cleanup(func() { _ = ln.Close() })
+	return s
+}
+
+func (s *redisStub) Addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *redisStub) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *redisStub) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readRESPArray(r)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		switch strings.ToUpper(args[0]) {
+		case "PING":
+			_, _ = io.WriteString(conn, "+PONG\r\n")
+		case "SELECT":
+			_, _ = io.WriteString(conn, "+OK\r\n")
+		case "SUBSCRIBE":
+			for i, ch := range args[1:] {
+				s.subs[ch] = append(s.subs[ch], conn)
+				_, _ = io.WriteString(conn, fmt.Sprintf("*3\r\n%s%s:%d\r\n", respBulk("subscribe"), respBulk(ch), i+1))
+			}
+		case "PUBLISH":
+			for _, sub := range s.subs[args[1]] {
+				_, _ = io.WriteString(sub, fmt.Sprintf("*3\r\n%s%s%s", respBulk("message"), respBulk(args[1]), respBulk(args[2])))
+			}
+			_, _ = io.WriteString(conn, fmt.Sprintf(":%d\r\n", len(s.subs[args[1]])))
+		default:
+			_, _ = io.WriteString(conn, fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0]))
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Close the connections of the subscribers to simulate a network failure.
+func (s *redisStub) dropSubscribers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch, conns := range s.subs {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+		delete(s.subs, ch)
+	}
+}
+
+func respBulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func readRESPArray(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid array header: %q", line)
+	}
+
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk header: %q", line)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func TestRedisCacheNotifier(t *testing.T) {
+	assert := assert.New(t)
+	stub := newRedisStub(t)
+
+	publisher := NewRedisCache(RedisConfig{Addr: stub.Addr()}, WithKeyPrefix("app:"))
+	subscriber := NewRedisCache(RedisConfig{Addr: stub.Addr()}, WithKeyPrefix("app:"))
+	other := NewRedisCache(RedisConfig{Addr: stub.Addr()})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		_ = publisher.Close(ctx)
+		_ = subscriber.Close(ctx)
+		_ = other.Close(ctx)
+	}()
+
+	received := make(chan string, 10)
+	resubscribed := make(chan struct{}, 10)
+	err := subscriber.(Notifier).Subscribe(ctx, "tt", func(ctx context.Context, message string) {
+		received <- message
+	}, func(ctx context.Context) {
+		resubscribed <- struct{}{}
+	})
+	assert.Nil(err)
+
+	// The channel is scoped by the key prefix
+	err = other.(Notifier).Publish(ctx, "tt", "other")
+	assert.Nil(err)
+
+	for _, msg := range []string{"foo", "bar"} {
+		err = publisher.(Notifier).Publish(ctx, "tt", msg)
+		assert.Nil(err)
+	}
+
+	for _, want := range []string{"foo", "bar"} {
+		select {
+		case msg := <-received:
+			assert.Equal(want, msg)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Timed out waiting for message %q", want)
+		}
+	}
+
+	// The subscriber is told to resync after the client resubscribes
+	stub.dropSubscribers()
+	select {
+	case <-resubscribed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for the resubscription")
+	}
+	err = publisher.(Notifier).Publish(ctx, "tt", "baz")
+	assert.Nil(err)
+	select {
+	case msg := <-received:
+		assert.Equal("baz", msg)
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for message after resubscription")
+	}
+
+	_, ok := NewMemoryCache(MemoryConfig{}).(Notifier)
+	assert.False(ok)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -51,6 +52,12 @@ func TestRedisCache(t *testing.T) {
 	})
 	assert.Nil(err)
 
+	n, err := cache.Incr(ctx, "tt", "counter", time.Minute)
+	assert.Nil(err)
+	n2, err := cache.Incr(ctx, "tt", "counter", time.Minute)
+	assert.Nil(err)
+	assert.Equal(n+1, n2)
+
 	err = cache.Close(ctx)
 	assert.Nil(err)
 }
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
 	"encoding/base64"
+	"errors"
+	"io"
 )
 
 var (
@@ -53,9 +56,16 @@ func Decrypt(crypted, key []byte) ([]byte, error) {
 	}
 
 	blockSize := block.BlockSize()
+	if len(crypted) == 0 || len(crypted)%blockSize != 0 {
+		return nil, errors.New("aes: invalid ciphertext length")
+	}
 	blockMode := cipher.NewCBCDecrypter(block, key[:blockSize])
 	origData := make([]byte, len(crypted))
 	blockMode.CryptBlocks(origData, crypted)
+	// a wrong key usually leaves an invalid padding
+	if padding := int(origData[len(origData)-1]); padding == 0 || padding > blockSize {
+		return nil, errors.New("aes: invalid padding")
+	}
 	origData = PKCS5UnPadding(origData)
 	return origData, nil
 }
@@ -67,3 +77,55 @@ func DecryptFromBase64(data string, key []byte) ([]byte, error) {
 	}
 	return Decrypt(crypted, key)
 }
+
+// Encrypt with AES-GCM, a random nonce is generated per call and prepended to the sealed data.
+func EncryptGCM(origData, key []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(origData)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, origData, nil), nil
+}
+
+func EncryptGCMToBase64(origData, key []byte) (string, error) {
+	crypted, err := EncryptGCM(origData, key)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(crypted), nil
+}
+
+// Decrypt the data sealed by EncryptGCM, it fails if the key is wrong or the data was tampered with.
+func DecryptGCM(crypted, key []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(crypted) < aead.NonceSize() {
+		return nil, errors.New("aes: ciphertext too short")
+	}
+	nonce, sealed := crypted[:aead.NonceSize()], crypted[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, nil)
+}
+
+func DecryptGCMFromBase64(data string, key []byte) ([]byte, error) {
+	crypted, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptGCM(crypted, key)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
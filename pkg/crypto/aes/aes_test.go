@@ -21,3 +21,25 @@ func TestAESEncrypt(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal(data, result)
 }
+
+func TestAESEncryptGCM(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte("hello world")
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	bs64, err := EncryptGCMToBase64(data, key)
+	assert.Nil(err)
+	other, err := EncryptGCMToBase64(data, key)
+	assert.Nil(err)
+	assert.NotEqual(bs64, other)
+
+	result, err := DecryptGCMFromBase64(bs64, key)
+	assert.Nil(err)
+	assert.Equal(data, result)
+
+	_, err = DecryptGCMFromBase64(bs64, SecretKey)
+	assert.NotNil(err)
+	_, err = DecryptGCMFromBase64("c2hvcnQ", key)
+	assert.NotNil(err)
+}
@@ -3,9 +3,8 @@ package hash
 import (
 	"crypto/md5"
 	"crypto/sha1"
+	"crypto/sha256"
 	"fmt"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 // md5 hash
@@ -32,16 +31,14 @@ func SHA1String(s string) string {
 	return SHA1([]byte(s))
 }
 
-// Use bcrypt generate password hash
-func GeneratePassword(password string) (string, error) {
-	b, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(b), nil
+// sha256 hash
+func SHA256(b []byte) string {
+	h := sha256.New()
+	_, _ = h.Write(b)
+	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
-// Use bcrypt compare hash password and password
-func CompareHashAndPassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+// sha256 hash
+func SHA256String(s string) string {
+	return SHA256([]byte(s))
 }
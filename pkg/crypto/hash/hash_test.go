@@ -2,6 +2,8 @@ package hash
 
 import (
 	"testing"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestGeneratePassword(t *testing.T) {
@@ -24,3 +26,80 @@ func TestMD5(t *testing.T) {
 		t.Error("Failed to generate MD5 hash: ", v)
 	}
 }
+
+func TestPasswordAlgorithms(t *testing.T) {
+	origin := "abc-123"
+	for _, cfg := range []PasswordConfig{
+		{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost},
+		{Algorithm: AlgorithmArgon2id, Argon2Memory: 1024, Argon2Iterations: 1},
+		{Algorithm: AlgorithmScrypt, ScryptLogN: 10},
+	} {
+		hasher, err := NewPasswordHasher(cfg)
+		if err != nil {
+			t.Fatal("NewPasswordHasher Failed: ", err.Error())
+		}
+		hashPwd, err := hasher.Hash(origin)
+		if err != nil {
+			t.Fatal("Hash Failed: ", err.Error())
+		}
+		if v := PasswordAlgorithm(hashPwd); v != cfg.Algorithm {
+			t.Errorf("Unexpected algorithm %s of %s", v, hashPwd)
+		}
+		if _, err := VerifyPassword(hashPwd, origin); err != nil {
+			t.Error("Unmatched password: ", err.Error())
+		}
+		if _, err := VerifyPassword(hashPwd, "abc-124"); err != ErrPasswordMismatch {
+			t.Error("Matched wrong password: ", hashPwd)
+		}
+		if hasher.NeedsRehash(hashPwd) {
+			t.Error("Rehash hash of the same parameters: ", hashPwd)
+		}
+	}
+}
+
+func TestVerifyPasswordNeedsRehash(t *testing.T) {
+	defer SetDefaultPasswordHasher(&Bcrypt{Cost: bcrypt.DefaultCost})
+
+	origin := "abc-123"
+	bcryptPwd, _ := (&Bcrypt{Cost: bcrypt.MinCost}).Hash(origin)
+	argon2Pwd, _ := (&Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1}).Hash(origin)
+	SetDefaultPasswordHasher(&Argon2id{Memory: 1024, Iterations: 2, Parallelism: 1})
+
+	for encoded, expected := range map[string]bool{
+		bcryptPwd:               true, // other algorithm
+		argon2Pwd:               true, // other parameters
+		mustGenerate(t, origin): false,
+	} {
+		needsRehash, err := VerifyPassword(encoded, origin)
+		if err != nil {
+			t.Error("Unmatched password: ", err.Error())
+		} else if needsRehash != expected {
+			t.Errorf("Unexpected rehash %v of %s", needsRehash, encoded)
+		}
+	}
+
+	// legacy MD5 digests are only accepted for the configured passwords
+	if _, err := VerifyPassword(MD5String(origin), origin); err != ErrUnknownAlgorithm {
+		t.Error("Verified unsalted MD5 hash")
+	}
+	if needsRehash, err := VerifyConfigPassword(MD5String(origin), origin); err != nil || !needsRehash {
+		t.Error("Unverified legacy config password: ", err)
+	}
+	if _, err := VerifyConfigPassword(MD5String(origin), "abc-124"); err != ErrPasswordMismatch {
+		t.Error("Verified legacy config password of another password")
+	}
+	if _, err := VerifyPassword("plain", origin); err != ErrUnknownAlgorithm {
+		t.Error("Verified unknown hash")
+	}
+	if _, err := VerifyPassword("$argon2id$v=19$m=1024$c2FsdA$a2V5", origin); err != ErrInvalidHash {
+		t.Error("Verified invalid hash")
+	}
+}
+
+func mustGenerate(t *testing.T, password string) string {
+	hashPwd, err := GeneratePassword(password)
+	if err != nil {
+		t.Fatal("GeneratePassword Failed: ", err.Error())
+	}
+	return hashPwd
+}
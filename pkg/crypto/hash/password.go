@@ -0,0 +1,373 @@
+package hash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	ErrPasswordMismatch = errors.New("hash: password does not match")
+	ErrUnknownAlgorithm = errors.New("hash: unknown password hash algorithm")
+	ErrInvalidHash      = errors.New("hash: invalid encoded password hash")
+)
+
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmScrypt   = "scrypt"
+	AlgorithmMD5      = "md5" // Legacy unsalted hex digests, only verified for the configured passwords
+)
+
+const (
+	saltLength = 16
+	keyLength  = 32
+)
+
+// Password hash algorithm, the encoded hashes are self-describing (PHC string format or bcrypt's
+// modular crypt format) so hashes of different algorithms and parameters can be stored side by side.
+type PasswordHasher interface {
+	// Name of the algorithm, the identifier of the PHC string
+	Name() string
+	Hash(password string) (string, error)
+	// Verify the password, returns ErrPasswordMismatch if it doesn't match
+	Verify(encoded, password string) error
+	// Check if the encoded hash was created with other parameters than the hasher's
+	NeedsRehash(encoded string) bool
+}
+
+type PasswordConfig struct {
+	Algorithm         string // bcrypt/argon2id/scrypt
+	BcryptCost        int
+	Argon2Memory      uint32 // KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	ScryptLogN        int // CPU/memory cost as power of two
+	ScryptR           int
+	ScryptP           int
+}
+
+// Create the hasher of the configured algorithm, the missing parameters are set to the recommended values.
+func NewPasswordHasher(cfg PasswordConfig) (PasswordHasher, error) {
+	switch cfg.Algorithm {
+	case AlgorithmBcrypt, "":
+		if cfg.BcryptCost == 0 {
+			cfg.BcryptCost = bcrypt.DefaultCost
+		}
+		if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("hash: bcrypt cost %d is out of range", cfg.BcryptCost)
+		}
+		return &Bcrypt{Cost: cfg.BcryptCost}, nil
+	case AlgorithmArgon2id:
+		h := &Argon2id{Memory: cfg.Argon2Memory, Iterations: cfg.Argon2Iterations, Parallelism: cfg.Argon2Parallelism}
+		if h.Memory == 0 {
+			h.Memory = 64 * 1024
+		}
+		if h.Iterations == 0 {
+			h.Iterations = 3
+		}
+		if h.Parallelism == 0 {
+			h.Parallelism = 2
+		}
+		return h, nil
+	case AlgorithmScrypt:
+		h := &Scrypt{LogN: cfg.ScryptLogN, R: cfg.ScryptR, P: cfg.ScryptP}
+		if h.LogN == 0 {
+			h.LogN = 15
+		}
+		if h.R == 0 {
+			h.R = 8
+		}
+		if h.P == 0 {
+			h.P = 1
+		}
+		if h.LogN < 1 || h.LogN > 30 {
+			return nil, fmt.Errorf("hash: scrypt cost %d is out of range", h.LogN)
+		}
+		return h, nil
+	}
+	return nil, ErrUnknownAlgorithm
+}
+
+var (
+	hasherLock    sync.RWMutex
+	defaultHasher PasswordHasher = &Bcrypt{Cost: bcrypt.DefaultCost}
+	hashers                      = map[string]PasswordHasher{
+		AlgorithmBcrypt:   &Bcrypt{Cost: bcrypt.DefaultCost},
+		AlgorithmArgon2id: &Argon2id{Memory: 64 * 1024, Iterations: 3, Parallelism: 2},
+		AlgorithmScrypt:   &Scrypt{LogN: 15, R: 8, P: 1},
+	}
+)
+
+// Register the hasher to verify the hashes of its algorithm, it replaces the hasher of the same name.
+func RegisterPasswordHasher(h PasswordHasher) {
+	hasherLock.Lock()
+	defer hasherLock.Unlock()
+	hashers[h.Name()] = h
+}
+
+// Set the hasher of the new password hashes, the hashes of other algorithms or parameters need rehash.
+func SetDefaultPasswordHasher(h PasswordHasher) {
+	hasherLock.Lock()
+	defer hasherLock.Unlock()
+	hashers[h.Name()] = h
+	defaultHasher = h
+}
+
+func DefaultPasswordHasher() PasswordHasher {
+	hasherLock.RLock()
+	defer hasherLock.RUnlock()
+	return defaultHasher
+}
+
+var md5Regexp = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// Algorithm of the encoded hash, e.g. argon2id for `$argon2id$v=19$...`
+func PasswordAlgorithm(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return AlgorithmBcrypt
+	case md5Regexp.MatchString(encoded):
+		return AlgorithmMD5
+	case strings.HasPrefix(encoded, "$"):
+		if i := strings.IndexByte(encoded[1:], '$'); i > 0 {
+			return encoded[1 : i+1]
+		}
+	}
+	return ""
+}
+
+// Verify the password with the algorithm of the encoded hash. needsRehash reports if the hash
+// should be replaced with a hash of the default hasher, which is only known after the password matched.
+func VerifyPassword(encoded, password string) (needsRehash bool, err error) {
+	hasherLock.RLock()
+	h, ok := hashers[PasswordAlgorithm(encoded)]
+	def := defaultHasher
+	hasherLock.RUnlock()
+	if !ok {
+		return false, ErrUnknownAlgorithm
+	}
+
+	if err := h.Verify(encoded, password); err != nil {
+		return false, err
+	}
+	return h.Name() != def.Name() || def.NeedsRehash(encoded), nil
+}
+
+// Verify the password of the configuration, e.g. the root password, which may still be a legacy MD5 digest.
+// The MD5 digests are unsalted, so the stored hashes are only verified with VerifyPassword which rejects them.
+func VerifyConfigPassword(encoded, password string) (needsRehash bool, err error) {
+	if PasswordAlgorithm(encoded) == AlgorithmMD5 {
+		return true, legacyMD5{}.Verify(encoded, password)
+	}
+	return VerifyPassword(encoded, password)
+}
+
+// Generate password hash with the default hasher
+func GeneratePassword(password string) (string, error) {
+	return DefaultPasswordHasher().Hash(password)
+}
+
+// Compare the encoded hash of any registered algorithm and the password
+func CompareHashAndPassword(hashedPassword, password string) error {
+	_, err := VerifyPassword(hashedPassword, password)
+	return err
+}
+
+// Bcrypt in the modular crypt format, e.g. `$2a$10$...`
+type Bcrypt struct {
+	Cost int
+}
+
+func (a *Bcrypt) Name() string {
+	return AlgorithmBcrypt
+}
+
+func (a *Bcrypt) Hash(password string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(password), a.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (a *Bcrypt) Verify(encoded, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrPasswordMismatch
+	}
+	return err
+}
+
+func (a *Bcrypt) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != a.Cost
+}
+
+// Argon2id in the PHC string format, e.g. `$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>`
+type Argon2id struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+}
+
+func (a *Argon2id) Name() string {
+	return AlgorithmArgon2id
+}
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt, err := newSalt()
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, keyLength)
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", a.Memory, a.Iterations, a.Parallelism)
+	return encodePHC(AlgorithmArgon2id, fmt.Sprintf("v=%d", argon2.Version), params, salt, key), nil
+}
+
+func (a *Argon2id) decode(encoded string) (*Argon2id, []byte, []byte, error) {
+	fields, salt, key, err := decodePHC(encoded, AlgorithmArgon2id, 2)
+	if err != nil {
+		return nil, nil, nil, err
+	} else if fields[0] != fmt.Sprintf("v=%d", argon2.Version) {
+		return nil, nil, nil, ErrInvalidHash
+	}
+
+	var params Argon2id
+	if _, err := fmt.Sscanf(fields[1], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return nil, nil, nil, ErrInvalidHash
+	} else if params.Iterations == 0 || params.Parallelism == 0 {
+		return nil, nil, nil, ErrInvalidHash
+	}
+	return &params, salt, key, nil
+}
+
+func (a *Argon2id) Verify(encoded, password string) error {
+	params, salt, key, err := a.decode(encoded)
+	if err != nil {
+		return err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+func (a *Argon2id) NeedsRehash(encoded string) bool {
+	params, _, _, err := a.decode(encoded)
+	return err != nil || *params != *a
+}
+
+// Scrypt in the PHC string format, e.g. `$scrypt$ln=15,r=8,p=1$<salt>$<hash>`
+type Scrypt struct {
+	LogN int // CPU/memory cost as power of two
+	R    int
+	P    int
+}
+
+func (a *Scrypt) Name() string {
+	return AlgorithmScrypt
+}
+
+func (a *Scrypt) Hash(password string) (string, error) {
+	salt, err := newSalt()
+	if err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key([]byte(password), salt, 1<<a.LogN, a.R, a.P, keyLength)
+	if err != nil {
+		return "", err
+	}
+	return encodePHC(AlgorithmScrypt, "", fmt.Sprintf("ln=%d,r=%d,p=%d", a.LogN, a.R, a.P), salt, key), nil
+}
+
+func (a *Scrypt) decode(encoded string) (*Scrypt, []byte, []byte, error) {
+	fields, salt, key, err := decodePHC(encoded, AlgorithmScrypt, 1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var params Scrypt
+	if _, err := fmt.Sscanf(fields[0], "ln=%d,r=%d,p=%d", &params.LogN, &params.R, &params.P); err != nil {
+		return nil, nil, nil, ErrInvalidHash
+	} else if params.LogN < 1 || params.LogN > 30 {
+		return nil, nil, nil, ErrInvalidHash
+	}
+	return &params, salt, key, nil
+}
+
+func (a *Scrypt) Verify(encoded, password string) error {
+	params, salt, key, err := a.decode(encoded)
+	if err != nil {
+		return err
+	}
+	other, err := scrypt.Key([]byte(password), salt, 1<<params.LogN, params.R, params.P, len(key))
+	if err != nil {
+		return ErrInvalidHash
+	}
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+func (a *Scrypt) NeedsRehash(encoded string) bool {
+	params, _, _, err := a.decode(encoded)
+	return err != nil || *params != *a
+}
+
+// Unsalted MD5 hex digests of the old root password, it's not registered so only VerifyConfigPassword accepts them
+type legacyMD5 struct{}
+
+func (legacyMD5) Verify(encoded, password string) error {
+	if subtle.ConstantTimeCompare([]byte(strings.ToLower(encoded)), []byte(MD5String(password))) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+func newSalt() ([]byte, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+var phcEncoding = base64.RawStdEncoding
+
+func encodePHC(id, version, params string, salt, key []byte) string {
+	fields := []string{"", id}
+	if version != "" {
+		fields = append(fields, version)
+	}
+	fields = append(fields, params, phcEncoding.EncodeToString(salt), phcEncoding.EncodeToString(key))
+	return strings.Join(fields, "$")
+}
+
+// Split the PHC string `$id[$v=version]$params$salt$hash` into its n leading fields, salt and hash.
+func decodePHC(encoded, id string, n int) ([]string, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != n+4 || parts[0] != "" || parts[1] != id {
+		return nil, nil, nil, ErrInvalidHash
+	}
+	salt, err := phcEncoding.DecodeString(parts[n+2])
+	if err != nil || len(salt) == 0 {
+		return nil, nil, nil, ErrInvalidHash
+	}
+	key, err := phcEncoding.DecodeString(parts[n+3])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, ErrInvalidHash
+	}
+	return parts[2 : n+2], salt, key, nil
+}
@@ -0,0 +1,57 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCode(t *testing.T) {
+	assert := assert.New(t)
+
+	// Test vectors of RFC 6238 (SHA1), truncated to 6 digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range vectors {
+		code, err := GenerateCode(secret, time.Unix(unix, 0))
+		assert.Nil(err)
+		assert.Equal(want, code, "time %d", unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	secret, err := GenerateSecret()
+	assert.Nil(err)
+	assert.Len(secret, 32)
+
+	now := time.Unix(1700000000, 0)
+	code, err := GenerateCode(secret, now)
+	assert.Nil(err)
+
+	step, ok := Validate(secret, code, now)
+	assert.True(ok)
+	assert.Equal(uint64(now.Unix()/Period), step)
+
+	_, ok = Validate(strings.ToLower(secret), code, now.Add(Period*time.Second))
+	assert.True(ok)
+	_, ok = Validate(secret, code, now.Add(3*Period*time.Second))
+	assert.False(ok)
+	_, ok = Validate(secret, "12345", now)
+	assert.False(ok)
+
+	uri := ProvisioningURI(secret, "go-admin", "test@example.com")
+	assert.True(strings.HasPrefix(uri, "otpauth://totp/go-admin:test@example.com?"))
+	assert.Contains(uri, "secret="+secret)
+}
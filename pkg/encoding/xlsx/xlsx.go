@@ -0,0 +1,349 @@
+// Package xlsx reads and writes the rows of the first worksheet of Office Open XML spreadsheets,
+// styles, formulas and further sheets are not supported.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	nsMain          = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRelationships = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsPackageRels   = "http://schemas.openxmlformats.org/package/2006/relationships"
+)
+
+// Maximum uncompressed size of a part of the spreadsheet, protects against zip bombs
+var MaxPartSize int64 = 64 << 20
+
+var ErrPartTooLarge = errors.New("xlsx: part too large")
+
+// A Writer streams the rows to the first worksheet of a spreadsheet, all cells are written as strings.
+// The spreadsheet is complete after Close.
+type Writer struct {
+	SheetName string // Name of the worksheet, default Sheet1
+
+	zw      *zip.Writer
+	sheet   io.Writer
+	rows    int
+	started bool
+	err     error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		SheetName: "Sheet1",
+		zw:        zip.NewWriter(w),
+	}
+}
+
+// Write a row of cells to the worksheet.
+func (w *Writer) Write(record []string) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	w.rows++
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<row r="%d">`, w.rows)
+	for i, value := range record {
+		if value == "" {
+			continue
+		}
+		fmt.Fprintf(&buf, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, ColumnName(i), w.rows)
+		if err := xml.EscapeText(&buf, []byte(value)); err != nil {
+			return err
+		}
+		buf.WriteString(`</t></is></c>`)
+	}
+	buf.WriteString(`</row>`)
+	if _, err := buf.WriteTo(w.sheet); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// Close the worksheet and write the end of the spreadsheet, it doesn't close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// Write the package parts before the rows of the worksheet.
+func (w *Writer) start() error {
+	if w.err != nil || w.started {
+		return w.err
+	}
+	w.started = true
+
+	var sheetName bytes.Buffer
+	if err := xml.EscapeText(&sheetName, []byte(w.SheetName)); err != nil {
+		w.err = err
+		return err
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="` + nsPackageRels + `">` +
+			`<Relationship Id="rId1" Type="` + nsRelationships + `/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="` + nsMain + `" xmlns:r="` + nsRelationships + `">` +
+			`<sheets><sheet name="` + sheetName.String() + `" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="` + nsPackageRels + `">` +
+			`<Relationship Id="rId1" Type="` + nsRelationships + `/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+	}
+	for _, part := range parts {
+		if err := w.writePart(part.name, part.content); err != nil {
+			w.err = err
+			return err
+		}
+	}
+
+	sheet, err := w.zw.Create("xl/worksheets/sheet1.xml")
+	if err == nil {
+		_, err = io.WriteString(sheet, xml.Header+`<worksheet xmlns="`+nsMain+`"><sheetData>`)
+	}
+	if err != nil {
+		w.err = err
+		return err
+	}
+	w.sheet = sheet
+	return nil
+}
+
+func (w *Writer) writePart(name, content string) error {
+	part, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, xml.Header+content)
+	return err
+}
+
+type xmlText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// Plain text of a shared or inline string, the rich text runs are concatenated
+func (a xmlText) String() string {
+	if len(a.Runs) == 0 {
+		return a.T
+	}
+	var sb strings.Builder
+	for _, run := range a.Runs {
+		sb.WriteString(run.T)
+	}
+	return sb.String()
+}
+
+type xmlWorkbook struct {
+	Sheets []struct {
+		ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xmlRelationships struct {
+	Items []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xmlSharedStrings struct {
+	Items []xmlText `xml:"si"`
+}
+
+type xmlWorksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string  `xml:"r,attr"`
+			T      string  `xml:"t,attr"`
+			V      string  `xml:"v"`
+			Inline xmlText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// Read all rows of the first worksheet as strings, the missing rows and cells are empty.
+func ReadAll(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var sst xmlSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(f, &sst); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("xlsx: worksheet %s not found", sheetPath)
+	}
+	var sheet xmlWorksheet
+	if err := decodePart(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		// the rows are numbered from 1, the empty rows may be omitted
+		for row.R > len(rows)+1 {
+			rows = append(rows, nil)
+		}
+
+		var record []string
+		for _, cell := range row.Cells {
+			col := len(record)
+			if cell.R != "" {
+				if col, err = columnIndex(cell.R); err != nil {
+					return nil, err
+				}
+			}
+			for col > len(record) {
+				record = append(record, "")
+			}
+
+			value := cell.V
+			switch cell.T {
+			case "s":
+				idx, err := strconv.Atoi(cell.V)
+				if err != nil || idx < 0 || idx >= len(sst.Items) {
+					return nil, fmt.Errorf("xlsx: invalid shared string index %q of cell %s", cell.V, cell.R)
+				}
+				value = sst.Items[idx].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			case "b":
+				value = strconv.FormatBool(cell.V == "1")
+			}
+			record = append(record, value)
+		}
+		rows = append(rows, record)
+	}
+	return rows, nil
+}
+
+// Resolve the path of the first worksheet from the workbook relationships.
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const defaultPath = "xl/worksheets/sheet1.xml"
+
+	f, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("xlsx: workbook not found")
+	}
+	var workbook xmlWorkbook
+	if err := decodePart(f, &workbook); err != nil {
+		return "", err
+	} else if len(workbook.Sheets) == 0 {
+		return "", errors.New("xlsx: no worksheet")
+	}
+
+	f, ok = files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return defaultPath, nil
+	}
+	var rels xmlRelationships
+	if err := decodePart(f, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Items {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return defaultPath, nil
+}
+
+func decodePart(f *zip.File, v interface{}) error {
+	if int64(f.UncompressedSize64) > MaxPartSize {
+		return ErrPartTooLarge
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// the declared size may be forged
+	lr := &io.LimitedReader{R: rc, N: MaxPartSize + 1}
+	if err := xml.NewDecoder(lr).Decode(v); err != nil {
+		if lr.N <= 0 {
+			return ErrPartTooLarge
+		}
+		return fmt.Errorf("xlsx: invalid %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// Name of the zero based column index, e.g. 0 is A and 26 is AA.
+func ColumnName(idx int) string {
+	name := ""
+	for idx++; idx > 0; idx = (idx - 1) / 26 {
+		name = string(rune('A'+(idx-1)%26)) + name
+	}
+	return name
+}
+
+// Zero based column index of the cell reference, e.g. B3 is 1.
+func columnIndex(ref string) (int, error) {
+	idx := 0
+	n := 0
+	for _, c := range ref {
+		if c >= 'A' && c <= 'Z' {
+			idx = idx*26 + int(c-'A') + 1
+			n++
+		} else if c >= 'a' && c <= 'z' {
+			idx = idx*26 + int(c-'a') + 1
+			n++
+		} else {
+			break
+		}
+	}
+	if n == 0 || n > 3 {
+		return 0, fmt.Errorf("xlsx: invalid cell reference %q", ref)
+	}
+	return idx - 1, nil
+}
@@ -0,0 +1,83 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRead(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.SheetName = "Users & Roles"
+	records := [][]string{
+		{"email", "name", "remark"},
+		{"tom@example.com", "Tom <Admin>", " leading space"},
+		{"", "", "only the third"},
+		{"jerry@example.com", "Jerry", ""},
+	}
+	for _, record := range records {
+		assert.Nil(w.Write(record))
+	}
+	assert.Nil(w.Close())
+
+	rows, err := ReadAll(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(err)
+	assert.Equal([][]string{
+		{"email", "name", "remark"},
+		{"tom@example.com", "Tom <Admin>", " leading space"},
+		{"", "", "only the third"},
+		{"jerry@example.com", "Jerry"},
+	}, rows)
+}
+
+func TestReadSharedStrings(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Data" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/data.xml"/>` +
+			`</Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>email</t></si><si><r><t>rich </t></r><r><t>text</t></r></si></sst>`,
+		"xl/worksheets/data.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1"><v>13800000000</v></c></row>` +
+			`<row r="3"><c r="B3" t="s"><v>1</v></c><c r="C3" t="b"><v>1</v></c></row>` +
+			`</sheetData></worksheet>`,
+	} {
+		f, err := zw.Create(name)
+		assert.Nil(err)
+		_, err = f.Write([]byte(content))
+		assert.Nil(err)
+	}
+	assert.Nil(zw.Close())
+
+	rows, err := ReadAll(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(err)
+	assert.Equal([][]string{
+		{"email", "", "13800000000"},
+		nil,
+		{"", "rich text", "true"},
+	}, rows)
+
+	_, err = ReadAll(bytes.NewReader([]byte("email,name")), 10)
+	assert.NotNil(err)
+}
+
+func TestColumnName(t *testing.T) {
+	assert := assert.New(t)
+	for idx, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(name, ColumnName(idx))
+		got, err := columnIndex(name + "12")
+		assert.Nil(err)
+		assert.Equal(idx, got)
+	}
+}
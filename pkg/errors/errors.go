@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -197,6 +198,33 @@ func InternalServerError(id, format string, a ...interface{}) error {
 	}
 }
 
+type retryError struct {
+	error
+	retryAfter time.Duration
+}
+
+func (e *retryError) Unwrap() error {
+	return e.error
+}
+
+// WithRetryAfter annotates err with the time after which the request may be retried,
+// it is responded in the Retry-After header.
+func WithRetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryError{error: err, retryAfter: d}
+}
+
+// RetryAfter returns the retry time annotated by WithRetryAfter in err's chain
+func RetryAfter(err error) (time.Duration, bool) {
+	var rerr *retryError
+	if errors.As(err, &rerr) {
+		return rerr.retryAfter, true
+	}
+	return 0, false
+}
+
 // Equal tries to compare errors
 func Equal(err1 error, err2 error) bool {
 	verr1, ok1 := err1.(*Error)
@@ -3,6 +3,7 @@ package jwtx
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/patrickmn/go-cache"
@@ -22,6 +23,7 @@ func NewMemoryCache(cfg MemoryConfig) Cacher {
 
 type memCache struct {
 	cache *cache.Cache
+	mu    sync.Mutex // serializes GetAndDelete, so a value is only taken once
 }
 
 func (a *memCache) getKey(ns, key string) string {
@@ -46,6 +48,19 @@ func (a *memCache) Get(ctx context.Context, ns, key string) (string, bool, error
 	return val.(string), ok, nil
 }
 
+func (a *memCache) GetAndDelete(ctx context.Context, ns, key string) (string, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key = a.getKey(ns, key)
+	val, ok := a.cache.Get(key)
+	if !ok {
+		return "", false, nil
+	}
+	a.cache.Delete(key)
+	return val.(string), true, nil
+}
+
 func (a *memCache) Exists(ctx context.Context, ns, key string) (bool, error) {
 	_, ok := a.cache.Get(a.getKey(ns, key))
 	return ok, nil
@@ -2,34 +2,59 @@ package jwtx
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	jsoniter "github.com/json-iterator/go"
 )
 
 type Auther interface {
-	// Generate a JWT (JSON Web Token) with the provided subject.
+	// Generate a JWT (JSON Web Token) and a refresh token with the provided subject, the pair starts a new token family.
 	GenerateToken(ctx context.Context, subject string) (TokenInfo, error)
-	// Invalidate a token by removing it from the token store.
+	// Exchange a refresh token for a new token pair of the same family, the refresh token can only be used once
+	// and using it again revokes the whole family.
+	RefreshToken(ctx context.Context, refreshToken string) (TokenInfo, error)
+	// Invalidate a token by removing it from the token store, the refresh tokens of its family are revoked too.
 	DestroyToken(ctx context.Context, accessToken string) error
 	// Parse the subject (or user identifier) from a given access token.
 	ParseSubject(ctx context.Context, accessToken string) (string, error)
+	// Parse the subject and the session ID (token family) from a given access token.
+	ParseSession(ctx context.Context, accessToken string) (string, string, error)
+	// Generate a short-lived JWT for the subject acting on behalf of the actor (RFC 8693 `act` claim),
+	// it starts a new token family without a refresh token.
+	GenerateActorToken(ctx context.Context, subject, actor string, expired time.Duration) (TokenInfo, error)
+	// Parse the subject, the session ID and the actor from a given access token.
+	ParseClaims(ctx context.Context, accessToken string) (*TokenClaims, error)
+	// Revoke every access token and refresh token of the session.
+	RevokeSession(ctx context.Context, sessionID string) error
+	// Return the public keys of asymmetric signing methods to verify tokens without the secret.
+	JWKS() *JSONWebKeySet
 	// Release any resources held by the JWTAuth instance.
 	Release(ctx context.Context) error
 }
 
 const defaultKey = "CG24SDVP8OHPK395GB5G"
 
-var ErrInvalidToken = errors.New("Invalid token")
+var (
+	ErrInvalidToken       = errors.New("Invalid token")
+	ErrRefreshTokenReused = errors.New("Refresh token reused")
+)
 
 type options struct {
-	signingMethod jwt.SigningMethod
-	signingKey    []byte
-	signingKey2   []byte
-	keyFuncs      []func(*jwt.Token) (interface{}, error)
-	expired       int
-	tokenType     string
+	signingMethod  jwt.SigningMethod
+	signingKey     []byte
+	signingKey2    []byte
+	keyFuncs       []func(*jwt.Token) (interface{}, error)
+	keys           []*Key
+	signKey        *Key
+	expired        int
+	refreshExpired int
+	tokenType      string
 }
 
 type Option func(*options)
@@ -55,31 +80,66 @@ func SetExpired(expired int) Option {
 	}
 }
 
+// Sign tokens with asymmetric keys instead of the HMAC signing key, the first key with a private key
+// signs new tokens and the others only verify tokens, so keys can be rotated without invalidating tokens.
+// Tokens without the `kid` header are still verified with the keys set by SetSigningKey.
+func SetKeys(keys ...*Key) Option {
+	return func(o *options) {
+		o.keys = keys
+	}
+}
+
+// Set the lifetime of refresh tokens in seconds, each rotation issues a refresh token with the full lifetime.
+func SetRefreshExpired(expired int) Option {
+	return func(o *options) {
+		o.refreshExpired = expired
+	}
+}
+
 func New(store Storer, opts ...Option) Auther {
 	o := options{
-		tokenType:     "Bearer",
-		expired:       7200,
-		signingMethod: jwt.SigningMethodHS512,
-		signingKey:    []byte(defaultKey),
+		tokenType:      "Bearer",
+		expired:        7200,
+		refreshExpired: 604800,
+		signingMethod:  jwt.SigningMethodHS512,
+		signingKey:     []byte(defaultKey),
 	}
 
 	for _, opt := range opts {
 		opt(&o)
 	}
 
-	o.keyFuncs = append(o.keyFuncs, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
+	for _, key := range o.keys {
+		if key.PrivateKey != nil {
+			o.signKey = key
+			break
 		}
-		return o.signingKey, nil
-	})
+	}
 
-	if o.signingKey2 != nil {
+	if len(o.keys) > 0 {
+		o.keyFuncs = append(o.keyFuncs, func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			for _, key := range o.keys {
+				if key.ID == kid && key.Method.Alg() == t.Method.Alg() {
+					return key.PublicKey, nil
+				}
+			}
+			return nil, ErrInvalidToken
+		})
+	}
+
+	for _, signingKey := range [][]byte{o.signingKey, o.signingKey2} {
+		if len(signingKey) == 0 {
+			continue
+		}
+		signingKey := signingKey
 		o.keyFuncs = append(o.keyFuncs, func(t *jwt.Token) (interface{}, error) {
 			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, ErrInvalidToken
+			} else if _, ok := t.Header["kid"]; ok {
+				return nil, ErrInvalidToken
 			}
-			return o.signingKey2, nil
+			return signingKey, nil
 		})
 	}
 
@@ -94,18 +154,53 @@ type JWTAuth struct {
 	store Storer
 }
 
+// The claims of access tokens, Family links the token to the refresh tokens issued with it.
+type claims struct {
+	jwt.StandardClaims
+	Family string       `json:"fid,omitempty"`
+	Actor  *actorClaims `json:"act,omitempty"`
+}
+
+// The party acting on behalf of the subject, e.g. an administrator impersonating a user.
+type actorClaims struct {
+	Subject string `json:"sub"`
+}
+
+// The verified claims of an access token
+type TokenClaims struct {
+	Subject   string
+	SessionID string
+	Actor     string // Subject of the actor, empty if the token is not issued on behalf of the subject
+}
+
+// The state of a refresh token kept in the store, used tokens are kept until they expire to detect reuse.
+type refreshState struct {
+	Subject   string `json:"sub"`
+	Family    string `json:"fid"`
+	ExpiresAt int64  `json:"exp"`
+}
+
 func (a *JWTAuth) GenerateToken(ctx context.Context, subject string) (TokenInfo, error) {
+	family, err := randomString(16, hex.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	return a.generateToken(ctx, subject, family)
+}
+
+func (a *JWTAuth) generateToken(ctx context.Context, subject, family string) (TokenInfo, error) {
 	now := time.Now()
 	expiresAt := now.Add(time.Duration(a.opts.expired) * time.Second).Unix()
 
-	token := jwt.NewWithClaims(a.opts.signingMethod, &jwt.StandardClaims{
-		IssuedAt:  now.Unix(),
-		ExpiresAt: expiresAt,
-		NotBefore: now.Unix(),
-		Subject:   subject,
+	tokenStr, err := a.signToken(&claims{
+		StandardClaims: jwt.StandardClaims{
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt,
+			NotBefore: now.Unix(),
+			Subject:   subject,
+		},
+		Family: family,
 	})
-
-	tokenStr, err := token.SignedString(a.opts.signingKey)
 	if err != nil {
 		return nil, err
 	}
@@ -114,18 +209,155 @@ func (a *JWTAuth) GenerateToken(ctx context.Context, subject string) (TokenInfo,
 		ExpiresAt:   expiresAt,
 		TokenType:   a.opts.tokenType,
 		AccessToken: tokenStr,
+		SessionID:   family,
+	}
+
+	// Refresh tokens are opaque and only usable with a store
+	if a.store == nil {
+		return tokenInfo, nil
+	}
+
+	refreshToken, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
 	}
+	refreshExpiresAt := now.Add(time.Duration(a.opts.refreshExpired) * time.Second)
+	err = a.setRefreshState(ctx, refreshToken, &refreshState{
+		Subject:   subject,
+		Family:    family,
+		ExpiresAt: refreshExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokenInfo.RefreshToken = refreshToken
+	tokenInfo.RefreshExpiresAt = refreshExpiresAt.Unix()
 	return tokenInfo, nil
 }
 
-func (a *JWTAuth) parseToken(tokenStr string) (*jwt.StandardClaims, error) {
+func (a *JWTAuth) GenerateActorToken(ctx context.Context, subject, actor string, expired time.Duration) (TokenInfo, error) {
+	family, err := randomString(16, hex.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(expired).Unix()
+	tokenStr, err := a.signToken(&claims{
+		StandardClaims: jwt.StandardClaims{
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt,
+			NotBefore: now.Unix(),
+			Subject:   subject,
+		},
+		Family: family,
+		Actor:  &actorClaims{Subject: actor},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenInfo{
+		ExpiresAt:   expiresAt,
+		TokenType:   a.opts.tokenType,
+		AccessToken: tokenStr,
+		SessionID:   family,
+	}, nil
+}
+
+func (a *JWTAuth) signToken(c *claims) (string, error) {
+	token := jwt.NewWithClaims(a.opts.signingMethod, c)
+
+	var signingKey interface{} = a.opts.signingKey
+	if key := a.opts.signKey; key != nil {
+		token.Method = key.Method
+		token.Header["alg"] = key.Method.Alg()
+		token.Header["kid"] = key.ID
+		signingKey = key.PrivateKey
+	}
+	return token.SignedString(signingKey)
+}
+
+func (a *JWTAuth) RefreshToken(ctx context.Context, refreshToken string) (TokenInfo, error) {
+	if refreshToken == "" || a.store == nil {
+		return nil, ErrInvalidToken
+	}
+
+	id := refreshTokenID(refreshToken)
+	val, ok, err := a.store.GetRefreshToken(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	var state refreshState
+	if err := jsoniter.Unmarshal([]byte(val), &state); err != nil {
+		return nil, ErrInvalidToken
+	} else if time.Now().Unix() >= state.ExpiresAt {
+		return nil, ErrInvalidToken
+	}
+
+	if revoked, err := a.store.IsFamilyRevoked(ctx, state.Family); err != nil {
+		return nil, err
+	} else if revoked {
+		return nil, ErrInvalidToken
+	}
+
+	// A token claimed before means it was leaked, so the tokens of both the attacker and the user are revoked.
+	// The claim is atomic, of the concurrent refreshes with the same token only one can succeed.
+	if claimed, err := a.store.ClaimRefreshToken(ctx, id); err != nil {
+		return nil, err
+	} else if !claimed {
+		if err := a.revokeFamily(ctx, state.Family); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	return a.generateToken(ctx, state.Subject, state.Family)
+}
+
+func (a *JWTAuth) setRefreshState(ctx context.Context, refreshToken string, state *refreshState) error {
+	buf, err := jsoniter.Marshal(state)
+	if err != nil {
+		return err
+	}
+	expired := time.Until(time.Unix(state.ExpiresAt, 0))
+	return a.store.SetRefreshToken(ctx, refreshTokenID(refreshToken), string(buf), expired)
+}
+
+// Revoke the family until every refresh token and access token of it expires.
+func (a *JWTAuth) revokeFamily(ctx context.Context, family string) error {
+	expired := time.Duration(a.opts.refreshExpired) * time.Second
+	if access := time.Duration(a.opts.expired) * time.Second; access > expired {
+		expired = access
+	}
+	return a.store.RevokeFamily(ctx, family, expired)
+}
+
+// The store only keeps the hash of refresh tokens.
+func refreshTokenID(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
+
+func (a *JWTAuth) parseToken(tokenStr string) (*claims, error) {
 	var (
 		token *jwt.Token
 		err   error
 	)
 
 	for _, keyFunc := range a.opts.keyFuncs {
-		token, err = jwt.ParseWithClaims(tokenStr, &jwt.StandardClaims{}, keyFunc)
+		token, err = jwt.ParseWithClaims(tokenStr, &claims{}, keyFunc)
 		if err != nil || token == nil || !token.Valid {
 			continue
 		}
@@ -136,7 +368,7 @@ func (a *JWTAuth) parseToken(tokenStr string) (*jwt.StandardClaims, error) {
 		return nil, ErrInvalidToken
 	}
 
-	return token.Claims.(*jwt.StandardClaims), nil
+	return token.Claims.(*claims), nil
 }
 
 func (a *JWTAuth) callStore(fn func(Storer) error) error {
@@ -154,18 +386,37 @@ func (a *JWTAuth) DestroyToken(ctx context.Context, tokenStr string) error {
 
 	return a.callStore(func(store Storer) error {
 		expired := time.Until(time.Unix(claims.ExpiresAt, 0))
-		return store.Set(ctx, tokenStr, expired)
+		if err := store.Set(ctx, tokenStr, expired); err != nil {
+			return err
+		}
+		if claims.Family != "" {
+			return a.revokeFamily(ctx, claims.Family)
+		}
+		return nil
 	})
 }
 
 func (a *JWTAuth) ParseSubject(ctx context.Context, tokenStr string) (string, error) {
+	subject, _, err := a.ParseSession(ctx, tokenStr)
+	return subject, err
+}
+
+func (a *JWTAuth) ParseSession(ctx context.Context, tokenStr string) (string, string, error) {
+	tokenClaims, err := a.ParseClaims(ctx, tokenStr)
+	if err != nil {
+		return "", "", err
+	}
+	return tokenClaims.Subject, tokenClaims.SessionID, nil
+}
+
+func (a *JWTAuth) ParseClaims(ctx context.Context, tokenStr string) (*TokenClaims, error) {
 	if tokenStr == "" {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
 	claims, err := a.parseToken(tokenStr)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	err = a.callStore(func(store Storer) error {
@@ -174,13 +425,45 @@ func (a *JWTAuth) ParseSubject(ctx context.Context, tokenStr string) (string, er
 		} else if exists {
 			return ErrInvalidToken
 		}
+
+		if claims.Family != "" {
+			if revoked, err := store.IsFamilyRevoked(ctx, claims.Family); err != nil {
+				return err
+			} else if revoked {
+				return ErrInvalidToken
+			}
+		}
 		return nil
 	})
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	tokenClaims := &TokenClaims{
+		Subject:   claims.Subject,
+		SessionID: claims.Family,
+	}
+	if claims.Actor != nil {
+		tokenClaims.Actor = claims.Actor.Subject
 	}
+	return tokenClaims, nil
+}
+
+func (a *JWTAuth) RevokeSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	return a.callStore(func(store Storer) error {
+		return a.revokeFamily(ctx, sessionID)
+	})
+}
 
-	return claims.Subject, nil
+func (a *JWTAuth) JWKS() *JSONWebKeySet {
+	set := &JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(a.opts.keys))}
+	for _, key := range a.opts.keys {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+	return set
 }
 
 func (a *JWTAuth) Release(ctx context.Context) error {
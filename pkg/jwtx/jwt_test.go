@@ -2,6 +2,7 @@ package jwtx
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -35,3 +36,155 @@ func TestAuth(t *testing.T) {
 	err = jwtAuth.Release(ctx)
 	assert.Nil(t, err)
 }
+
+func TestRefreshToken(t *testing.T) {
+	cache := NewMemoryCache(MemoryConfig{CleanupInterval: time.Second})
+
+	store := NewStoreWithCache(cache)
+	ctx := context.Background()
+	jwtAuth := New(store)
+
+	userID := "test"
+	token, err := jwtAuth.GenerateToken(ctx, userID)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token.GetRefreshToken())
+	assert.Greater(t, token.GetRefreshExpiresAt(), token.GetExpiresAt())
+
+	// Rotation issues a new pair of the same family
+	newToken, err := jwtAuth.RefreshToken(ctx, token.GetRefreshToken())
+	assert.Nil(t, err)
+	assert.NotEqual(t, token.GetRefreshToken(), newToken.GetRefreshToken())
+
+	id, err := jwtAuth.ParseSubject(ctx, newToken.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, userID, id)
+
+	// Reusing a rotated token revokes the whole family
+	_, err = jwtAuth.RefreshToken(ctx, token.GetRefreshToken())
+	assert.EqualError(t, err, ErrRefreshTokenReused.Error())
+
+	_, err = jwtAuth.RefreshToken(ctx, newToken.GetRefreshToken())
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+	_, err = jwtAuth.ParseSubject(ctx, newToken.GetAccessToken())
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+	_, err = jwtAuth.ParseSubject(ctx, token.GetAccessToken())
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+
+	// Other families are not affected, and destroying the access token revokes its refresh token
+	otherToken, err := jwtAuth.GenerateToken(ctx, userID)
+	assert.Nil(t, err)
+	_, err = jwtAuth.ParseSubject(ctx, otherToken.GetAccessToken())
+	assert.Nil(t, err)
+
+	err = jwtAuth.DestroyToken(ctx, otherToken.GetAccessToken())
+	assert.Nil(t, err)
+	_, err = jwtAuth.RefreshToken(ctx, otherToken.GetRefreshToken())
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+
+	_, err = jwtAuth.RefreshToken(ctx, "unknown")
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestRefreshTokenConcurrent(t *testing.T) {
+	cache := NewMemoryCache(MemoryConfig{CleanupInterval: time.Second})
+
+	store := NewStoreWithCache(cache)
+	ctx := context.Background()
+	jwtAuth := New(store)
+
+	token, err := jwtAuth.GenerateToken(ctx, "test")
+	assert.Nil(t, err)
+
+	// Only one of the concurrent refreshes with the same token succeeds, the first loser detects
+	// the reuse and the later ones see the revoked family
+	var wg sync.WaitGroup
+	results := make(chan error, 10)
+	for i := 0; i < cap(results); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := jwtAuth.RefreshToken(ctx, token.GetRefreshToken())
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var succeeded, reused, invalid int
+	for err := range results {
+		switch err {
+		case nil:
+			succeeded++
+		case ErrRefreshTokenReused:
+			reused++
+		case ErrInvalidToken:
+			invalid++
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.GreaterOrEqual(t, reused, 1)
+	assert.Equal(t, cap(results)-1, reused+invalid)
+
+	// The reuse revokes the family, including the token of the winner
+	_, err = jwtAuth.ParseSubject(ctx, token.GetAccessToken())
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestRevokeSession(t *testing.T) {
+	cache := NewMemoryCache(MemoryConfig{CleanupInterval: time.Second})
+
+	store := NewStoreWithCache(cache)
+	ctx := context.Background()
+	jwtAuth := New(store)
+
+	token, err := jwtAuth.GenerateToken(ctx, "test")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token.GetSessionID())
+
+	newToken, err := jwtAuth.RefreshToken(ctx, token.GetRefreshToken())
+	assert.Nil(t, err)
+	assert.Equal(t, token.GetSessionID(), newToken.GetSessionID())
+
+	subject, sessionID, err := jwtAuth.ParseSession(ctx, newToken.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, "test", subject)
+	assert.Equal(t, token.GetSessionID(), sessionID)
+
+	err = jwtAuth.RevokeSession(ctx, sessionID)
+	assert.Nil(t, err)
+	_, err = jwtAuth.ParseSubject(ctx, newToken.GetAccessToken())
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+	_, err = jwtAuth.RefreshToken(ctx, newToken.GetRefreshToken())
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestActorToken(t *testing.T) {
+	cache := NewMemoryCache(MemoryConfig{CleanupInterval: time.Second})
+
+	store := NewStoreWithCache(cache)
+	ctx := context.Background()
+	jwtAuth := New(store)
+
+	token, err := jwtAuth.GenerateActorToken(ctx, "user", "admin", time.Minute)
+	assert.Nil(t, err)
+	assert.Empty(t, token.GetRefreshToken())
+	assert.LessOrEqual(t, token.GetExpiresAt(), time.Now().Add(time.Minute).Unix())
+
+	claims, err := jwtAuth.ParseClaims(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, &TokenClaims{Subject: "user", SessionID: token.GetSessionID(), Actor: "admin"}, claims)
+
+	// Tokens of the subject itself have no actor
+	userToken, err := jwtAuth.GenerateToken(ctx, "user")
+	assert.Nil(t, err)
+	claims, err = jwtAuth.ParseClaims(ctx, userToken.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Empty(t, claims.Actor)
+
+	err = jwtAuth.DestroyToken(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+	_, err = jwtAuth.ParseClaims(ctx, token.GetAccessToken())
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+	_, err = jwtAuth.ParseClaims(ctx, userToken.GetAccessToken())
+	assert.Nil(t, err)
+}
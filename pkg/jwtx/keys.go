@@ -0,0 +1,169 @@
+package jwtx
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Key is an asymmetric key to sign or verify tokens, tokens carry the key ID in the `kid` header.
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer    // Nil for keys that only verify tokens, e.g. retired keys
+	PublicKey  crypto.PublicKey // Published in the JWK Set
+}
+
+// Parse a key from PEM encoded data, the public key is derived from the private key if it is not provided.
+// Supported methods are RS256/RS384/RS512, PS256/PS384/PS512, ES256/ES384/ES512 and EdDSA.
+func ParseKey(id, method string, privatePEM, publicPEM []byte) (*Key, error) {
+	if id == "" {
+		return nil, errors.New("jwtx: key id is required")
+	}
+	m := jwt.GetSigningMethod(method)
+	if m == nil {
+		return nil, fmt.Errorf("jwtx: unknown signing method %q", method)
+	}
+
+	key := &Key{ID: id, Method: m}
+	var err error
+	switch m.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		if len(privatePEM) > 0 {
+			key.PrivateKey, err = jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+		} else if len(publicPEM) > 0 {
+			key.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+		}
+	case *jwt.SigningMethodECDSA:
+		if len(privatePEM) > 0 {
+			key.PrivateKey, err = jwt.ParseECPrivateKeyFromPEM(privatePEM)
+		} else if len(publicPEM) > 0 {
+			key.PublicKey, err = jwt.ParseECPublicKeyFromPEM(publicPEM)
+		}
+	case *jwt.SigningMethodEd25519:
+		if len(privatePEM) > 0 {
+			var pk crypto.PrivateKey
+			if pk, err = jwt.ParseEdPrivateKeyFromPEM(privatePEM); err == nil {
+				key.PrivateKey = pk.(ed25519.PrivateKey)
+			}
+		} else if len(publicPEM) > 0 {
+			key.PublicKey, err = jwt.ParseEdPublicKeyFromPEM(publicPEM)
+		}
+	default:
+		return nil, fmt.Errorf("jwtx: signing method %q is not asymmetric", method)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwtx: invalid key %q: %w", id, err)
+	}
+
+	if key.PrivateKey != nil {
+		key.PublicKey = key.PrivateKey.Public()
+	} else if key.PublicKey == nil {
+		return nil, fmt.Errorf("jwtx: key %q has neither private key nor public key", id)
+	}
+	return key, nil
+}
+
+// JSONWebKey is the public part of a key in JWK format (RFC 7517).
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+func (k *Key) JWK() JSONWebKey {
+	jwk := JSONWebKey{
+		Kid: k.ID,
+		Use: "sig",
+		Alg: k.Method.Alg(),
+	}
+
+	enc := base64.RawURLEncoding.EncodeToString
+	switch pub := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = enc(pub.N.Bytes())
+		jwk.E = enc(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = enc(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = enc(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = enc(pub)
+	}
+	return jwk
+}
+
+// Parse the public key of the JWK, e.g. from the JWK Set of another issuer.
+func (k JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	dec := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "RSA":
+		n, err := dec(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := dec(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwtx: unsupported curve %q", k.Crv)
+		}
+		x, err := dec(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := dec(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwtx: unsupported curve %q", k.Crv)
+		}
+		x, err := dec(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("jwtx: invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("jwtx: unsupported key type %q", k.Kty)
+}
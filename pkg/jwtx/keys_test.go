@@ -0,0 +1,136 @@
+package jwtx
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func genKeyPEM(t *testing.T, method string) (privatePEM, publicPEM []byte) {
+	var priv crypto.Signer
+	var err error
+	switch method {
+	case "RS256", "PS256":
+		priv, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "EdDSA":
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+func TestAsymmetricKeys(t *testing.T) {
+	ctx := context.Background()
+
+	for _, method := range []string{"RS256", "PS256", "ES256", "EdDSA"} {
+		privatePEM, _ := genKeyPEM(t, method)
+		key, err := ParseKey("k1", method, privatePEM, nil)
+		assert.Nil(t, err, method)
+
+		jwtAuth := New(NewStoreWithCache(NewMemoryCache(MemoryConfig{CleanupInterval: time.Second})), SetKeys(key))
+		token, err := jwtAuth.GenerateToken(ctx, "test")
+		assert.Nil(t, err, method)
+
+		parsed, _, err := new(jwt.Parser).ParseUnverified(token.GetAccessToken(), &jwt.StandardClaims{})
+		assert.Nil(t, err, method)
+		assert.Equal(t, method, parsed.Header["alg"])
+		assert.Equal(t, "k1", parsed.Header["kid"])
+
+		id, err := jwtAuth.ParseSubject(ctx, token.GetAccessToken())
+		assert.Nil(t, err, method)
+		assert.Equal(t, "test", id)
+
+		jwks := jwtAuth.JWKS()
+		if assert.Len(t, jwks.Keys, 1, method) {
+			assert.Equal(t, "k1", jwks.Keys[0].Kid)
+			assert.Equal(t, method, jwks.Keys[0].Alg)
+			assert.NotEmpty(t, jwks.Keys[0].Kty)
+
+			pub, err := jwks.Keys[0].PublicKey()
+			assert.Nil(t, err, method)
+			assert.True(t, key.PublicKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(pub), method)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	store := NewStoreWithCache(NewMemoryCache(MemoryConfig{CleanupInterval: time.Second}))
+
+	oldPrivatePEM, oldPublicPEM := genKeyPEM(t, "ES256")
+	oldKey, err := ParseKey("old", "ES256", oldPrivatePEM, nil)
+	assert.Nil(t, err)
+
+	hmacAuth := New(store, SetSigningKey("secret", ""))
+	hmacToken, err := hmacAuth.GenerateToken(ctx, "test")
+	assert.Nil(t, err)
+
+	oldAuth := New(store, SetKeys(oldKey))
+	oldToken, err := oldAuth.GenerateToken(ctx, "test")
+	assert.Nil(t, err)
+
+	// Tokens without kid are rejected unless the secret key is still accepted
+	_, err = oldAuth.ParseSubject(ctx, hmacToken.GetAccessToken())
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+
+	newPrivatePEM, _ := genKeyPEM(t, "EdDSA")
+	newKey, err := ParseKey("new", "EdDSA", newPrivatePEM, nil)
+	assert.Nil(t, err)
+	retiredKey, err := ParseKey("old", "ES256", nil, oldPublicPEM)
+	assert.Nil(t, err)
+	assert.Nil(t, retiredKey.PrivateKey)
+
+	newAuth := New(store, SetKeys(retiredKey, newKey), SetSigningKey("secret", ""))
+	assert.Len(t, newAuth.JWKS().Keys, 2)
+
+	id, err := newAuth.ParseSubject(ctx, oldToken.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, "test", id)
+
+	id, err = newAuth.ParseSubject(ctx, hmacToken.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, "test", id)
+
+	newToken, err := newAuth.GenerateToken(ctx, "test")
+	assert.Nil(t, err)
+	parsed, _, err := new(jwt.Parser).ParseUnverified(newToken.GetAccessToken(), &jwt.StandardClaims{})
+	assert.Nil(t, err)
+	assert.Equal(t, "new", parsed.Header["kid"])
+
+	// Tokens of removed keys are rejected
+	_, err = oldAuth.ParseSubject(ctx, newToken.GetAccessToken())
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+
+	// A kid must not be verified with another algorithm, e.g. HMAC with the public key as secret
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{Subject: "root", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	forged.Header["kid"] = "old"
+	forgedStr, err := forged.SignedString(oldPublicPEM)
+	assert.Nil(t, err)
+	_, err = newAuth.ParseSubject(ctx, forgedStr)
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+}
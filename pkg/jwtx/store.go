@@ -10,6 +10,14 @@ type Storer interface {
 	Set(ctx context.Context, tokenStr string, expiration time.Duration) error
 	Delete(ctx context.Context, tokenStr string) error
 	Check(ctx context.Context, tokenStr string) (bool, error)
+	// Save the state of a new refresh token by its ID, the value is opaque to the store.
+	SetRefreshToken(ctx context.Context, id, value string, expiration time.Duration) error
+	GetRefreshToken(ctx context.Context, id string) (string, bool, error)
+	// Claim the refresh token for its single use, only the first of the concurrent claims gets true.
+	ClaimRefreshToken(ctx context.Context, id string) (bool, error)
+	// Mark a token family as revoked, the tokens of the family are rejected until the mark expires.
+	RevokeFamily(ctx context.Context, family string, expiration time.Duration) error
+	IsFamilyRevoked(ctx context.Context, family string) (bool, error)
 	Close(ctx context.Context) error
 }
 
@@ -28,6 +36,8 @@ func WithCacheNS(ns string) StoreOption {
 type Cacher interface {
 	Set(ctx context.Context, ns, key, value string, expiration ...time.Duration) error
 	Get(ctx context.Context, ns, key string) (string, bool, error)
+	// GetAndDelete must be atomic, it is used to claim the refresh tokens.
+	GetAndDelete(ctx context.Context, ns, key string) (string, bool, error)
 	Exists(ctx context.Context, ns, key string) (bool, error)
 	Delete(ctx context.Context, ns, key string) error
 	Close(ctx context.Context) error
@@ -63,6 +73,31 @@ func (s *storeImpl) Check(ctx context.Context, tokenStr string) (bool, error) {
 	return s.c.Exists(ctx, s.opts.CacheNS, tokenStr)
 }
 
+// The state is kept after the token is claimed to detect its reuse, the unclaimed mark is taken by the claim.
+func (s *storeImpl) SetRefreshToken(ctx context.Context, id, value string, expiration time.Duration) error {
+	if err := s.c.Set(ctx, s.opts.CacheNS, "refresh:"+id, value, expiration); err != nil {
+		return err
+	}
+	return s.c.Set(ctx, s.opts.CacheNS, "unclaimed:"+id, "", expiration)
+}
+
+func (s *storeImpl) GetRefreshToken(ctx context.Context, id string) (string, bool, error) {
+	return s.c.Get(ctx, s.opts.CacheNS, "refresh:"+id)
+}
+
+func (s *storeImpl) ClaimRefreshToken(ctx context.Context, id string) (bool, error) {
+	_, ok, err := s.c.GetAndDelete(ctx, s.opts.CacheNS, "unclaimed:"+id)
+	return ok, err
+}
+
+func (s *storeImpl) RevokeFamily(ctx context.Context, family string, expiration time.Duration) error {
+	return s.c.Set(ctx, s.opts.CacheNS, "family:"+family, "", expiration)
+}
+
+func (s *storeImpl) IsFamilyRevoked(ctx context.Context, family string) (bool, error) {
+	return s.c.Exists(ctx, s.opts.CacheNS, "family:"+family)
+}
+
 func (s *storeImpl) Close(ctx context.Context) error {
 	return s.c.Close(ctx)
 }
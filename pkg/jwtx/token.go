@@ -8,13 +8,19 @@ type TokenInfo interface {
 	GetAccessToken() string
 	GetTokenType() string
 	GetExpiresAt() int64
+	GetRefreshToken() string
+	GetRefreshExpiresAt() int64
+	GetSessionID() string
 	EncodeToJSON() ([]byte, error)
 }
 
 type tokenInfo struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresAt   int64  `json:"expires_at"`
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresAt        int64  `json:"expires_at"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	RefreshExpiresAt int64  `json:"refresh_expires_at,omitempty"`
+	SessionID        string `json:"session_id,omitempty"`
 }
 
 func (t *tokenInfo) GetAccessToken() string {
@@ -29,6 +35,18 @@ func (t *tokenInfo) GetExpiresAt() int64 {
 	return t.ExpiresAt
 }
 
+func (t *tokenInfo) GetRefreshToken() string {
+	return t.RefreshToken
+}
+
+func (t *tokenInfo) GetRefreshExpiresAt() int64 {
+	return t.RefreshExpiresAt
+}
+
+func (t *tokenInfo) GetSessionID() string {
+	return t.SessionID
+}
+
 func (t *tokenInfo) EncodeToJSON() ([]byte, error) {
 	return jsoniter.Marshal(t)
 }
@@ -0,0 +1,141 @@
+// Package ldapx authenticates users against an LDAP directory (e.g. Active Directory).
+package ldapx
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	ErrUserNotFound       = errors.New("ldap: user not found")
+	ErrInvalidCredentials = errors.New("ldap: invalid credentials")
+)
+
+type Config struct {
+	URL                string // ldap://host:389 or ldaps://host:636
+	StartTLS           bool   // Upgrade ldap:// connections with StartTLS
+	InsecureSkipVerify bool   // Skip the verification of the server certificate
+	BindDN             string // Service account to search users, anonymous search if empty
+	BindPassword       string
+	BaseDN             string        // Base DN of the user search
+	UserFilter         string        // User search filter, %s is replaced with the escaped username, e.g. (mail=%s)
+	Timeout            time.Duration // Timeout of the connection and every request
+}
+
+// Entry of the authenticated user
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Return the first value of the attribute, attribute names are case insensitive.
+func (e *Entry) Get(name string) string {
+	if values := e.Values(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (e *Entry) Values(name string) []string {
+	for key, values := range e.Attributes {
+		if strings.EqualFold(key, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// Client of the directory, every authentication uses a new connection.
+type Client struct {
+	config Config
+}
+
+func NewClient(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Client{config: cfg}
+}
+
+func (c *Client) dial() (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.config.InsecureSkipVerify}
+	if host, _, err := net.SplitHostPort(strings.TrimPrefix(strings.TrimPrefix(c.config.URL, "ldaps://"), "ldap://")); err == nil {
+		tlsConfig.ServerName = host
+	}
+
+	conn, err := ldap.DialURL(c.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: c.config.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(c.config.Timeout)
+
+	if c.config.StartTLS && strings.HasPrefix(c.config.URL, "ldap://") {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// Search the user with the service account and bind as the user to verify the password,
+// the attributes of the user entry are returned.
+func (c *Client) Authenticate(username, password string, attributes ...string) (*Entry, error) {
+	// binding without password is an anonymous bind on most servers
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if c.config.BindDN != "" {
+		if err := conn.Bind(c.config.BindDN, c.config.BindPassword); err != nil {
+			return nil, fmt.Errorf("ldap: bind service account: %w", err)
+		}
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		c.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(c.config.Timeout/time.Second), false,
+		fmt.Sprintf(c.config.UserFilter, ldap.EscapeFilter(username)),
+		attributes, nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, ErrUserNotFound
+		} else if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, fmt.Errorf("ldap: user filter matches multiple entries")
+		}
+		return nil, fmt.Errorf("ldap: search user: %w", err)
+	} else if len(result.Entries) == 0 {
+		return nil, ErrUserNotFound
+	} else if len(result.Entries) > 1 {
+		return nil, fmt.Errorf("ldap: user filter matches multiple entries")
+	}
+
+	item := result.Entries[0]
+	if err := conn.Bind(item.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap: bind user: %w", err)
+	}
+
+	entry := &Entry{DN: item.DN, Attributes: make(map[string][]string, len(item.Attributes))}
+	for _, attr := range item.Attributes {
+		entry.Attributes[attr.Name] = attr.Values
+	}
+	return entry, nil
+}
@@ -0,0 +1,58 @@
+package ldapx_test
+
+import (
+	"testing"
+
+	"go-admin/pkg/ldapx"
+	"go-admin/pkg/ldapx/ldaptest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	server := ldaptest.NewServer(
+		&ldaptest.Entry{DN: "cn=admin,dc=example,dc=com", Password: "admin-secret"},
+		&ldaptest.Entry{
+			DN:       "uid=alice,ou=people,dc=example,dc=com",
+			Password: "alice-secret",
+			Attributes: map[string][]string{
+				"objectClass": {"person"},
+				"mail":        {"alice@example.com"},
+				"givenName":   {"Alice"},
+				"memberOf":    {"cn=admins,ou=groups,dc=example,dc=com", "cn=users,ou=groups,dc=example,dc=com"},
+			},
+		},
+	)
+	defer server.Close()
+
+	client := ldapx.NewClient(ldapx.Config{
+		URL:          server.URL,
+		BindDN:       "cn=admin,dc=example,dc=com",
+		BindPassword: "admin-secret",
+		BaseDN:       "ou=people,dc=example,dc=com",
+		UserFilter:   "(&(objectClass=person)(mail=%s))",
+	})
+
+	entry, err := client.Authenticate("alice@example.com", "alice-secret", "mail", "memberOf")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "uid=alice,ou=people,dc=example,dc=com", entry.DN)
+		assert.Equal(t, "alice@example.com", entry.Get("MAIL"))
+		assert.Len(t, entry.Values("memberOf"), 2)
+		assert.Empty(t, entry.Get("givenName"))
+	}
+
+	_, err = client.Authenticate("alice@example.com", "wrong")
+	assert.ErrorIs(t, err, ldapx.ErrInvalidCredentials)
+	_, err = client.Authenticate("alice@example.com", "")
+	assert.ErrorIs(t, err, ldapx.ErrInvalidCredentials)
+	_, err = client.Authenticate("bob@example.com", "alice-secret")
+	assert.ErrorIs(t, err, ldapx.ErrUserNotFound)
+
+	// The username is escaped in the filter
+	_, err = client.Authenticate("*", "alice-secret")
+	assert.ErrorIs(t, err, ldapx.ErrUserNotFound)
+
+	_, err = ldapx.NewClient(ldapx.Config{URL: server.URL, BindDN: "cn=admin,dc=example,dc=com", BindPassword: "wrong",
+		BaseDN: "dc=example,dc=com", UserFilter: "(mail=%s)"}).Authenticate("alice@example.com", "alice-secret")
+	assert.NotNil(t, err)
+}
@@ -9,15 +9,16 @@ import (
 )
 
 type Logger struct {
-	ID        string    `gorm:"size:20;primaryKey;" json:"id"`  // Unique ID
-	Level     string    `gorm:"size:20;index;" json:"level"`    // Log level
-	TraceID   string    `gorm:"size:64;index;" json:"trace_id"` // Trace ID
-	UserID    string    `gorm:"size:20;index;" json:"user_id"`  // User ID
-	Tag       string    `gorm:"size:32;index;" json:"tag"`      // Log tag
-	Message   string    `gorm:"size:1024;" json:"message"`      // Log message
-	Stack     string    `gorm:"type:text;" json:"stack"`        // Error stack
-	Data      string    `gorm:"type:text;" json:"data"`         // Log data
-	CreatedAt time.Time `gorm:"index;" json:"created_at"`       // Create time
+	ID        string    `gorm:"size:20;primaryKey;" json:"id"`   // Unique ID
+	TenantID  string    `gorm:"size:20;index;" json:"tenant_id"` // Tenant ID
+	Level     string    `gorm:"size:20;index;" json:"level"`     // Log level
+	TraceID   string    `gorm:"size:64;index;" json:"trace_id"`  // Trace ID
+	UserID    string    `gorm:"size:20;index;" json:"user_id"`   // User ID
+	Tag       string    `gorm:"size:32;index;" json:"tag"`       // Log tag
+	Message   string    `gorm:"size:1024;" json:"message"`       // Log message
+	Stack     string    `gorm:"type:text;" json:"stack"`         // Error stack
+	Data      string    `gorm:"type:text;" json:"data"`          // Log data
+	CreatedAt time.Time `gorm:"index;" json:"created_at"`        // Create time
 }
 
 func NewGormHook(db *gorm.DB) *GormHook {
@@ -66,6 +67,10 @@ func (h *GormHook) Exec(extra map[string]string, b []byte) error {
 		msg.UserID = v.(string)
 		delete(data, "user_id")
 	}
+	if v, ok := data["tenant_id"]; ok {
+		msg.TenantID = v.(string)
+		delete(data, "tenant_id")
+	}
 	if v, ok := data["level"]; ok {
 		msg.Level = v.(string)
 		delete(data, "level")
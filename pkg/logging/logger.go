@@ -8,22 +8,27 @@ import (
 )
 
 const (
-	TagKeyMain     = "main"
-	TagKeyRecovery = "recovery"
-	TagKeyRequest  = "request"
-	TagKeyLogin    = "login"
-	TagKeyRegister = "register"
-	TagKeyLogout   = "logout"
-	TagKeySystem   = "system"
-	TagKeyOperate  = "operate"
+	TagKeyMain        = "main"
+	TagKeyRecovery    = "recovery"
+	TagKeyRequest     = "request"
+	TagKeyLogin       = "login"
+	TagKeyRegister    = "register"
+	TagKeyLogout      = "logout"
+	TagKeySystem      = "system"
+	TagKeyOperate     = "operate"
+	TagKeyResetPwd    = "reset_password"
+	TagKeyMFA         = "mfa"
+	TagKeyLockout     = "lockout"
+	TagKeyImpersonate = "impersonate"
 )
 
 type (
-	ctxLoggerKey  struct{}
-	ctxTraceIDKey struct{}
-	ctxUserIDKey  struct{}
-	ctxTagKey     struct{}
-	ctxStackKey   struct{}
+	ctxLoggerKey   struct{}
+	ctxTraceIDKey  struct{}
+	ctxUserIDKey   struct{}
+	ctxTenantIDKey struct{}
+	ctxTagKey      struct{}
+	ctxStackKey    struct{}
 )
 
 func NewLogger(ctx context.Context, logger *zap.Logger) context.Context {
@@ -68,6 +73,20 @@ func FromUserID(ctx context.Context) string {
 	return ""
 }
 
+func NewTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, ctxTenantIDKey{}, tenantID)
+}
+
+func FromTenantID(ctx context.Context) string {
+	v := ctx.Value(ctxTenantIDKey{})
+	if v != nil {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
 func NewTag(ctx context.Context, tag string) context.Context {
 	return context.WithValue(ctx, ctxTagKey{}, tag)
 }
@@ -104,6 +123,9 @@ func Context(ctx context.Context) *zap.Logger {
 	if v := FromUserID(ctx); v != "" {
 		fields = append(fields, zap.String("user_id", v))
 	}
+	if v := FromTenantID(ctx); v != "" {
+		fields = append(fields, zap.String("tenant_id", v))
+	}
 	if v := FromTag(ctx); v != "" {
 		fields = append(fields, zap.String("tag", v))
 	}
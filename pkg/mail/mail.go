@@ -2,6 +2,7 @@ package mail
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -13,6 +14,9 @@ var (
 	once         sync.Once
 )
 
+// ErrSenderNotSet is returned when sending without a global sender.
+var ErrSenderNotSet = errors.New("mail: global sender is not set")
+
 // Set a global SMTP sender
 func SetSender(sender *SmtpSender) {
 	once.Do(func() {
@@ -22,11 +26,17 @@ func SetSender(sender *SmtpSender) {
 
 // Use smtp client send email with to/cc/bcc
 func Send(ctx context.Context, to []string, cc []string, bcc []string, subject string, body string, file ...string) error {
+	if globalSender == nil {
+		return ErrSenderNotSet
+	}
 	return globalSender.Send(ctx, to, cc, bcc, subject, body, file...)
 }
 
 // Use smtp client send email, use to specify recipients
 func SendTo(ctx context.Context, to []string, subject string, body string, file ...string) error {
+	if globalSender == nil {
+		return ErrSenderNotSet
+	}
 	return globalSender.SendTo(ctx, to, subject, body, file...)
 }
 
@@ -34,7 +34,12 @@ func AuthWithConfig(config AuthConfig) gin.HandlerFunc {
 		ctx = logging.NewUserID(ctx, userID)
 		if userID == config.RootID {
 			ctx = util.NewIsRootUser(ctx)
+		} else {
+			// the tenant header is only honored for root, the others are scoped to their own tenant
+			ctx = util.NewTenantID(ctx, util.FromUserCache(ctx).TenantID)
 		}
+		tenantID, _ := util.FromTenantID(ctx)
+		ctx = logging.NewTenantID(ctx, tenantID)
 		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
@@ -14,8 +14,9 @@ type CasbinConfig struct {
 	AllowedPathPrefixes []string
 	SkippedPathPrefixes []string
 	Skipper             func(c *gin.Context) bool
-	GetEnforcer         func(c *gin.Context) *casbin.Enforcer
+	GetEnforcer         func(c *gin.Context) *casbin.SyncedEnforcer
 	GetSubjects         func(c *gin.Context) []string
+	GetDomain           func(c *gin.Context) string // The model has a domain (r = sub, dom, obj, act) if set
 }
 
 func CasbinWithConfig(config CasbinConfig) gin.HandlerFunc {
@@ -34,7 +35,11 @@ func CasbinWithConfig(config CasbinConfig) gin.HandlerFunc {
 		}
 
 		for _, sub := range config.GetSubjects(c) {
-			if b, err := enforcer.Enforce(sub, c.Request.URL.Path, c.Request.Method); err != nil {
+			rvals := []interface{}{sub, c.Request.URL.Path, c.Request.Method}
+			if config.GetDomain != nil {
+				rvals = []interface{}{sub, config.GetDomain(c), c.Request.URL.Path, c.Request.Method}
+			}
+			if b, err := enforcer.Enforce(rvals...); err != nil {
 				util.ResError(c, err)
 				return
 			} else if b {
@@ -81,8 +81,14 @@ func LoggerWithConfig(config LoggerConfig) gin.HandlerFunc {
 			}
 		}
 
+		// requests of impersonated users are logged separately to audit the impersonators
 		ctx := c.Request.Context()
-		ctx = logging.NewTag(ctx, logging.TagKeyRequest)
+		if impersonatorID := util.FromImpersonatorID(ctx); impersonatorID != "" {
+			ctx = logging.NewTag(ctx, logging.TagKeyImpersonate)
+			fields = append(fields, zap.String("impersonator_id", impersonatorID))
+		} else {
+			ctx = logging.NewTag(ctx, logging.TagKeyRequest)
+		}
 		logging.Context(ctx).Info(fmt.Sprintf("[HTTP] %s-%s-%d (%dms)",
 			c.Request.URL.Path, c.Request.Method, c.Writer.Status(), cost), fields...)
 	}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"go-admin/pkg/logging"
+	"go-admin/pkg/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantConfig struct {
+	AllowedPathPrefixes []string
+	SkippedPathPrefixes []string
+	HeaderKey           string
+}
+
+var DefaultTenantConfig = TenantConfig{
+	HeaderKey: "X-Tenant-ID",
+}
+
+func Tenant() gin.HandlerFunc {
+	return TenantWithConfig(DefaultTenantConfig)
+}
+
+// Scope the request to the tenant of the header, it is only honored for root and the routes without authentication,
+// the auth middleware overwrites it with the tenant of the authenticated user.
+func TenantWithConfig(config TenantConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !AllowedPathPrefixes(c, config.AllowedPathPrefixes...) ||
+			SkippedPathPrefixes(c, config.SkippedPathPrefixes...) {
+			c.Next()
+			return
+		}
+
+		if tenantID := c.GetHeader(config.HeaderKey); tenantID != "" {
+			ctx := util.NewTenantID(c.Request.Context(), tenantID)
+			ctx = logging.NewTenantID(ctx, tenantID)
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,80 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"go-admin/pkg/oidc"
+	"go-admin/pkg/oidc/oidctest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+	server.SetUser(oidctest.User{Subject: "1001", Email: "sso@example.com", EmailVerified: true, GivenName: "Single", FamilyName: "Sign-On"})
+
+	provider, err := oidc.NewProvider(ctx, oidc.Config{
+		Issuer:       server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/callback",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The provider redirects back with the code, don't follow it
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	authorize := func(state, nonce, verifier string) url.Values {
+		resp, err := client.Get(provider.AuthCodeURL(state, nonce, verifier))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusFound, resp.StatusCode)
+
+		location, err := url.Parse(resp.Header.Get("Location"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return location.Query()
+	}
+
+	verifier := oidc.NewCodeVerifier()
+	query := authorize("state", "nonce", verifier)
+	assert.Equal(t, "state", query.Get("state"))
+
+	// The code verifier must match the code challenge
+	_, err = provider.Exchange(ctx, query.Get("code"), oidc.NewCodeVerifier())
+	assert.NotNil(t, err)
+
+	query = authorize("state", "nonce", verifier)
+	token, err := provider.Exchange(ctx, query.Get("code"), verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = provider.VerifyIDToken(ctx, token.IDToken, "other-nonce")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, "nonce")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "1001", claims.Subject)
+		assert.Equal(t, "sso@example.com", claims.Email)
+		assert.True(t, claims.EmailVerified)
+		assert.Equal(t, "Single", claims.GivenName)
+	}
+
+	// A token of another client is rejected
+	other, err := oidc.NewProvider(ctx, oidc.Config{Issuer: server.URL, ClientID: "other", RedirectURL: "http://localhost/callback"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = other.VerifyIDToken(ctx, token.IDToken, "nonce")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+}
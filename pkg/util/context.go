@@ -9,13 +9,17 @@ import (
 )
 
 type (
-	traceIDCtx    struct{}
-	transCtx      struct{}
-	rowLockCtx    struct{}
-	userIDCtx     struct{}
-	userTokenCtx  struct{}
-	isRootUserCtx struct{}
-	userCacheCtx  struct{}
+	traceIDCtx        struct{}
+	transCtx          struct{}
+	rowLockCtx        struct{}
+	userIDCtx         struct{}
+	userTokenCtx      struct{}
+	isRootUserCtx     struct{}
+	userCacheCtx      struct{}
+	clientCtx         struct{}
+	apiKeyIDCtx       struct{}
+	impersonatorIDCtx struct{}
+	tenantIDCtx       struct{}
 )
 
 func NewTraceID(ctx context.Context, traceID string) context.Context {
@@ -75,6 +79,50 @@ func FromUserToken(ctx context.Context) string {
 	return ""
 }
 
+// Set the ID of the API key that authenticated the request
+func NewAPIKeyID(ctx context.Context, keyID string) context.Context {
+	return context.WithValue(ctx, apiKeyIDCtx{}, keyID)
+}
+
+func FromAPIKeyID(ctx context.Context) string {
+	v := ctx.Value(apiKeyIDCtx{})
+	if v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+// Set the ID of the user impersonating the current user
+func NewImpersonatorID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, impersonatorIDCtx{}, userID)
+}
+
+func FromImpersonatorID(ctx context.Context) string {
+	v := ctx.Value(impersonatorIDCtx{})
+	if v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+// Scope the data of the context to the tenant, the empty tenant ID is the default tenant
+func NewTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantIDCtx{}, &tenantID)
+}
+
+// Lift the tenant scope of the context, the data of all tenants is accessible (e.g. for root users)
+func NewCrossTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantIDCtx{}, (*string)(nil))
+}
+
+// Get the tenant the data of the context is scoped to, `ok` is false if it is not scoped
+func FromTenantID(ctx context.Context) (tenantID string, ok bool) {
+	if v, _ := ctx.Value(tenantIDCtx{}).(*string); v != nil {
+		return *v, true
+	}
+	return "", false
+}
+
 func NewIsRootUser(ctx context.Context) context.Context {
 	return context.WithValue(ctx, isRootUserCtx{}, true)
 }
@@ -86,7 +134,15 @@ func FromIsRootUser(ctx context.Context) bool {
 
 // Set user cache object
 type UserCache struct {
-	RoleIDs []string `json:"rids"`
+	RoleIDs   []string   `json:"rids"`
+	TenantID  string     `json:"tid,omitempty"` // Tenant of the user, empty for the default tenant
+	DataScope *DataScope `json:"ds,omitempty"`  // Rows the user may see, all rows if nil
+}
+
+// Rows the user may see, derived from the data scopes of its roles
+type DataScope struct {
+	UserIDs       []string `json:"uids"`           // Rows owned by these users
+	DepartmentIDs []string `json:"dids,omitempty"` // Rows owned by the members of these departments and their sub-departments
 }
 
 func ParseUserCache(s string) UserCache {
@@ -114,3 +170,21 @@ func FromUserCache(ctx context.Context) UserCache {
 	}
 	return UserCache{}
 }
+
+// Client information of the request
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+func NewClientInfo(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, clientCtx{}, client)
+}
+
+func FromClientInfo(ctx context.Context) ClientInfo {
+	v := ctx.Value(clientCtx{})
+	if v != nil {
+		return v.(ClientInfo)
+	}
+	return ClientInfo{}
+}
@@ -31,7 +31,31 @@ func GetDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
 	if FromRowLock(ctx) {
 		db = db.Clauses(clause.Locking{Strength: "UPDATE"})
 	}
-	return db.WithContext(ctx)
+	db = db.WithContext(ctx)
+	if tenantID, ok := FromTenantID(ctx); ok {
+		db = db.Set(tenantIDSettingKey, tenantID)
+	}
+	return db
+}
+
+// Restrict the query to the rows the current user may see according to the data scope of its user cache,
+// `column` is the column with the ID of the user owning the row. Root users and contexts without a data scope see all rows.
+// `departmentUsers` builds the subquery of the IDs of the members of the departments and their sub-departments,
+// without it the departments of the data scope are ignored.
+func DataScopeFunc(ctx context.Context, column string, departmentUsers ...func(departmentIDs []string) *gorm.DB) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if FromIsRootUser(ctx) {
+			return db
+		}
+		scope := FromUserCache(ctx).DataScope
+		if scope == nil {
+			return db
+		}
+		if len(scope.DepartmentIDs) > 0 && len(departmentUsers) > 0 {
+			return db.Where(column+" IN ? OR "+column+" IN (?)", scope.UserIDs, departmentUsers[0](scope.DepartmentIDs))
+		}
+		return db.Where(column+" IN ?", scope.UserIDs)
+	}
 }
 
 func wrapQueryOptions(db *gorm.DB, opts QueryOptions) *gorm.DB {
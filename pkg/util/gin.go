@@ -1,9 +1,12 @@
 package util
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"go-admin/pkg/encoding/json"
@@ -34,6 +37,28 @@ func GetToken(c *gin.Context) string {
 	return token
 }
 
+// Get API key from header `X-API-Key` or `Authorization: ApiKey {key}`
+func GetAPIKey(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+
+	auth := c.GetHeader("Authorization")
+	prefix := "ApiKey "
+	if strings.HasPrefix(auth, prefix) {
+		return auth[len(prefix):]
+	}
+	return ""
+}
+
+// Get the request context carrying the client IP and user agent
+func GetClientContext(c *gin.Context) context.Context {
+	return NewClientInfo(c.Request.Context(), ClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
+
 // Get body data from context
 func GetBodyData(c *gin.Context) []byte {
 	if v, ok := c.Get(ReqBodyKey); ok {
@@ -133,6 +158,10 @@ func ResError(c *gin.Context, err error, status ...int) {
 		ierr.Detail = http.StatusText(http.StatusInternalServerError)
 	}
 
+	if d, ok := errors.RetryAfter(err); ok {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+	}
+
 	ierr.Code = int32(code)
 	ResJSON(c, code, ResponseResult{Error: ierr})
 }
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The most common passwords of public breach lists, always banned by PasswordPolicy
+var commonPasswords = []string{
+	"123456", "123456789", "12345678", "1234567890", "12345", "1234567", "123123", "111111", "000000", "654321",
+	"password", "password1", "password123", "passw0rd", "p@ssw0rd", "p@ssword", "qwerty", "qwerty123", "qwertyuiop", "1q2w3e4r",
+	"1qaz2wsx", "zaq12wsx", "abc123", "abcd1234", "a1b2c3d4", "iloveyou", "admin", "admin123", "administrator", "root",
+	"welcome", "welcome1", "letmein", "monkey", "dragon", "sunshine", "princess", "football", "baseball", "superman",
+	"trustno1", "master", "shadow", "michael", "secret", "changeme", "default", "login", "starwars", "whatever",
+}
+
+// Password policy, zero values disable the corresponding rules.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	Banned        map[string]struct{} // Banned passwords in lower case, in addition to the common passwords
+}
+
+// Add banned passwords, they are compared case-insensitively.
+func (p *PasswordPolicy) Ban(passwords ...string) {
+	if p.Banned == nil {
+		p.Banned = make(map[string]struct{}, len(passwords))
+	}
+	for _, password := range passwords {
+		if password = strings.TrimSpace(password); password != "" {
+			p.Banned[strings.ToLower(password)] = struct{}{}
+		}
+	}
+}
+
+func (p *PasswordPolicy) isBanned(password string) bool {
+	password = strings.ToLower(password)
+	if _, ok := p.Banned[password]; ok {
+		return true
+	}
+	for _, item := range commonPasswords {
+		if item == password {
+			return true
+		}
+	}
+	return false
+}
+
+// Check the password against the policy and return the violated rules, e.g. "be at least 8 characters long".
+// The email of the user (and its local part) can't be used as password.
+func (p *PasswordPolicy) Check(password, email string) []string {
+	var violations []string
+	if p.MinLength > 0 && len([]rune(password)) < p.MinLength {
+		violations = append(violations, "be at least "+strconv.Itoa(p.MinLength)+" characters long")
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		violations = append(violations, "contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		violations = append(violations, "contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, "contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, "contain a symbol")
+	}
+
+	if p.isBanned(password) {
+		violations = append(violations, "not be a commonly used password")
+	}
+	if email != "" {
+		local, _, _ := strings.Cut(email, "@")
+		if strings.EqualFold(password, email) || strings.EqualFold(password, local) {
+			violations = append(violations, "not be the same as the email")
+		}
+	}
+	return violations
+}
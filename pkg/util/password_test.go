@@ -0,0 +1,30 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := &PasswordPolicy{
+		MinLength:     8,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+	policy.Ban("Go-Admin-2024!")
+
+	assert.Empty(t, policy.Check("Correct-Horse-9", "user@example.com"))
+	assert.Equal(t, []string{"be at least 8 characters long", "contain an uppercase letter", "contain a digit", "contain a symbol"}, policy.Check("abc", ""))
+	assert.Equal(t, []string{"contain an uppercase letter", "contain a digit", "contain a symbol", "not be a commonly used password"}, policy.Check("password", ""))
+	assert.Equal(t, []string{"not be a commonly used password"}, policy.Check("go-Admin-2024!", ""))
+	assert.Contains(t, policy.Check("User@Example.com1", "user@example.com1"), "not be the same as the email")
+	assert.Contains(t, policy.Check("JOHN.doe", "john.doe@example.com"), "not be the same as the email")
+
+	// Only the common passwords are banned by a zero policy
+	var zero PasswordPolicy
+	assert.Empty(t, zero.Check("x", ""))
+	assert.Equal(t, []string{"not be a commonly used password"}, zero.Check("QWERTY", ""))
+}
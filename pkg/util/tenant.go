@@ -0,0 +1,99 @@
+package util
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	tenantIDSettingKey = "tenant:id"
+	tenantIDColumn     = "tenant_id"
+	// Tag of the tenant field, the rows of the default tenant are readable by all tenants if set to `shared`, e.g. `tenant:"shared"`
+	tenantTagKey = "tenant"
+)
+
+// A gorm plugin that isolates the rows of the models with a `tenant_id` column, the statements built by GetDB
+// are restricted to the tenant of the context and the created rows are assigned to it.
+type TenantPlugin struct{}
+
+var _ gorm.Plugin = TenantPlugin{}
+
+func (TenantPlugin) Name() string {
+	return "tenant"
+}
+
+func (TenantPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("tenant:create", tenantCreate); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tenant:query", tenantQuery); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tenant:row", tenantQuery); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:update", tenantWrite); err != nil {
+		return err
+	}
+	return cb.Delete().Before("gorm:delete").Register("tenant:delete", tenantWrite)
+}
+
+func tenantField(db *gorm.DB) (*schema.Field, string, bool) {
+	v, ok := db.Get(tenantIDSettingKey)
+	if !ok || db.Error != nil || db.Statement.Schema == nil {
+		return nil, "", false
+	}
+	field := db.Statement.Schema.LookUpField(tenantIDColumn)
+	if field == nil {
+		return nil, "", false
+	}
+	return field, v.(string), true
+}
+
+func tenantCreate(db *gorm.DB) {
+	field, tenantID, ok := tenantField(db)
+	if !ok {
+		return
+	}
+
+	ctx, rv := db.Statement.Context, db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			_ = db.AddError(field.Set(ctx, reflect.Indirect(rv.Index(i)), tenantID))
+		}
+	case reflect.Struct:
+		_ = db.AddError(field.Set(ctx, rv, tenantID))
+	}
+}
+
+func tenantQuery(db *gorm.DB) {
+	field, tenantID, ok := tenantField(db)
+	if !ok {
+		return
+	}
+
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+	if tenantID != "" && field.Tag.Get(tenantTagKey) == "shared" {
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+			clause.IN{Column: column, Values: []interface{}{tenantID, ""}},
+		}})
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Eq{Column: column, Value: tenantID}}})
+}
+
+// The shared rows are only writable in the default tenant
+func tenantWrite(db *gorm.DB) {
+	field, tenantID, ok := tenantField(db)
+	if !ok {
+		return
+	}
+
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Eq{Column: column, Value: tenantID}}})
+}
@@ -0,0 +1,479 @@
+// Package webauthn implements the registration and authentication ceremonies of WebAuthn relying parties.
+// Attestation statements are not verified (attestation conveyance "none"), the credentials are trusted on first use.
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ugorji/go/codec"
+)
+
+var (
+	ErrInvalidCredential = errors.New("webauthn: invalid credential")
+	ErrSignCount         = errors.New("webauthn: sign count did not increase, the authenticator may be cloned")
+)
+
+const (
+	PublicKeyType = "public-key"
+
+	UserVerificationRequired    = "required"
+	UserVerificationPreferred   = "preferred"
+	UserVerificationDiscouraged = "discouraged"
+
+	// COSE algorithm identifiers
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// Flags of the authenticator data
+const (
+	flagUserPresent      = 0x01
+	flagUserVerified     = 0x04
+	flagAttestedCredData = 0x40
+)
+
+type Config struct {
+	RPID             string        // Domain of the relying party, e.g. example.com
+	RPName           string        // Display name of the relying party
+	Origins          []string      // Allowed origins of the clients, e.g. https://admin.example.com
+	Timeout          time.Duration // Time for the user to complete the ceremony, a hint for the client
+	UserVerification string        // required/preferred/discouraged, default preferred
+}
+
+// Bytes encoded as base64url without padding in JSON, like the WebAuthn JSON serialization
+type URLEncodedBytes []byte
+
+func (b URLEncodedBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *URLEncodedBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserEntity struct {
+	ID          URLEncodedBytes `json:"id" swaggertype:"string"` // User handle, must not contain personal information
+	Name        string          `json:"name"`
+	DisplayName string          `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type       string          `json:"type"`
+	ID         URLEncodedBytes `json:"id" swaggertype:"string"`
+	Transports []string        `json:"transports,omitempty"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey,omitempty"`
+	UserVerification string `json:"userVerification,omitempty"`
+}
+
+// Options of navigator.credentials.create()
+type CreationOptions struct {
+	PublicKey PublicKeyCredentialCreationOptions `json:"publicKey"`
+}
+
+type PublicKeyCredentialCreationOptions struct {
+	Challenge              URLEncodedBytes        `json:"challenge" swaggertype:"string"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout,omitempty"` // milliseconds
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// Options of navigator.credentials.get()
+type RequestOptions struct {
+	PublicKey PublicKeyCredentialRequestOptions `json:"publicKey"`
+}
+
+type PublicKeyCredentialRequestOptions struct {
+	Challenge        URLEncodedBytes        `json:"challenge" swaggertype:"string"`
+	Timeout          int64                  `json:"timeout,omitempty"` // milliseconds
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification,omitempty"`
+}
+
+// The credential created by navigator.credentials.create()
+type AttestationResponse struct {
+	ID       string                           `json:"id"`
+	RawID    URLEncodedBytes                  `json:"rawId" swaggertype:"string"`
+	Type     string                           `json:"type"`
+	Response AuthenticatorAttestationResponse `json:"response"`
+}
+
+type AuthenticatorAttestationResponse struct {
+	ClientDataJSON    URLEncodedBytes `json:"clientDataJSON" swaggertype:"string"`
+	AttestationObject URLEncodedBytes `json:"attestationObject" swaggertype:"string"`
+	Transports        []string        `json:"transports,omitempty"`
+}
+
+// The assertion returned by navigator.credentials.get()
+type AssertionResponse struct {
+	ID       string                         `json:"id"`
+	RawID    URLEncodedBytes                `json:"rawId" swaggertype:"string"`
+	Type     string                         `json:"type"`
+	Response AuthenticatorAssertionResponse `json:"response"`
+}
+
+type AuthenticatorAssertionResponse struct {
+	ClientDataJSON    URLEncodedBytes `json:"clientDataJSON" swaggertype:"string"`
+	AuthenticatorData URLEncodedBytes `json:"authenticatorData" swaggertype:"string"`
+	Signature         URLEncodedBytes `json:"signature" swaggertype:"string"`
+	UserHandle        URLEncodedBytes `json:"userHandle,omitempty" swaggertype:"string"`
+}
+
+// A registered credential, the relying party stores it to verify the assertions
+type Credential struct {
+	ID         []byte
+	PublicKey  []byte // COSE_Key
+	AAGUID     []byte
+	SignCount  uint32
+	Transports []string
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type attestationObject struct {
+	Fmt      string                 `codec:"fmt"`
+	AttStmt  map[string]interface{} `codec:"attStmt"`
+	AuthData []byte                 `codec:"authData"`
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+type RelyingParty struct {
+	config Config
+}
+
+func New(cfg Config) *RelyingParty {
+	if cfg.UserVerification == "" {
+		cfg.UserVerification = UserVerificationPreferred
+	}
+	return &RelyingParty{config: cfg}
+}
+
+// Generate a random challenge for a ceremony, it must be kept by the relying party until the ceremony is finished.
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// Options to register a new credential of the user, the existing credentials are excluded.
+func (rp *RelyingParty) CreationOptions(challenge []byte, user UserEntity, exclude []CredentialDescriptor) *CreationOptions {
+	return &CreationOptions{PublicKey: PublicKeyCredentialCreationOptions{
+		Challenge: challenge,
+		RP:        RelyingPartyEntity{ID: rp.config.RPID, Name: rp.config.RPName},
+		User:      user,
+		PubKeyCredParams: []CredentialParameter{
+			{Type: PublicKeyType, Alg: AlgES256},
+			{Type: PublicKeyType, Alg: AlgEdDSA},
+			{Type: PublicKeyType, Alg: AlgRS256},
+		},
+		Timeout:            rp.config.Timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: rp.config.UserVerification,
+		},
+		Attestation: "none",
+	}}
+}
+
+// Verify the new credential created for the challenge.
+func (rp *RelyingParty) FinishRegistration(challenge []byte, resp *AttestationResponse) (*Credential, error) {
+	if resp.Type != PublicKeyType {
+		return nil, fmt.Errorf("%w: unsupported type %q", ErrInvalidCredential, resp.Type)
+	}
+	if err := rp.verifyClientData(resp.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	var obj attestationObject
+	if err := codec.NewDecoderBytes(resp.Response.AttestationObject, new(codec.CborHandle)).Decode(&obj); err != nil {
+		return nil, fmt.Errorf("%w: malformed attestation object: %s", ErrInvalidCredential, err)
+	}
+	authData, err := rp.verifyAuthenticatorData(obj.AuthData)
+	if err != nil {
+		return nil, err
+	} else if authData.flags&flagAttestedCredData == 0 {
+		return nil, fmt.Errorf("%w: no attested credential data", ErrInvalidCredential)
+	} else if !bytes.Equal(authData.credentialID, resp.RawID) {
+		return nil, fmt.Errorf("%w: credential id mismatch", ErrInvalidCredential)
+	} else if _, err := parsePublicKey(authData.publicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:         authData.credentialID,
+		PublicKey:  authData.publicKey,
+		AAGUID:     authData.aaguid,
+		SignCount:  authData.signCount,
+		Transports: resp.Response.Transports,
+	}, nil
+}
+
+// Options to authenticate with one of the allowed credentials, any discoverable credential if none is allowed.
+func (rp *RelyingParty) RequestOptions(challenge []byte, allow []CredentialDescriptor) *RequestOptions {
+	return &RequestOptions{PublicKey: PublicKeyCredentialRequestOptions{
+		Challenge:        challenge,
+		Timeout:          rp.config.Timeout.Milliseconds(),
+		RPID:             rp.config.RPID,
+		AllowCredentials: allow,
+		UserVerification: rp.config.UserVerification,
+	}}
+}
+
+// Verify the assertion of the credential for the challenge and return the new sign count of the credential.
+func (rp *RelyingParty) FinishLogin(challenge []byte, cred *Credential, resp *AssertionResponse) (uint32, error) {
+	if resp.Type != PublicKeyType {
+		return 0, fmt.Errorf("%w: unsupported type %q", ErrInvalidCredential, resp.Type)
+	} else if !bytes.Equal(cred.ID, resp.RawID) {
+		return 0, fmt.Errorf("%w: credential id mismatch", ErrInvalidCredential)
+	}
+	if err := rp.verifyClientData(resp.Response.ClientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+	authData, err := rp.verifyAuthenticatorData(resp.Response.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	signed := append(append([]byte(nil), resp.Response.AuthenticatorData...), clientDataHash[:]...)
+	if err := verifySignature(cred.PublicKey, signed, resp.Response.Signature); err != nil {
+		return 0, err
+	}
+
+	// authenticators without a counter always return 0
+	if (authData.signCount != 0 || cred.SignCount != 0) && authData.signCount <= cred.SignCount {
+		return 0, ErrSignCount
+	}
+	return authData.signCount, nil
+}
+
+func (rp *RelyingParty) verifyClientData(data []byte, typ string, challenge []byte) error {
+	var cd clientData
+	if err := json.Unmarshal(data, &cd); err != nil {
+		return fmt.Errorf("%w: malformed client data: %s", ErrInvalidCredential, err)
+	} else if cd.Type != typ {
+		return fmt.Errorf("%w: unexpected client data type %q", ErrInvalidCredential, cd.Type)
+	}
+
+	c, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(cd.Challenge, "="))
+	if err != nil || subtle.ConstantTimeCompare(c, challenge) != 1 {
+		return fmt.Errorf("%w: challenge mismatch", ErrInvalidCredential)
+	}
+	for _, origin := range rp.config.Origins {
+		if cd.Origin == origin {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: origin %q is not allowed", ErrInvalidCredential, cd.Origin)
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(data []byte) (*authenticatorData, error) {
+	authData, err := parseAuthenticatorData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rp.config.RPID))
+	if !bytes.Equal(authData.rpIDHash, rpIDHash[:]) {
+		return nil, fmt.Errorf("%w: rp id mismatch", ErrInvalidCredential)
+	} else if authData.flags&flagUserPresent == 0 {
+		return nil, fmt.Errorf("%w: user is not present", ErrInvalidCredential)
+	} else if rp.config.UserVerification == UserVerificationRequired && authData.flags&flagUserVerified == 0 {
+		return nil, fmt.Errorf("%w: user is not verified", ErrInvalidCredential)
+	}
+	return authData, nil
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, fmt.Errorf("%w: authenticator data too short", ErrInvalidCredential)
+	}
+	authData := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if authData.flags&flagAttestedCredData == 0 {
+		return authData, nil
+	}
+
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, fmt.Errorf("%w: attested credential data too short", ErrInvalidCredential)
+	}
+	authData.aaguid = rest[:16]
+	idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < idLen {
+		return nil, fmt.Errorf("%w: credential id too short", ErrInvalidCredential)
+	}
+	authData.credentialID = rest[:idLen]
+	rest = rest[idLen:]
+
+	// the public key is followed by the extensions if any
+	var key map[int64]interface{}
+	dec := codec.NewDecoderBytes(rest, new(codec.CborHandle))
+	if err := dec.Decode(&key); err != nil {
+		return nil, fmt.Errorf("%w: malformed public key: %s", ErrInvalidCredential, err)
+	}
+	authData.publicKey = rest[:dec.NumBytesRead()]
+	return authData, nil
+}
+
+// COSE_Key parameters (RFC 9053)
+const (
+	coseKeyType = 1
+	coseAlg     = 3
+	coseCurve   = -1 // EC2 and OKP keys
+	coseX       = -2 // EC2 and OKP keys
+	coseY       = -3 // EC2 keys
+	coseN       = -1 // RSA keys
+	coseE       = -2 // RSA keys
+
+	coseKeyOKP  = 1
+	coseKeyEC2  = 2
+	coseKeyRSA  = 3
+	coseP256    = 1
+	coseEd25519 = 6
+
+	minRSAKeySize = 2048
+)
+
+type publicKey struct {
+	alg int64
+	key interface{}
+}
+
+func parsePublicKey(data []byte) (*publicKey, error) {
+	var m map[int64]interface{}
+	if err := codec.NewDecoderBytes(data, new(codec.CborHandle)).Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: malformed public key: %s", ErrInvalidCredential, err)
+	}
+
+	kty, _ := coseInt(m[coseKeyType])
+	alg, _ := coseInt(m[coseAlg])
+	switch {
+	case kty == coseKeyEC2 && alg == AlgES256:
+		crv, _ := coseInt(m[coseCurve])
+		x, _ := m[coseX].([]byte)
+		y, _ := m[coseY].([]byte)
+		if crv != coseP256 || len(x) != 32 || len(y) != 32 {
+			break
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			break
+		}
+		return &publicKey{alg: alg, key: key}, nil
+	case kty == coseKeyOKP && alg == AlgEdDSA:
+		crv, _ := coseInt(m[coseCurve])
+		x, _ := m[coseX].([]byte)
+		if crv != coseEd25519 || len(x) != ed25519.PublicKeySize {
+			break
+		}
+		return &publicKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case kty == coseKeyRSA && alg == AlgRS256:
+		n, _ := m[coseN].([]byte)
+		e, _ := m[coseE].([]byte)
+		if len(n)*8 < minRSAKeySize || len(e) == 0 || len(e) > 4 {
+			break
+		}
+		exp := 0
+		for _, b := range e {
+			exp = exp<<8 | int(b)
+		}
+		return &publicKey{alg: alg, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exp}}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported public key (kty %d, alg %d)", ErrInvalidCredential, kty, alg)
+}
+
+func verifySignature(coseKey, data, sig []byte) error {
+	pub, err := parsePublicKey(coseKey)
+	if err != nil {
+		return err
+	}
+
+	var ok bool
+	switch key := pub.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		ok = ecdsa.VerifyASN1(key, digest[:], sig)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, data, sig)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	}
+	if !ok {
+		return fmt.Errorf("%w: invalid signature", ErrInvalidCredential)
+	}
+	return nil
+}
+
+func coseInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), true
+	}
+	return 0, false
+}
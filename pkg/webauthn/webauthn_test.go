@@ -0,0 +1,114 @@
+package webauthn_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go-admin/pkg/webauthn"
+	"go-admin/pkg/webauthn/webauthntest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newChallenge(t *testing.T) []byte {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return challenge
+}
+
+func TestCeremonies(t *testing.T) {
+	rp := webauthn.New(webauthn.Config{
+		RPID:             "localhost",
+		RPName:           "Test",
+		Origins:          []string{"http://localhost:5001"},
+		Timeout:          time.Minute,
+		UserVerification: webauthn.UserVerificationRequired,
+	})
+	authenticator := webauthntest.NewAuthenticator("http://localhost:5001")
+
+	// Registration
+	challenge := newChallenge(t)
+	creationOptions := rp.CreationOptions(challenge, webauthn.UserEntity{ID: []byte("user-1"), Name: "user@example.com"}, nil)
+	assert.Equal(t, int64(60000), creationOptions.PublicKey.Timeout)
+	attestation, err := authenticator.Create(creationOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The responses are sent as JSON by the clients
+	data, err := json.Marshal(attestation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation = new(webauthn.AttestationResponse)
+	if err := json.Unmarshal(data, attestation); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = rp.FinishRegistration(newChallenge(t), attestation)
+	assert.ErrorIs(t, err, webauthn.ErrInvalidCredential)
+	cred, err := rp.FinishRegistration(challenge, attestation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []byte(attestation.RawID), cred.ID)
+	assert.Equal(t, uint32(0), cred.SignCount)
+
+	// Authentication
+	challenge = newChallenge(t)
+	assertion, err := authenticator.Get(rp.RequestOptions(challenge, []webauthn.CredentialDescriptor{
+		{Type: webauthn.PublicKeyType, ID: cred.ID},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []byte("user-1"), []byte(assertion.Response.UserHandle))
+
+	_, err = rp.FinishLogin(newChallenge(t), cred, assertion)
+	assert.ErrorIs(t, err, webauthn.ErrInvalidCredential)
+	signCount, err := rp.FinishLogin(challenge, cred, assertion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint32(1), signCount)
+	cred.SignCount = signCount
+
+	// A replayed assertion doesn't increase the sign count
+	_, err = rp.FinishLogin(challenge, cred, assertion)
+	assert.ErrorIs(t, err, webauthn.ErrSignCount)
+
+	// Tampered signatures are rejected
+	challenge = newChallenge(t)
+	assertion, err = authenticator.Get(rp.RequestOptions(challenge, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertion.Response.AuthenticatorData[len(assertion.Response.AuthenticatorData)-1]++
+	_, err = rp.FinishLogin(challenge, cred, assertion)
+	assert.ErrorIs(t, err, webauthn.ErrInvalidCredential)
+}
+
+func TestOriginAndRPID(t *testing.T) {
+	challenge := newChallenge(t)
+	rp := webauthn.New(webauthn.Config{RPID: "localhost", Origins: []string{"http://localhost:5001"}})
+	options := rp.CreationOptions(challenge, webauthn.UserEntity{ID: []byte("user-1"), Name: "user@example.com"}, nil)
+
+	phishing := webauthntest.NewAuthenticator("http://phishing.example.com")
+	attestation, err := phishing.Create(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = rp.FinishRegistration(challenge, attestation)
+	assert.ErrorIs(t, err, webauthn.ErrInvalidCredential)
+
+	other := webauthn.New(webauthn.Config{RPID: "example.com", Origins: []string{"http://localhost:5001"}})
+	attestation, err = webauthntest.NewAuthenticator("http://localhost:5001").Create(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = other.FinishRegistration(challenge, attestation)
+	assert.ErrorIs(t, err, webauthn.ErrInvalidCredential)
+}
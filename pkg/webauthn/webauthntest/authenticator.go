@@ -0,0 +1,193 @@
+// Package webauthntest provides a software authenticator to test the WebAuthn ceremonies of relying parties.
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"go-admin/pkg/webauthn"
+
+	"github.com/ugorji/go/codec"
+)
+
+var ErrNoCredential = errors.New("webauthntest: no credential for the relying party")
+
+type credential struct {
+	id         []byte
+	rpID       string
+	userHandle []byte
+	key        *ecdsa.PrivateKey
+	signCount  uint32
+}
+
+// Authenticator is a platform authenticator with discoverable ES256 credentials, the user is always
+// present and verified. The client data is created for Origin, like a browser on that page does.
+type Authenticator struct {
+	Origin string
+
+	mu          sync.Mutex
+	credentials []*credential
+}
+
+func NewAuthenticator(origin string) *Authenticator {
+	return &Authenticator{Origin: origin}
+}
+
+// Create a credential like navigator.credentials.create() does.
+func (a *Authenticator) Create(options *webauthn.CreationOptions) (*webauthn.AttestationResponse, error) {
+	opts := options.PublicKey
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, exclude := range opts.ExcludeCredentials {
+		for _, cred := range a.credentials {
+			if string(cred.id) == string(exclude.ID) {
+				return nil, errors.New("webauthntest: credential already registered")
+			}
+		}
+	}
+	cred := &credential{id: id, rpID: opts.RP.ID, userHandle: opts.User.ID, key: key}
+	a.credentials = append(a.credentials, cred)
+
+	clientDataJSON, err := a.clientData("webauthn.create", opts.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	coseKey, err := encodeCBOR(map[int]interface{}{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: padTo32(key.X.Bytes()),
+		-3: padTo32(key.Y.Bytes()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// attested credential data: aaguid, credential id length, credential id and public key
+	attested := make([]byte, 16, 18+len(id)+len(coseKey))
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(id)))
+	attested = append(attested, id...)
+	attested = append(attested, coseKey...)
+
+	attestationObject, err := encodeCBOR(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": cred.authenticatorData(0x45, attested), // UP, UV and AT
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthn.AttestationResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(id),
+		RawID: id,
+		Type:  webauthn.PublicKeyType,
+		Response: webauthn.AuthenticatorAttestationResponse{
+			ClientDataJSON:    clientDataJSON,
+			AttestationObject: attestationObject,
+			Transports:        []string{"internal"},
+		},
+	}, nil
+}
+
+// Sign the challenge with a credential like navigator.credentials.get() does, the first allowed credential
+// of the relying party is used, or the first discoverable one if none is allowed.
+func (a *Authenticator) Get(options *webauthn.RequestOptions) (*webauthn.AssertionResponse, error) {
+	opts := options.PublicKey
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var cred *credential
+	for _, item := range a.credentials {
+		if item.rpID != opts.RPID {
+			continue
+		}
+		if len(opts.AllowCredentials) == 0 {
+			cred = item
+			break
+		}
+		for _, allow := range opts.AllowCredentials {
+			if string(allow.ID) == string(item.id) {
+				cred = item
+				break
+			}
+		}
+		if cred != nil {
+			break
+		}
+	}
+	if cred == nil {
+		return nil, ErrNoCredential
+	}
+
+	clientDataJSON, err := a.clientData("webauthn.get", opts.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	cred.signCount++
+	authData := cred.authenticatorData(0x05, nil) // UP and UV
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, cred.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthn.AssertionResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(cred.id),
+		RawID: cred.id,
+		Type:  webauthn.PublicKeyType,
+		Response: webauthn.AuthenticatorAssertionResponse{
+			ClientDataJSON:    clientDataJSON,
+			AuthenticatorData: authData,
+			Signature:         signature,
+			UserHandle:        cred.userHandle,
+		},
+	}, nil
+}
+
+func (a *Authenticator) clientData(typ string, challenge []byte) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"type":        typ,
+		"challenge":   base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":      a.Origin,
+		"crossOrigin": false,
+	})
+}
+
+func (c *credential) authenticatorData(flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(c.rpID))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, c.signCount)
+	return append(data, attested...)
+}
+
+func encodeCBOR(v interface{}) ([]byte, error) {
+	var b []byte
+	err := codec.NewEncoderBytes(&b, new(codec.CborHandle)).Encode(v)
+	return b, err
+}
+
+func padTo32(b []byte) []byte {
+	if len(b) >= 32 {
+		return b
+	}
+	return append(make([]byte, 32-len(b)), b...)
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/middleware"
+	"go-admin/pkg/util"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+
+	var roleA, roleB, roleC model.Role
+	tester(t).POST(baseAPI + "/roles").WithJSON(model.RoleForm{Code: "api-key-a", Name: "API Key A", Status: model.RoleStatusEnabled}).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &roleA})
+	tester(t).POST(baseAPI + "/roles").WithJSON(model.RoleForm{Code: "api-key-b", Name: "API Key B", Status: model.RoleStatusEnabled}).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &roleB})
+	tester(t).POST(baseAPI + "/roles").WithJSON(model.RoleForm{Code: "api-key-c", Name: "API Key C", Status: model.RoleStatusEnabled}).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &roleC})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/roles/" + roleA.ID).Expect().Status(http.StatusOK)
+		tester(t).DELETE(baseAPI + "/roles/" + roleB.ID).Expect().Status(http.StatusOK)
+		tester(t).DELETE(baseAPI + "/roles/" + roleC.ID).Expect().Status(http.StatusOK)
+	}()
+	validFrom := time.Now().Add(time.Hour)
+
+	userFormItem := model.UserForm{
+		Email:     "api-key@example.com",
+		FirstName: "API",
+		LastName:  "Key",
+		Password:  hash.MD5String("api-key"),
+		Status:    model.UserStatusActive,
+		Roles:     model.UserRoles{{RoleID: roleA.ID}, {RoleID: roleB.ID}, {RoleID: roleC.ID, ValidFrom: &validFrom}},
+	}
+	var user model.User
+	tester(t).POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+
+	captchaID, captchaCode := solveCaptcha(t, e)
+	var token model.LoginToken
+	e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+		Email:       userFormItem.Email,
+		Password:    userFormItem.Password,
+		CaptchaID:   captchaID,
+		CaptchaCode: captchaCode,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+	bearer := "Bearer " + token.AccessToken
+
+	createKey := func(formItem model.APIKeyForm) *model.APIKey {
+		var apiKey model.APIKey
+		e.POST(baseAPI+"/current/api-keys").WithHeader("Authorization", bearer).WithJSON(formItem).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &apiKey})
+		return &apiKey
+	}
+
+	// Keys can only be granted roles of the user
+	e.POST(baseAPI+"/current/api-keys").WithHeader("Authorization", bearer).
+		WithJSON(model.APIKeyForm{Name: "invalid", RoleIDs: []string{"not-granted"}}).
+		Expect().Status(http.StatusBadRequest)
+	// The role assigned from a future time is not granted yet
+	e.POST(baseAPI+"/current/api-keys").WithHeader("Authorization", bearer).
+		WithJSON(model.APIKeyForm{Name: "not-yet", RoleIDs: []string{roleC.ID}}).
+		Expect().Status(http.StatusBadRequest)
+
+	apiKey := createKey(model.APIKeyForm{Name: "ci", RoleIDs: []string{roleA.ID}})
+	assert.NotEmpty(apiKey.Key)
+	assert.Equal(apiKey.Key[:len(apiKey.Prefix)], apiKey.Prefix)
+
+	// The key is accepted with both headers
+	e.GET(baseAPI+"/current/user").WithHeader("X-API-Key", apiKey.Key).
+		Expect().Status(http.StatusOK).JSON().Path("$.data.id").IsEqual(user.ID)
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", "ApiKey "+apiKey.Key).
+		Expect().Status(http.StatusOK).JSON().Path("$.data.id").IsEqual(user.ID)
+	e.GET(baseAPI+"/current/user").WithHeader("X-API-Key", "gak_invalid").Expect().Status(http.StatusUnauthorized)
+
+	// Only the hash is stored and the key is not returned again
+	var keys model.APIKeys
+	e.GET(baseAPI+"/current/api-keys").WithHeader("Authorization", bearer).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &keys})
+	if assert.Len(keys, 1) {
+		assert.Empty(keys[0].Key)
+		assert.NotNil(keys[0].LastUsedAt)
+		assert.Equal([]string{roleA.ID}, keys[0].RoleIDs)
+	}
+	var stored model.APIKey
+	assert.Nil(injector.DB.Where("id = ?", apiKey.ID).First(&stored).Error)
+	assert.Equal(hash.SHA256String(apiKey.Key), stored.KeyHash)
+
+	// The requests of the key only have the roles of the key
+	roleApp := gin.New()
+	roleApp.Use(middleware.AuthWithConfig(middleware.AuthConfig{
+		ParseUserID: injector.Mods.Auth.AuthAPI.AuthService.ParseUserID,
+		RootID:      config.C.General.Root.ID,
+	}))
+	roleApp.GET("/roles", func(c *gin.Context) {
+		util.ResSuccess(c, util.FromUserCache(c.Request.Context()).RoleIDs, "")
+	})
+	roleServer := httptest.NewServer(roleApp)
+	defer roleServer.Close()
+	roles := httpexpect.Default(t, roleServer.URL)
+	roles.GET("/roles").WithHeader("X-API-Key", apiKey.Key).
+		Expect().Status(http.StatusOK).JSON().Path("$.data").IsEqual([]string{roleA.ID})
+	roles.GET("/roles").WithHeader("Authorization", bearer).
+		Expect().Status(http.StatusOK).JSON().Path("$.data").Array().Length().IsEqual(2)
+
+	// Keys can't manage keys
+	e.POST(baseAPI+"/current/api-keys").WithHeader("X-API-Key", apiKey.Key).WithJSON(model.APIKeyForm{Name: "nested"}).
+		Expect().Status(http.StatusForbidden)
+	e.GET(baseAPI+"/current/api-keys").WithHeader("X-API-Key", apiKey.Key).Expect().Status(http.StatusForbidden)
+
+	// Expired keys are rejected
+	expiresAt := time.Now().Add(time.Hour)
+	expiredKey := createKey(model.APIKeyForm{Name: "expired", ExpiresAt: &expiresAt})
+	e.GET(baseAPI+"/current/user").WithHeader("X-API-Key", expiredKey.Key).Expect().Status(http.StatusOK)
+	assert.Nil(injector.DB.Model(new(model.APIKey)).Where("id = ?", expiredKey.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	e.GET(baseAPI+"/current/user").WithHeader("X-API-Key", expiredKey.Key).Expect().Status(http.StatusUnauthorized)
+
+	// Revoked by the user
+	e.DELETE(baseAPI+"/current/api-keys/"+expiredKey.ID).WithHeader("Authorization", bearer).Expect().Status(http.StatusOK)
+	e.DELETE(baseAPI+"/current/api-keys/"+expiredKey.ID).WithHeader("Authorization", bearer).Expect().Status(http.StatusNotFound)
+
+	// Revoked by the administrator
+	tester(t).GET(baseAPI + "/users/" + user.ID + "/api-keys").Expect().Status(http.StatusOK).
+		JSON().Path("$.data").Array().Length().IsEqual(1)
+	tester(t).DELETE(baseAPI + "/users/" + user.ID + "/api-keys/" + apiKey.ID).Expect().Status(http.StatusOK)
+	e.GET(baseAPI+"/current/user").WithHeader("X-API-Key", apiKey.Key).Expect().Status(http.StatusUnauthorized)
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/util"
+
+	"github.com/LyricTian/captcha"
+	"github.com/gavv/httpexpect/v2"
+)
+
+var (
+	captchaDigits    = new(captchaStore)
+	captchaStoreOnce sync.Once
+)
+
+// A captcha store that lets the tests read the digits of the generated captchas.
+type captchaStore struct {
+	mu     sync.Mutex
+	digits map[string][]byte
+}
+
+func (s *captchaStore) Set(id string, digits []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.digits == nil {
+		s.digits = make(map[string][]byte)
+	}
+	s.digits[id] = digits
+}
+
+func (s *captchaStore) Get(id string, clear bool) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	digits := s.digits[id]
+	if clear {
+		delete(s.digits, id)
+	}
+	return digits
+}
+
+// Request a captcha and return its ID and solution.
+func solveCaptcha(t *testing.T, e *httpexpect.Expect) (string, string) {
+	captchaStoreOnce.Do(func() {
+		captcha.SetCustomStore(captchaDigits)
+	})
+
+	var data model.Captcha
+	e.GET(baseAPI + "/captcha/id").Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &data})
+
+	var code strings.Builder
+	for _, d := range captchaDigits.Get(data.CaptchaID, false) {
+		code.WriteByte('0' + d)
+	}
+	return data.CaptchaID, code.String()
+}
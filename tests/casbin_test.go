@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCasbinIncrementalLoad(t *testing.T) {
+	e := tester(t)
+	assert := assert.New(t)
+	casbinx := injector.Mods.Auth.Casbinx
+
+	enforce := func(sub, obj string) func() bool {
+		return func() bool {
+			ok, _ := casbinx.GetEnforcer().Enforce(sub, "", obj, http.MethodGet)
+			return ok
+		}
+	}
+	eventually := func(cond func() bool) {
+		assert.Eventually(cond, 10*time.Second, 100*time.Millisecond)
+	}
+	not := func(cond func() bool) func() bool {
+		return func() bool { return !cond() }
+	}
+
+	var permissionA, permissionB model.Permission
+	e.POST(baseAPI + "/permissions").WithJSON(model.PermissionForm{
+		Code:       "casbin_a",
+		Name:       "Casbin A",
+		HttpMethod: http.MethodGet,
+		HttpPath:   "/api/v1/casbin-a",
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &permissionA})
+	e.POST(baseAPI + "/permissions").WithJSON(model.PermissionForm{
+		Code:       "casbin_b",
+		Name:       "Casbin B",
+		HttpMethod: http.MethodGet,
+		HttpPath:   "/api/v1/casbin-b",
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &permissionB})
+
+	var roleA, roleB model.Role
+	e.POST(baseAPI + "/roles").WithJSON(model.RoleForm{
+		Code:        "casbin_role_a",
+		Name:        "Casbin role A",
+		Permissions: model.RolePermissions{{PermissionID: permissionA.ID}},
+		Status:      model.RoleStatusEnabled,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &roleA})
+	e.POST(baseAPI + "/roles").WithJSON(model.RoleForm{
+		Code:        "casbin_role_b",
+		Name:        "Casbin role B",
+		Permissions: model.RolePermissions{{PermissionID: permissionB.ID}},
+		Status:      model.RoleStatusEnabled,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &roleB})
+
+	eventually(enforce(roleA.ID, "/api/v1/casbin-a"))
+	eventually(enforce(roleB.ID, "/api/v1/casbin-b"))
+
+	// Editing a role only replaces the rules of that role
+	roleA.Permissions = model.RolePermissions{{PermissionID: permissionB.ID}}
+	e.PUT(baseAPI + "/roles/" + roleA.ID).WithJSON(roleA).Expect().Status(http.StatusOK)
+	eventually(enforce(roleA.ID, "/api/v1/casbin-b"))
+	assert.False(enforce(roleA.ID, "/api/v1/casbin-a")())
+	assert.True(enforce(roleB.ID, "/api/v1/casbin-b")())
+
+	// Editing a permission reloads every role that is granted the permission
+	e.PUT(baseAPI + "/permissions/" + permissionB.ID).WithJSON(model.PermissionForm{
+		Code:       "casbin_b",
+		Name:       "Casbin B",
+		HttpMethod: http.MethodGet,
+		HttpPath:   "/api/v1/casbin-c",
+	}).Expect().Status(http.StatusOK)
+	eventually(enforce(roleA.ID, "/api/v1/casbin-c"))
+	eventually(enforce(roleB.ID, "/api/v1/casbin-c"))
+	assert.False(enforce(roleB.ID, "/api/v1/casbin-b")())
+
+	// Disabled and deleted roles lose their rules
+	roleB.Status = model.RoleStatusDisabled
+	e.PUT(baseAPI + "/roles/" + roleB.ID).WithJSON(roleB).Expect().Status(http.StatusOK)
+	eventually(not(enforce(roleB.ID, "/api/v1/casbin-c")))
+	assert.True(enforce(roleA.ID, "/api/v1/casbin-c")())
+
+	e.DELETE(baseAPI + "/roles/" + roleA.ID).Expect().Status(http.StatusOK)
+	eventually(not(enforce(roleA.ID, "/api/v1/casbin-c")))
+
+	e.DELETE(baseAPI + "/roles/" + roleB.ID).Expect().Status(http.StatusOK)
+	e.DELETE(baseAPI + "/permissions/" + permissionA.ID).Expect().Status(http.StatusOK)
+	e.DELETE(baseAPI + "/permissions/" + permissionB.ID).Expect().Status(http.StatusOK)
+
+	// The policy is no longer written to the working directory
+	_, err := os.Stat(filepath.Join(config.C.General.WorkDir, "gen_rbac_policy.csv"))
+	assert.True(os.IsNotExist(err))
+}
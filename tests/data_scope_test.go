@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go-admin/internal/modules/auth/model"
+	sysModel "go-admin/internal/modules/sys/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataScope(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+
+	createRole := func(formItem *model.RoleForm) string {
+		formItem.Name = formItem.Code
+		formItem.Status = model.RoleStatusEnabled
+		var role model.Role
+		tester(t).POST(baseAPI + "/roles").WithJSON(formItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &role})
+		t.Cleanup(func() {
+			tester(t).DELETE(baseAPI + "/roles/" + role.ID).Expect().Status(http.StatusOK)
+		})
+		return role.ID
+	}
+	createDepartment := func(code, parentID string) string {
+		var department model.Department
+		tester(t).POST(baseAPI + "/departments").WithJSON(model.DepartmentForm{
+			Code:     code,
+			Name:     code,
+			Status:   model.DepartmentStatusEnabled,
+			ParentID: parentID,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &department})
+		t.Cleanup(func() {
+			tester(t).DELETE(baseAPI + "/departments/" + department.ID).Expect().Status(http.StatusOK)
+		})
+		return department.ID
+	}
+	createUser := func(email string, departmentIDs []string, roleIDs ...string) *model.User {
+		userFormItem := model.UserForm{
+			Email:     email,
+			FirstName: "Data",
+			LastName:  "Scope",
+			Password:  hash.MD5String("data-scope"),
+			Status:    model.UserStatusActive,
+		}
+		for _, roleID := range roleIDs {
+			userFormItem.Roles = append(userFormItem.Roles, &model.UserRole{RoleID: roleID})
+		}
+		for _, departmentID := range departmentIDs {
+			userFormItem.Departments = append(userFormItem.Departments, &model.UserDepartment{DepartmentID: departmentID})
+		}
+		var user model.User
+		tester(t).POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+		t.Cleanup(func() {
+			tester(t).DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+		})
+		return &user
+	}
+	login := func(email string) string {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		var token model.LoginToken
+		e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+			Email:       email,
+			Password:    hash.MD5String("data-scope"),
+			CaptchaID:   captchaID,
+			CaptchaCode: captchaCode,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+		return "Bearer " + token.AccessToken
+	}
+	queryUsers := func(authorization string) []string {
+		var users model.Users
+		e.GET(baseAPI+"/users").WithQuery("email", "data-scope-").WithHeader("Authorization", authorization).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &users})
+		var emails []string
+		for _, user := range users {
+			emails = append(emails, user.Email)
+		}
+		return emails
+	}
+
+	// sales
+	// └── east
+	salesID := createDepartment("data-scope-sales", "")
+	eastID := createDepartment("data-scope-east", salesID)
+
+	target := createUser("data-scope-target@example.com", nil)
+	ownRoleID := createRole(&model.RoleForm{Code: "data-scope-own", DataScope: model.RoleDataScopeOwn})
+	customRole := &model.RoleForm{Code: "data-scope-custom", DataScope: model.RoleDataScopeCustom, DataUserIDs: []string{target.ID}}
+	customRoleID := createRole(customRole)
+	allRoleID := createRole(&model.RoleForm{Code: "data-scope-all", DataScope: model.RoleDataScopeAll})
+	ownUser := createUser("data-scope-own@example.com", nil, ownRoleID)
+	customUser := createUser("data-scope-custom@example.com", nil, customRoleID)
+	createUser("data-scope-all@example.com", nil, ownRoleID, allRoleID)
+
+	ownToken := login("data-scope-own@example.com")
+	customToken := login("data-scope-custom@example.com")
+	allToken := login("data-scope-all@example.com")
+
+	assert.Equal([]string{"data-scope-own@example.com"}, queryUsers(ownToken))
+	assert.ElementsMatch([]string{"data-scope-custom@example.com", "data-scope-target@example.com"}, queryUsers(customToken))
+	assert.Len(queryUsers(allToken), 4)
+
+	// Changes of the role apply to the next request of its users
+	customRole.DataScope = model.RoleDataScopeAll
+	tester(t).PUT(baseAPI + "/roles/" + customRoleID).WithJSON(customRole).Expect().Status(http.StatusOK)
+	assert.Len(queryUsers(customToken), 4)
+
+	// Logs are scoped by their users
+	assert.Nil(injector.DB.AutoMigrate(new(sysModel.Logger)))
+	for _, userID := range []string{ownUser.ID, customUser.ID, target.ID} {
+		assert.Nil(injector.DB.Create(&sysModel.Logger{
+			ID:        util.NewXID(),
+			Level:     "info",
+			TraceID:   "data-scope",
+			UserID:    userID,
+			Message:   "data scope",
+			CreatedAt: time.Now(),
+		}).Error)
+	}
+	var loggers sysModel.Loggers
+	e.GET(baseAPI+"/loggers").WithQuery("traceID", "data-scope").WithHeader("Authorization", ownToken).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &loggers})
+	if assert.Len(loggers, 1) {
+		assert.Equal(ownUser.ID, loggers[0].UserID)
+	}
+	tester(t).GET(baseAPI+"/loggers").WithQuery("traceID", "data-scope").
+		Expect().Status(http.StatusOK).JSON().Path("$.data").Array().Length().IsEqual(3)
+
+	// The department scope sees the members of the user's departments and their sub-departments
+	departmentRoleID := createRole(&model.RoleForm{Code: "data-scope-department", DataScope: model.RoleDataScopeDepartment})
+	createUser("data-scope-sales@example.com", []string{salesID}, departmentRoleID)
+	createUser("data-scope-east@example.com", []string{eastID})
+	assert.ElementsMatch([]string{"data-scope-sales@example.com", "data-scope-east@example.com"},
+		queryUsers(login("data-scope-sales@example.com")))
+
+	// Roles without their own data scope only see the rows of their parents
+	tester(t).POST(baseAPI + "/roles").WithJSON(&model.RoleForm{
+		Code:      "data-scope-invalid",
+		Name:      "data-scope-invalid",
+		Status:    model.RoleStatusEnabled,
+		DataScope: model.RoleDataScopeInherit,
+	}).Expect().Status(http.StatusBadRequest)
+	inheritRoleID := createRole(&model.RoleForm{Code: "data-scope-inherit", ParentIDs: []string{ownRoleID}})
+	createUser("data-scope-inherit@example.com", nil, inheritRoleID)
+	assert.Equal([]string{"data-scope-inherit@example.com"}, queryUsers(login("data-scope-inherit@example.com")))
+
+	// The scopes of the inherited roles are merged, and their changes apply to the users of the children
+	parentRole := &model.RoleForm{Code: "data-scope-parent", DataScope: model.RoleDataScopeCustom, DataDepartmentIDs: []string{eastID}}
+	parentRoleID := createRole(parentRole)
+	mergeRoleID := createRole(&model.RoleForm{
+		Code:        "data-scope-merge",
+		DataScope:   model.RoleDataScopeCustom,
+		DataUserIDs: []string{target.ID},
+		ParentIDs:   []string{parentRoleID},
+	})
+	createUser("data-scope-merge@example.com", nil, mergeRoleID)
+	mergeToken := login("data-scope-merge@example.com")
+	assert.ElementsMatch([]string{"data-scope-merge@example.com", "data-scope-target@example.com", "data-scope-east@example.com"},
+		queryUsers(mergeToken))
+	parentRole.DataDepartmentIDs = []string{salesID}
+	tester(t).PUT(baseAPI + "/roles/" + parentRoleID).WithJSON(parentRole).Expect().Status(http.StatusOK)
+	assert.ElementsMatch([]string{"data-scope-merge@example.com", "data-scope-target@example.com", "data-scope-east@example.com",
+		"data-scope-sales@example.com"}, queryUsers(mergeToken))
+}
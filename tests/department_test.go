@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepartment(t *testing.T) {
+	e := tester(t)
+	assert := assert.New(t)
+
+	createDepartment := func(code, parentID string, sequence int) *model.Department {
+		var department model.Department
+		e.POST(baseAPI + "/departments").WithJSON(model.DepartmentForm{
+			Code:     code,
+			Name:     code,
+			Sequence: sequence,
+			Status:   model.DepartmentStatusEnabled,
+			ParentID: parentID,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &department})
+		return &department
+	}
+	getDepartment := func(id string) *model.Department {
+		var department model.Department
+		e.GET(baseAPI + "/departments/" + id).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &department})
+		return &department
+	}
+
+	// root
+	// ├── sales
+	// │   └── east
+	// └── tech
+	root := createDepartment("dept-root", "", 0)
+	sales := createDepartment("dept-sales", root.ID, 1)
+	east := createDepartment("dept-east", sales.ID, 0)
+	tech := createDepartment("dept-tech", root.ID, 2)
+	assert.Equal(root.ID+util.TreePathDelimiter+sales.ID+util.TreePathDelimiter, east.ParentPath)
+
+	e.POST(baseAPI + "/departments").WithJSON(model.DepartmentForm{
+		Code:     "dept-east",
+		Name:     "Duplicated",
+		Status:   model.DepartmentStatusEnabled,
+		ParentID: sales.ID,
+	}).Expect().Status(http.StatusBadRequest)
+
+	var departments model.Departments
+	e.GET(baseAPI+"/departments").WithQuery("name", "dept-").
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &departments})
+	if assert.Len(departments, 1) && assert.NotNil(departments[0].Children) {
+		children := *departments[0].Children
+		if assert.Len(children, 2) {
+			assert.Equal(tech.ID, children[0].ID)
+			assert.Equal(sales.ID, children[1].ID)
+		}
+	}
+
+	// Searching by name keeps the ancestors of the matches
+	e.GET(baseAPI+"/departments").WithQuery("name", "dept-east").
+		Expect().Status(http.StatusOK).JSON().Path("$.data[0].id").IsEqual(root.ID)
+
+	// Departments can't be moved under themselves or their sub-departments
+	e.PATCH(baseAPI + "/departments/" + sales.ID + "/move").WithJSON(model.DepartmentMoveForm{ParentID: sales.ID}).
+		Expect().Status(http.StatusBadRequest)
+	e.PATCH(baseAPI + "/departments/" + sales.ID + "/move").WithJSON(model.DepartmentMoveForm{ParentID: east.ID}).
+		Expect().Status(http.StatusBadRequest)
+
+	// Moving carries the sub-departments along
+	e.PATCH(baseAPI + "/departments/" + sales.ID + "/move").WithJSON(model.DepartmentMoveForm{ParentID: tech.ID}).
+		Expect().Status(http.StatusOK)
+	assert.Equal(root.ID+util.TreePathDelimiter+tech.ID+util.TreePathDelimiter+sales.ID+util.TreePathDelimiter, getDepartment(east.ID).ParentPath)
+	assert.Equal(1, getDepartment(sales.ID).Sequence)
+
+	// Reordering keeps the parent
+	sequence := 3
+	e.PATCH(baseAPI + "/departments/" + sales.ID + "/move").WithJSON(model.DepartmentMoveForm{ParentID: tech.ID, Sequence: &sequence}).
+		Expect().Status(http.StatusOK)
+	sales = getDepartment(sales.ID)
+	assert.Equal(tech.ID, sales.ParentID)
+	assert.Equal(sequence, sales.Sequence)
+
+	// Disabling a department disables its sub-departments
+	e.PUT(baseAPI + "/departments/" + tech.ID).WithJSON(model.DepartmentForm{
+		Code:     tech.Code,
+		Name:     tech.Name,
+		Sequence: tech.Sequence,
+		Status:   model.DepartmentStatusDisabled,
+		ParentID: tech.ParentID,
+	}).Expect().Status(http.StatusOK)
+	assert.Equal(model.DepartmentStatusDisabled, getDepartment(east.ID).Status)
+
+	// Members and leaders
+	createUser := func(email string, departments ...*model.UserDepartment) *model.User {
+		var user model.User
+		e.POST(baseAPI + "/users").WithJSON(model.UserForm{
+			Email:       email,
+			FirstName:   "Department",
+			LastName:    "User",
+			Password:    hash.MD5String("department"),
+			Status:      model.UserStatusActive,
+			Departments: departments,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+		return &user
+	}
+	leader := createUser("dept-leader@example.com", &model.UserDepartment{DepartmentID: tech.ID, Leader: true})
+	member := createUser("dept-member@example.com", &model.UserDepartment{DepartmentID: east.ID})
+	other := createUser("dept-other@example.com", &model.UserDepartment{DepartmentID: root.ID})
+	defer func() {
+		for _, item := range []*model.User{leader, member, other} {
+			e.DELETE(baseAPI + "/users/" + item.ID).Expect().Status(http.StatusOK)
+		}
+	}()
+
+	assert.Equal([]string{leader.ID}, getDepartment(tech.ID).LeaderIDs)
+	assert.Empty(getDepartment(east.ID).LeaderIDs)
+
+	var user model.User
+	e.GET(baseAPI + "/users/" + member.ID).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	if assert.Len(user.Departments, 1) {
+		assert.Equal(east.ID, user.Departments[0].DepartmentID)
+		assert.Equal(east.Name, user.Departments[0].DepartmentName)
+	}
+
+	// Users are filtered by the department subtree
+	queryUsers := func(departmentID string) []string {
+		var users model.Users
+		e.GET(baseAPI+"/users").WithQuery("department_id", departmentID).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &users})
+		var ids []string
+		for _, item := range users {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+	assert.ElementsMatch([]string{leader.ID, member.ID}, queryUsers(tech.ID))
+	assert.ElementsMatch([]string{member.ID}, queryUsers(east.ID))
+	assert.ElementsMatch([]string{leader.ID, member.ID, other.ID}, queryUsers(root.ID))
+
+	// Departments with sub-departments can't be deleted, deleting drops the memberships
+	e.DELETE(baseAPI + "/departments/" + sales.ID).Expect().Status(http.StatusBadRequest)
+	for _, item := range []*model.Department{east, sales, tech, root} {
+		e.DELETE(baseAPI + "/departments/" + item.ID).Expect().Status(http.StatusOK)
+	}
+	e.GET(baseAPI + "/departments/" + root.ID).Expect().Status(http.StatusNotFound)
+	e.GET(baseAPI + "/users/" + member.ID).Expect().Status(http.StatusOK).JSON().Path("$.data.departments").Array().IsEmpty()
+}
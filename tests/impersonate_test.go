@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpersonate(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+
+	var roleA, roleB model.Role
+	tester(t).POST(baseAPI + "/roles").WithJSON(model.RoleForm{Code: "impersonate-a", Name: "Impersonate A", Status: model.RoleStatusEnabled}).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &roleA})
+	tester(t).POST(baseAPI + "/roles").WithJSON(model.RoleForm{Code: "impersonate-b", Name: "Impersonate B", Status: model.RoleStatusEnabled}).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &roleB})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/roles/" + roleA.ID).Expect().Status(http.StatusOK)
+		tester(t).DELETE(baseAPI + "/roles/" + roleB.ID).Expect().Status(http.StatusOK)
+	}()
+
+	createUser := func(email string, roleIDs ...string) (*model.User, model.UserForm) {
+		userFormItem := model.UserForm{
+			Email:     email,
+			FirstName: "Impersonate",
+			LastName:  "User",
+			Password:  hash.MD5String("impersonate"),
+			Status:    model.UserStatusActive,
+		}
+		for _, roleID := range roleIDs {
+			userFormItem.Roles = append(userFormItem.Roles, &model.UserRole{RoleID: roleID})
+		}
+		var user model.User
+		tester(t).POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+		return &user, userFormItem
+	}
+	login := func(email, password string) string {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		var token model.LoginToken
+		e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+			Email:       email,
+			Password:    password,
+			CaptchaID:   captchaID,
+			CaptchaCode: captchaCode,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+		return "Bearer " + token.AccessToken
+	}
+	impersonate := func(authorization, userID string) string {
+		var token model.LoginToken
+		e.POST(baseAPI+"/users/"+userID+"/impersonate").WithHeader("Authorization", authorization).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+		assert.Empty(token.RefreshToken)
+		return "Bearer " + token.AccessToken
+	}
+
+	admin, adminForm := createUser("impersonate-admin@example.com", roleA.ID, roleB.ID)
+	user, _ := createUser("impersonate-user@example.com", roleA.ID)
+	other, _ := createUser("impersonate-other@example.com")
+	defer func() {
+		for _, item := range []*model.User{admin, user, other} {
+			tester(t).DELETE(baseAPI + "/users/" + item.ID).Expect().Status(http.StatusOK)
+		}
+	}()
+
+	// Root can impersonate any user, but not be impersonated
+	rootConfig := &config.C.General.Root
+	oldRootPassword := rootConfig.Password
+	defer func() { rootConfig.Password = oldRootPassword }()
+	rootConfig.Password = hash.MD5String("impersonate-root")
+	root := login(rootConfig.Email, "impersonate-root")
+	adminToken := impersonate(root, admin.ID)
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", adminToken).
+		Expect().Status(http.StatusOK).JSON().Path("$.data.id").IsEqual(admin.ID)
+
+	adminLogin := login(adminForm.Email, adminForm.Password)
+	e.POST(baseAPI+"/users/"+rootConfig.ID+"/impersonate").WithHeader("Authorization", adminLogin).
+		Expect().Status(http.StatusForbidden)
+	e.POST(baseAPI+"/users/"+admin.ID+"/impersonate").WithHeader("Authorization", adminLogin).
+		Expect().Status(http.StatusBadRequest)
+
+	// Impersonators can't gain roles they don't have
+	userLogin := login(user.Email, hash.MD5String("impersonate"))
+	e.POST(baseAPI+"/users/"+admin.ID+"/impersonate").WithHeader("Authorization", userLogin).
+		Expect().Status(http.StatusForbidden)
+	impersonate(userLogin, other.ID)
+
+	// Impersonations can't be nested
+	userToken := impersonate(adminLogin, user.ID)
+	e.POST(baseAPI+"/users/"+other.ID+"/impersonate").WithHeader("Authorization", userToken).
+		Expect().Status(http.StatusForbidden)
+
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", userToken).
+		Expect().Status(http.StatusOK).JSON().Path("$.data.id").IsEqual(user.ID)
+	e.GET(baseAPI+"/current/menus").WithHeader("Authorization", userToken).Expect().Status(http.StatusOK)
+
+	// Impersonators can't manage the credentials of the user
+	e.PUT(baseAPI+"/current/password").WithHeader("Authorization", userToken).WithJSON(model.UpdateLoginPassword{
+		OldPassword: hash.MD5String("impersonate"),
+		NewPassword: hash.MD5String("impersonated"),
+	}).Expect().Status(http.StatusForbidden)
+	e.POST(baseAPI+"/current/api-keys").WithHeader("Authorization", userToken).WithJSON(model.APIKeyForm{Name: "impersonate"}).
+		Expect().Status(http.StatusForbidden)
+	e.POST(baseAPI+"/current/mfa/enroll").WithHeader("Authorization", userToken).Expect().Status(http.StatusForbidden)
+
+	// Ending the impersonation only invalidates the impersonation token
+	e.DELETE(baseAPI+"/current/impersonation").WithHeader("Authorization", userToken).Expect().Status(http.StatusOK)
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", userToken).Expect().Status(http.StatusUnauthorized)
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", adminLogin).Expect().Status(http.StatusOK)
+	e.DELETE(baseAPI+"/current/impersonation").WithHeader("Authorization", adminLogin).Expect().Status(http.StatusBadRequest)
+
+	// The impersonation ends when the impersonator loses a role of the user
+	userToken = impersonate(adminLogin, user.ID)
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", userToken).Expect().Status(http.StatusOK)
+	adminRoles := adminForm.Roles
+	adminForm.Roles = model.UserRoles{{RoleID: roleB.ID}}
+	tester(t).PUT(baseAPI + "/users/" + admin.ID).WithJSON(adminForm).Expect().Status(http.StatusOK)
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", userToken).Expect().Status(http.StatusUnauthorized)
+	adminForm.Roles = adminRoles
+	tester(t).PUT(baseAPI + "/users/" + admin.ID).WithJSON(adminForm).Expect().Status(http.StatusOK)
+
+	// The impersonation ends with the impersonator
+	userToken = impersonate(adminLogin, user.ID)
+	adminForm.Status = model.UserStatusInactive
+	tester(t).PUT(baseAPI + "/users/" + admin.ID).WithJSON(adminForm).Expect().Status(http.StatusOK)
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", userToken).Expect().Status(http.StatusUnauthorized)
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvitation(t *testing.T) {
+	e := tester(t)
+	assert := assert.New(t)
+	mailbox := getMailbox(t)
+
+	var role model.Role
+	e.POST(baseAPI + "/roles").WithJSON(model.RoleForm{
+		Code:   "invitee",
+		Name:   "Invitee",
+		Status: model.RoleStatusEnabled,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &role})
+	defer func() {
+		e.DELETE(baseAPI + "/roles/" + role.ID).Expect().Status(http.StatusOK)
+	}()
+
+	email := "invitee@example.com"
+	e.POST(baseAPI + "/invitations").WithJSON(model.InvitationForm{Email: email, RoleIDs: []string{"unknown"}}).
+		Expect().Status(http.StatusBadRequest)
+
+	var invitation model.Invitation
+	e.POST(baseAPI + "/invitations").WithJSON(model.InvitationForm{Email: email, RoleIDs: []string{role.ID}}).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &invitation})
+	assert.Equal(model.InvitationStatusPending, invitation.Status)
+	oldToken := extractLinkToken(t, mailbox.WaitFor(t, email))
+
+	// Only one pending invitation per email
+	e.POST(baseAPI + "/invitations").WithJSON(model.InvitationForm{Email: email}).
+		Expect().Status(http.StatusBadRequest)
+
+	// Resending invalidates the previous token
+	e.POST(baseAPI + "/invitations/" + invitation.ID + "/resend").Expect().Status(http.StatusOK)
+	token := extractLinkToken(t, mailbox.WaitFor(t, email))
+	assert.NotEqual(oldToken, token)
+
+	acceptForm := model.AcceptInvitationForm{
+		Token:     oldToken,
+		FirstName: "In",
+		LastName:  "Vitee",
+		Password:  hash.MD5String("invitee"),
+	}
+	e.POST(baseAPI + "/accept-invitation").WithJSON(acceptForm).
+		Expect().Status(http.StatusBadRequest).JSON().Path("$.error.id").IsEqual(config.ErrInvalidInviteToken)
+
+	var loginToken model.LoginToken
+	acceptForm.Token = token
+	e.POST(baseAPI + "/accept-invitation").WithJSON(acceptForm).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &loginToken})
+	assert.NotEmpty(loginToken.AccessToken)
+	e.POST(baseAPI + "/accept-invitation").WithJSON(acceptForm).
+		Expect().Status(http.StatusBadRequest).JSON().Path("$.error.id").IsEqual(config.ErrInvalidInviteToken)
+
+	var invitations model.Invitations
+	e.GET(baseAPI+"/invitations").WithQuery("email", email).Expect().Status(http.StatusOK).
+		JSON().Decode(&util.ResponseResult{Data: &invitations})
+	if assert.Len(invitations, 1) {
+		assert.Equal(model.InvitationStatusAccepted, invitations[0].Status)
+		assert.NotNil(invitations[0].AcceptedAt)
+	}
+
+	// The user is created with the pre-assigned roles
+	var user model.User
+	e.GET(baseAPI + "/users/" + invitations[0].UserID).Expect().Status(http.StatusOK).
+		JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		e.DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+	assert.Equal(email, user.Email)
+	assert.Equal(model.UserStatusActive, user.Status)
+	if assert.Len(user.Roles, 1) {
+		assert.Equal(role.ID, user.Roles[0].RoleID)
+	}
+	e.POST(baseAPI + "/invitations").WithJSON(model.InvitationForm{Email: email}).
+		Expect().Status(http.StatusBadRequest)
+	e.DELETE(baseAPI + "/invitations/" + invitation.ID).Expect().Status(http.StatusBadRequest)
+
+	// Revoked invitations can't be accepted
+	email2 := "invitee2@example.com"
+	var invitation2 model.Invitation
+	e.POST(baseAPI + "/invitations").WithJSON(model.InvitationForm{Email: email2}).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &invitation2})
+	acceptForm.Token = extractLinkToken(t, mailbox.WaitFor(t, email2))
+	e.DELETE(baseAPI + "/invitations/" + invitation2.ID).Expect().Status(http.StatusOK)
+	e.POST(baseAPI + "/invitations/" + invitation2.ID + "/resend").Expect().Status(http.StatusBadRequest)
+	e.POST(baseAPI + "/accept-invitation").WithJSON(acceptForm).
+		Expect().Status(http.StatusBadRequest).JSON().Path("$.error.id").IsEqual(config.ErrInvalidInviteToken)
+	e.DELETE(baseAPI + "/invitations/unknown").Expect().Status(http.StatusNotFound)
+}
+
+func TestInvitationTenant(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+	mailbox := getMailbox(t)
+
+	var tenant model.Tenant
+	tester(t).POST(baseAPI + "/tenants").WithJSON(model.TenantForm{
+		Code:   "invitation-tenant",
+		Name:   "Invitation tenant",
+		Status: model.TenantStatusEnabled,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &tenant})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/tenants/" + tenant.ID).Expect().Status(http.StatusOK)
+	}()
+
+	rootConfig := &config.C.General.Root
+	oldRootPassword := rootConfig.Password
+	defer func() { rootConfig.Password = oldRootPassword }()
+	rootConfig.Password = hash.MD5String("invitation-root")
+	var rootToken model.LoginToken
+	loginWithPassword(t, e, rootConfig.Email, "invitation-root").Status(http.StatusOK).
+		JSON().Decode(&util.ResponseResult{Data: &rootToken})
+	root := "Bearer " + rootToken.AccessToken
+
+	var role model.Role
+	e.POST(baseAPI+"/roles").WithHeader("Authorization", root).WithHeader("X-Tenant-ID", tenant.ID).WithJSON(model.RoleForm{
+		Code:   "tenant-invitee",
+		Name:   "Tenant invitee",
+		Status: model.RoleStatusEnabled,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &role})
+	defer func() {
+		e.DELETE(baseAPI+"/roles/"+role.ID).WithHeader("Authorization", root).WithHeader("X-Tenant-ID", tenant.ID).
+			Expect().Status(http.StatusOK)
+	}()
+
+	email := "tenant-invitee@example.com"
+	e.POST(baseAPI+"/invitations").WithHeader("Authorization", root).WithHeader("X-Tenant-ID", tenant.ID).
+		WithJSON(model.InvitationForm{Email: email, RoleIDs: []string{role.ID}}).Expect().Status(http.StatusOK)
+
+	// The invite link carries no tenant, the user is created in the tenant of the invitation
+	e.POST(baseAPI + "/accept-invitation").WithJSON(model.AcceptInvitationForm{
+		Token:     extractLinkToken(t, mailbox.WaitFor(t, email)),
+		FirstName: "Tenant",
+		LastName:  "Invitee",
+		Password:  hash.MD5String("tenant-invitee"),
+	}).Expect().Status(http.StatusOK).JSON().Path("$.data.access_token").String().NotEmpty()
+
+	var user model.User
+	assert.Nil(injector.DB.Where("email = ?", email).First(&user).Error)
+	defer func() {
+		e.DELETE(baseAPI+"/users/"+user.ID).WithHeader("Authorization", root).WithHeader("X-Tenant-ID", tenant.ID).
+			Expect().Status(http.StatusOK)
+	}()
+	assert.Equal(tenant.ID, user.TenantID)
+	var userRoles model.UserRoles
+	assert.Nil(injector.DB.Where("user_id = ?", user.ID).Find(&userRoles).Error)
+	if assert.Len(userRoles, 1) {
+		assert.Equal(role.ID, userRoles[0].RoleID)
+	}
+}
@@ -0,0 +1,15 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestJWKS(t *testing.T) {
+	e := tester(t)
+
+	// Tokens are signed with the secret key by default, so there is no public key to publish
+	e.GET("/.well-known/jwks.json").
+		Expect().Status(http.StatusOK).
+		JSON().Object().Value("keys").Array().IsEmpty()
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/ldapx/ldaptest"
+	"go-admin/pkg/util"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLDAP(t *testing.T) {
+	e := tester(t)
+	assert := assert.New(t)
+
+	alice := &ldaptest.Entry{
+		DN:       "uid=alice,ou=people,dc=example,dc=com",
+		Password: "alice-secret",
+		Attributes: map[string][]string{
+			"objectClass": {"person"},
+			"mail":        {"ldap-alice@example.com"},
+			"givenName":   {"Alice"},
+			"sn":          {"Directory"},
+			"cn":          {"Alice Directory"},
+			"memberOf":    {"cn=admins,ou=groups,dc=example,dc=com"},
+		},
+	}
+	server := ldaptest.NewServer(&ldaptest.Entry{DN: "cn=service,dc=example,dc=com", Password: "service-secret"}, alice)
+	defer server.Close()
+
+	var role, localRole model.Role
+	e.POST(baseAPI + "/roles").WithJSON(model.RoleForm{Code: "ldap-admin", Name: "LDAP Admin", Status: model.RoleStatusEnabled}).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &role})
+	e.POST(baseAPI + "/roles").WithJSON(model.RoleForm{Code: "ldap-local", Name: "LDAP Local", Status: model.RoleStatusEnabled}).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &localRole})
+	defer func() {
+		e.DELETE(baseAPI + "/roles/" + role.ID).Expect().Status(http.StatusOK)
+		e.DELETE(baseAPI + "/roles/" + localRole.ID).Expect().Status(http.StatusOK)
+	}()
+
+	ldapConfig := &config.C.General.LDAP
+	oldLDAPConfig := *ldapConfig
+	defer func() { *ldapConfig = oldLDAPConfig }()
+	ldapConfig.Enable = true
+	ldapConfig.URL = server.URL
+	ldapConfig.BindDN = "cn=service,dc=example,dc=com"
+	ldapConfig.BindPassword = "service-secret"
+	ldapConfig.BaseDN = "ou=people,dc=example,dc=com"
+	ldapConfig.AutoCreate = true
+	ldapConfig.GroupRoles = append(ldapConfig.GroupRoles[:0:0], struct {
+		Group  string
+		RoleID string
+	}{Group: "cn=admins,ou=groups,dc=example,dc=com", RoleID: role.ID})
+
+	login := func(email, password string) *httpexpect.Response {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		return e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+			Email:       email,
+			Password:    password,
+			CaptchaID:   captchaID,
+			CaptchaCode: captchaCode,
+		}).Expect()
+	}
+	currentUser := func(resp *httpexpect.Response) *model.User {
+		var token model.LoginToken
+		resp.Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+		var user model.User
+		authTester(t).GET(baseAPI+"/current/user").WithHeader("Authorization", "Bearer "+token.AccessToken).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+		return &user
+	}
+	roleIDs := func(user *model.User) []string {
+		var ids []string
+		for _, item := range user.Roles {
+			ids = append(ids, item.RoleID)
+		}
+		return ids
+	}
+
+	login("ldap-alice@example.com", "wrong").Status(http.StatusBadRequest).
+		JSON().Path("$.error.id").IsEqual(config.ErrInvalidUsernameOrPassword)
+
+	// The user is created on the first login with the roles of its groups
+	user := currentUser(login("ldap-alice@example.com", "alice-secret"))
+	defer func() {
+		e.DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+	assert.Equal("Alice Directory", user.FullName)
+	assert.Equal("Alice", user.FirstName)
+	assert.Equal([]string{role.ID}, roleIDs(user))
+
+	// Mapped roles follow the groups, local roles are kept
+	err := injector.DB.Create(&model.UserRole{ID: util.NewXID(), UserID: user.ID, RoleID: localRole.ID, CreatedAt: time.Now()}).Error
+	assert.Nil(err)
+	alice.Attributes["memberOf"] = nil
+	server.SetEntry(alice)
+	user = currentUser(login("ldap-alice@example.com", "alice-secret"))
+	assert.Equal([]string{localRole.ID}, roleIDs(user))
+
+	// Users of the directory have no local password
+	ldapConfig.Enable = false
+	login("ldap-alice@example.com", "alice-secret").Status(http.StatusBadRequest)
+	ldapConfig.Enable = true
+	ldapConfig.LocalFallback = true
+
+	// Local users not found in the directory login with their local password if the fallback is enabled
+	userFormItem := model.UserForm{
+		Email:     "ldap-local@example.com",
+		FirstName: "Local",
+		LastName:  "User",
+		Password:  hash.MD5String("local-password"),
+		Status:    model.UserStatusActive,
+	}
+	var localUser model.User
+	e.POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &localUser})
+	defer func() {
+		e.DELETE(baseAPI + "/users/" + localUser.ID).Expect().Status(http.StatusOK)
+	}()
+	assert.Equal(localUser.ID, currentUser(login(userFormItem.Email, userFormItem.Password)).ID)
+	ldapConfig.LocalFallback = false
+	login(userFormItem.Email, userFormItem.Password).Status(http.StatusBadRequest)
+
+	// Directory users are not created if it is disabled
+	ldapConfig.AutoCreate = false
+	server.SetEntry(&ldaptest.Entry{
+		DN:         "uid=bob,ou=people,dc=example,dc=com",
+		Password:   "bob-secret",
+		Attributes: map[string][]string{"objectClass": {"person"}, "mail": {"ldap-bob@example.com"}},
+	})
+	login("ldap-bob@example.com", "bob-secret").Status(http.StatusBadRequest)
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestLockout(t *testing.T) {
+	e := tester(t)
+
+	userFormItem := model.UserForm{
+		Email:     "lockout@example.com",
+		FirstName: "Lockout",
+		LastName:  "User",
+		Password:  hash.MD5String("lockout-password"),
+		Status:    model.UserStatusActive,
+	}
+	var user model.User
+	e.POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		e.DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+
+	login := func(password string) *httpexpect.Response {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		return e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+			Email:       userFormItem.Email,
+			Password:    password,
+			CaptchaID:   captchaID,
+			CaptchaCode: captchaCode,
+		}).Expect()
+	}
+
+	maxAttempts := config.C.General.Lockout.MaxAttempts
+	for i := 1; i < maxAttempts; i++ {
+		login("wrong").Status(http.StatusBadRequest)
+	}
+
+	// The last allowed failure locks the email, even the correct password is rejected then
+	resp := login("wrong").Status(http.StatusForbidden)
+	resp.Header("Retry-After").NotEmpty()
+	resp.JSON().Path("$.error.id").IsEqual(config.ErrLoginLocked)
+	login(userFormItem.Password).Status(http.StatusForbidden)
+
+	e.PATCH(baseAPI + "/users/" + user.ID + "/unlock").Expect().Status(http.StatusOK)
+	login(userFormItem.Password).Status(http.StatusOK)
+
+	// Concurrent failures are all counted
+	forms := make([]model.LoginForm, maxAttempts)
+	for i := range forms {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		forms[i] = model.LoginForm{Email: userFormItem.Email, Password: "wrong", CaptchaID: captchaID, CaptchaCode: captchaCode}
+	}
+	var wg sync.WaitGroup
+	for _, form := range forms {
+		wg.Add(1)
+		go func(form model.LoginForm) {
+			defer wg.Done()
+			e.POST(baseAPI + "/login").WithJSON(form).Expect()
+		}(form)
+	}
+	wg.Wait()
+	login(userFormItem.Password).Status(http.StatusForbidden)
+
+	e.PATCH(baseAPI + "/users/" + user.ID + "/unlock").Expect().Status(http.StatusOK)
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginHistory(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+
+	userFormItem := model.UserForm{
+		Email:     "login-history@example.com",
+		FirstName: "Login",
+		LastName:  "History",
+		Password:  hash.MD5String("login-history"),
+		Status:    model.UserStatusActive,
+	}
+	var user model.User
+	tester(t).POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+
+	var alerts []*model.LoginHistory
+	injector.Mods.Auth.AuthAPI.AuthService.LoginHistoryService.AddAlertHook(func(ctx context.Context, item *model.LoginHistory) error {
+		if item.UserID == user.ID {
+			alerts = append(alerts, item)
+		}
+		return nil
+	})
+
+	login := func(password, userAgent string) string {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		resp := e.POST(baseAPI+"/login").WithHeader("User-Agent", userAgent).WithJSON(model.LoginForm{
+			Email:       userFormItem.Email,
+			Password:    password,
+			CaptchaID:   captchaID,
+			CaptchaCode: captchaCode,
+		}).Expect()
+		if password != userFormItem.Password {
+			resp.Status(http.StatusBadRequest)
+			return ""
+		}
+		var token model.LoginToken
+		resp.Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+		return "Bearer " + token.AccessToken
+	}
+
+	login(hash.MD5String("wrong"), "Firefox")
+	bearer := login(userFormItem.Password, "Firefox")
+	assert.Empty(alerts, "the first login is not unusual")
+	login(userFormItem.Password, "Firefox")
+	assert.Empty(alerts)
+	login(userFormItem.Password, "Safari")
+	if assert.Len(alerts, 1) {
+		assert.True(alerts[0].NewUserAgent)
+		assert.False(alerts[0].NewIP)
+	}
+
+	var histories model.LoginHistories
+	e.GET(baseAPI+"/current/login-history").WithHeader("Authorization", bearer).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &histories})
+	if assert.Len(histories, 4) {
+		assert.Equal("Safari", histories[0].UserAgent)
+		assert.True(histories[0].NewUserAgent)
+		assert.Equal(model.LoginMethodPassword, histories[0].Method)
+
+		failed := histories[3]
+		assert.False(failed.Success)
+		assert.Equal("Firefox", failed.UserAgent)
+		assert.Equal("Incorrect email or password", failed.Reason)
+	}
+
+	tester(t).GET(baseAPI+"/users/"+user.ID+"/login-history").WithQuery("success", false).
+		Expect().Status(http.StatusOK).JSON().Path("$.total").IsEqual(1)
+	tester(t).GET(baseAPI + "/users/unknown/login-history").Expect().Status(http.StatusNotFound)
+}
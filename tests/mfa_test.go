@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/crypto/totp"
+	"go-admin/pkg/util"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMFA(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+
+	userFormItem := model.UserForm{
+		Email:     "mfa@example.com",
+		FirstName: "MFA",
+		LastName:  "User",
+		Password:  hash.MD5String("mfa-password"),
+		Status:    model.UserStatusActive,
+	}
+	var user model.User
+	tester(t).POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+
+	login := func() *model.LoginToken {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		var token model.LoginToken
+		e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+			Email:       userFormItem.Email,
+			Password:    userFormItem.Password,
+			CaptchaID:   captchaID,
+			CaptchaCode: captchaCode,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+		return &token
+	}
+	loginMFA := func(mfaToken, code string) *httpexpect.Response {
+		return e.POST(baseAPI + "/login/mfa").WithJSON(model.LoginMFAForm{MFAToken: mfaToken, Code: code}).Expect()
+	}
+
+	token := login()
+	assert.False(token.MFARequired)
+	assert.NotEmpty(token.AccessToken)
+	auth := "Bearer " + token.AccessToken
+
+	// The secret key must be configured to enroll
+	mfaConfig := &config.C.General.MFA
+	oldSecretKey := mfaConfig.SecretKey
+	defer func() { mfaConfig.SecretKey = oldSecretKey }()
+	mfaConfig.SecretKey = ""
+	e.POST(baseAPI+"/current/mfa/enroll").WithHeader("Authorization", auth).Expect().Status(http.StatusBadRequest)
+	mfaConfig.SecretKey = "0123456789abcdef0123456789abcdef"
+
+	// Enroll and confirm the TOTP secret
+	var enrollment model.MFAEnrollment
+	e.POST(baseAPI+"/current/mfa/enroll").WithHeader("Authorization", auth).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &enrollment})
+	assert.NotEmpty(enrollment.Secret)
+	assert.Contains(enrollment.URI, "otpauth://totp/")
+
+	e.POST(baseAPI+"/current/mfa/confirm").WithHeader("Authorization", auth).
+		WithJSON(model.MFACodeForm{Code: "000000"}).Expect().Status(http.StatusBadRequest)
+
+	now := time.Now()
+	code, err := totp.GenerateCode(enrollment.Secret, now)
+	assert.Nil(err)
+	var recovery model.MFARecoveryCodes
+	e.POST(baseAPI+"/current/mfa/confirm").WithHeader("Authorization", auth).
+		WithJSON(model.MFACodeForm{Code: code}).Expect().Status(http.StatusOK).
+		JSON().Decode(&util.ResponseResult{Data: &recovery})
+	assert.Len(recovery.RecoveryCodes, config.C.General.MFA.RecoveryCodes)
+
+	var getUser model.User
+	tester(t).GET(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &getUser})
+	assert.True(getUser.MFAEnabled)
+
+	// Login requires the second factor now
+	token = login()
+	assert.True(token.MFARequired)
+	assert.Empty(token.AccessToken)
+
+	loginMFA(token.MFAToken, "000000").Status(http.StatusBadRequest).JSON().Object().
+		Value("error").Object().Value("id").IsEqual(config.ErrInvalidMFACode)
+	// The code used for the confirmation can not be used again
+	loginMFA(token.MFAToken, code).Status(http.StatusBadRequest)
+
+	nextCode, err := totp.GenerateCode(enrollment.Secret, now.Add(totp.Period*time.Second))
+	assert.Nil(err)
+	var loginToken model.LoginToken
+	loginMFA(token.MFAToken, nextCode).Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &loginToken})
+	assert.NotEmpty(loginToken.AccessToken)
+	loginMFA(token.MFAToken, nextCode).Status(http.StatusBadRequest).JSON().Object().
+		Value("error").Object().Value("id").IsEqual(config.ErrInvalidMFAToken)
+
+	// Recovery codes are single-use
+	token = login()
+	loginMFA(token.MFAToken, recovery.RecoveryCodes[0]).Status(http.StatusOK)
+	token = login()
+	loginMFA(token.MFAToken, recovery.RecoveryCodes[0]).Status(http.StatusBadRequest)
+
+	// The challenge is dropped after too many failures, including the reused recovery code above
+	for i := 2; i < config.C.General.MFA.MaxAttempts; i++ {
+		loginMFA(token.MFAToken, "000000").Status(http.StatusBadRequest).JSON().Object().
+			Value("error").Object().Value("id").IsEqual(config.ErrInvalidMFACode)
+	}
+	loginMFA(token.MFAToken, "000000").Status(http.StatusBadRequest).JSON().Object().
+		Value("error").Object().Value("id").IsEqual(config.ErrInvalidMFAToken)
+	loginMFA(token.MFAToken, recovery.RecoveryCodes[1]).Status(http.StatusBadRequest).JSON().Object().
+		Value("error").Object().Value("id").IsEqual(config.ErrInvalidMFAToken)
+
+	// Only one of the concurrent logins with the same recovery code succeeds
+	tokens := make([]*model.LoginToken, 4)
+	for i := range tokens {
+		tokens[i] = login()
+	}
+	var succeeded int32
+	var wg sync.WaitGroup
+	for _, token := range tokens {
+		wg.Add(1)
+		go func(token *model.LoginToken) {
+			defer wg.Done()
+			if loginMFA(token.MFAToken, recovery.RecoveryCodes[2]).Raw().StatusCode == http.StatusOK {
+				atomic.AddInt32(&succeeded, 1)
+			}
+		}(token)
+	}
+	wg.Wait()
+	assert.Equal(int32(1), succeeded)
+
+	// Disable with a recovery code
+	auth = "Bearer " + loginToken.AccessToken
+	e.POST(baseAPI+"/current/mfa/disable").WithHeader("Authorization", auth).
+		WithJSON(model.MFACodeForm{Code: recovery.RecoveryCodes[1]}).Expect().Status(http.StatusOK)
+	token = login()
+	assert.False(token.MFARequired)
+	assert.NotEmpty(token.AccessToken)
+}
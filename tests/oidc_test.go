@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/oidc/oidctest"
+	"go-admin/pkg/util"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDC(t *testing.T) {
+	e := tester(t)
+	assert := assert.New(t)
+
+	server := oidctest.NewServer("go-admin", "oidc-secret")
+	defer server.Close()
+
+	var role model.Role
+	e.POST(baseAPI + "/roles").WithJSON(model.RoleForm{
+		Code:   "sso-user",
+		Name:   "SSO User",
+		Status: model.RoleStatusEnabled,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &role})
+	defer func() {
+		e.DELETE(baseAPI + "/roles/" + role.ID).Expect().Status(http.StatusOK)
+	}()
+
+	oidcConfig := &config.C.General.OIDC
+	oldOIDCConfig := *oidcConfig
+	defer func() { *oidcConfig = oldOIDCConfig }()
+	oidcConfig.Providers = []config.OIDCProvider{{
+		Name:           "mock",
+		DisplayName:    "Mock",
+		Issuer:         server.URL,
+		ClientID:       "go-admin",
+		ClientSecret:   "oidc-secret",
+		AutoCreate:     true,
+		DefaultRoleIDs: []string{role.ID},
+	}}
+	provider := &oidcConfig.Providers[0]
+
+	var providers []*model.OIDCProvider
+	e.GET(baseAPI + "/oidc/providers").Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &providers})
+	if assert.Len(providers, 1) {
+		assert.Equal("mock", providers[0].Name)
+		assert.Equal(oidcConfig.BaseURL+"/mock/authorize", providers[0].AuthorizeURL)
+	}
+	e.GET(baseAPI + "/oidc/unknown/authorize").Expect().Status(http.StatusNotFound)
+
+	// The login page is routed by the fragment, e.g. /#/login?code=xxx
+	loginQuery := func(req *httpexpect.Request) url.Values {
+		location := req.WithRedirectPolicy(httpexpect.DontFollowRedirects).Expect().Status(http.StatusFound).Header("Location").Raw()
+		assert.True(strings.HasPrefix(location, oidcConfig.LoginURL+"?"), location)
+		query, err := url.ParseQuery(strings.TrimPrefix(location, oidcConfig.LoginURL+"?"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return query
+	}
+
+	// Follow the redirects of the browser and return the query of the login page
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	ssoLoginTenant := func(tenantID string, user oidctest.User) url.Values {
+		server.SetUser(user)
+		authorizeURL := authTester(t).GET(baseAPI+"/oidc/mock/authorize").WithHeader("X-Tenant-ID", tenantID).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).Expect().Status(http.StatusFound).Header("Location").Raw()
+
+		resp, err := client.Get(authorizeURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		callbackURL, err := url.Parse(resp.Header.Get("Location"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(oidcConfig.BaseURL+"/mock/callback", callbackURL.Scheme+"://"+callbackURL.Host+callbackURL.Path)
+
+		return loginQuery(e.GET(baseAPI + "/oidc/mock/callback").WithQueryString(callbackURL.RawQuery))
+	}
+	ssoLogin := func(user oidctest.User) url.Values {
+		return ssoLoginTenant("", user)
+	}
+	login := func(query url.Values) *model.LoginToken {
+		var token model.LoginToken
+		e.POST(baseAPI + "/login/oidc").WithJSON(model.OIDCLoginForm{Code: query.Get("code")}).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+		return &token
+	}
+	currentUser := func(token *model.LoginToken) *model.User {
+		var user model.User
+		authTester(t).GET(baseAPI+"/current/user").WithHeader("Authorization", "Bearer "+token.AccessToken).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+		return &user
+	}
+
+	// The user is created with the default roles on the first login
+	ssoUser := oidctest.User{Subject: "sso-1", Email: "sso@example.com", EmailVerified: true, GivenName: "Single", FamilyName: "Sign-On"}
+	query := ssoLogin(ssoUser)
+	assert.Empty(query.Get("error"))
+	e.POST(baseAPI + "/login/oidc").WithJSON(model.OIDCLoginForm{Code: "invalid"}).
+		Expect().Status(http.StatusBadRequest).JSON().Path("$.error.id").IsEqual(config.ErrInvalidOIDCCode)
+
+	user := currentUser(login(query))
+	defer func() {
+		e.DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+	assert.Equal(ssoUser.Email, user.Email)
+	assert.Equal("Single Sign-On", user.FullName)
+	if assert.Len(user.Roles, 1) {
+		assert.Equal(role.ID, user.Roles[0].RoleID)
+	}
+
+	// The login code can only be used once
+	e.POST(baseAPI + "/login/oidc").WithJSON(model.OIDCLoginForm{Code: query.Get("code")}).
+		Expect().Status(http.StatusBadRequest).JSON().Path("$.error.id").IsEqual(config.ErrInvalidOIDCCode)
+
+	// The identity is linked to the created user, even if the email changes
+	ssoUser.Email = "sso-changed@example.com"
+	assert.Equal(user.ID, currentUser(login(ssoLogin(ssoUser))).ID)
+
+	// Unverified emails are neither linked nor used to create users
+	assert.NotEmpty(ssoLogin(oidctest.User{Subject: "sso-2", Email: "unverified@example.com"}).Get("error"))
+
+	// Existing users are linked by email only if it is enabled
+	userFormItem := model.UserForm{
+		Email:     "sso-link@example.com",
+		FirstName: "Linked",
+		LastName:  "User",
+		Password:  hash.MD5String("sso-link"),
+		Status:    model.UserStatusActive,
+	}
+	var linkedUser model.User
+	e.POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &linkedUser})
+	defer func() {
+		e.DELETE(baseAPI + "/users/" + linkedUser.ID).Expect().Status(http.StatusOK)
+	}()
+
+	linkUser := oidctest.User{Subject: "sso-3", Email: userFormItem.Email, EmailVerified: true}
+	assert.NotEmpty(ssoLogin(linkUser).Get("error"))
+	provider.LinkByEmail = true
+	assert.Equal(linkedUser.ID, currentUser(login(ssoLogin(linkUser))).ID)
+
+	// The users with two-factor authentication must still complete it
+	assert.Nil(injector.DB.Model(new(model.User)).Where("id = ?", linkedUser.ID).Update("mfa_enabled", true).Error)
+	mfaToken := login(ssoLogin(linkUser))
+	assert.True(mfaToken.MFARequired)
+	assert.NotEmpty(mfaToken.MFAToken)
+	assert.Empty(mfaToken.AccessToken)
+
+	// The users are resolved in the tenant the login started in, the email of another tenant isn't linked
+	var tenant model.Tenant
+	e.POST(baseAPI + "/tenants").WithJSON(model.TenantForm{
+		Code:   "sso-tenant",
+		Name:   "SSO tenant",
+		Status: model.TenantStatusEnabled,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &tenant})
+	defer func() {
+		e.DELETE(baseAPI + "/tenants/" + tenant.ID).Expect().Status(http.StatusOK)
+	}()
+	tenantUser := currentUser(login(ssoLoginTenant(tenant.ID, oidctest.User{Subject: "sso-5", Email: userFormItem.Email, EmailVerified: true})))
+	defer func() {
+		e.DELETE(baseAPI + "/users/" + tenantUser.ID).Expect().Status(http.StatusOK)
+	}()
+	assert.NotEqual(linkedUser.ID, tenantUser.ID)
+	assert.Equal(tenant.ID, tenantUser.TenantID)
+	assert.Empty(tenantUser.Roles) // the default role is in the default tenant
+
+	// Unknown users are rejected if the creation is disabled
+	provider.AutoCreate = false
+	assert.NotEmpty(ssoLogin(oidctest.User{Subject: "sso-4", Email: "sso-new@example.com", EmailVerified: true}).Get("error"))
+
+	// A callback without a valid state is rejected
+	query = loginQuery(e.GET(baseAPI+"/oidc/mock/callback").WithQuery("code", "code").WithQuery("state", "invalid"))
+	assert.NotEmpty(query.Get("error"))
+	assert.Empty(query.Get("code"))
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+	"go-admin/pkg/webauthn"
+	"go-admin/pkg/webauthn/webauthntest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasskey(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+
+	userFormItem := model.UserForm{
+		Email:     "passkey@example.com",
+		FirstName: "Pass",
+		LastName:  "Key",
+		Password:  hash.MD5String("passkey"),
+		Status:    model.UserStatusActive,
+	}
+	var user model.User
+	tester(t).POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+
+	captchaID, captchaCode := solveCaptcha(t, e)
+	var token model.LoginToken
+	e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+		Email:       userFormItem.Email,
+		Password:    userFormItem.Password,
+		CaptchaID:   captchaID,
+		CaptchaCode: captchaCode,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+	bearer := "Bearer " + token.AccessToken
+
+	// Register a passkey of the software authenticator
+	authenticator := webauthntest.NewAuthenticator(config.C.General.WebAuthn.Origins[0])
+	var creation struct {
+		ChallengeID string                   `json:"challenge_id"`
+		Options     webauthn.CreationOptions `json:"options"`
+	}
+	e.POST(baseAPI+"/current/passkeys/begin").WithHeader("Authorization", bearer).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &creation})
+	assert.Equal(user.ID, string(creation.Options.PublicKey.User.ID))
+	attestation, err := authenticator.Create(&creation.Options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registerForm := model.PasskeyRegisterForm{ChallengeID: creation.ChallengeID, Name: "laptop", Credential: *attestation}
+	var passkey model.Passkey
+	e.POST(baseAPI+"/current/passkeys").WithHeader("Authorization", bearer).WithJSON(registerForm).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &passkey})
+	assert.Equal("laptop", passkey.Name)
+
+	// The challenge can't be answered twice
+	e.POST(baseAPI+"/current/passkeys").WithHeader("Authorization", bearer).WithJSON(registerForm).
+		Expect().Status(http.StatusBadRequest).JSON().Path("$.error.id").IsEqual(config.ErrInvalidPasskey)
+
+	var passkeys model.Passkeys
+	e.GET(baseAPI+"/current/passkeys").WithHeader("Authorization", bearer).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &passkeys})
+	if assert.Len(passkeys, 1) {
+		assert.Equal(passkey.ID, passkeys[0].ID)
+	}
+
+	type requestChallenge struct {
+		ChallengeID string                  `json:"challenge_id"`
+		Options     webauthn.RequestOptions `json:"options"`
+	}
+	beginLogin := func(email string) *requestChallenge {
+		var request requestChallenge
+		e.POST(baseAPI + "/login/passkey/begin").WithJSON(model.PasskeyLoginBeginForm{Email: email}).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &request})
+		return &request
+	}
+
+	// Login with the passkeys of the user
+	request := beginLogin(userFormItem.Email)
+	assert.Len(request.Options.PublicKey.AllowCredentials, 1)
+	assertion, err := authenticator.Get(&request.Options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loginForm := model.PasskeyLoginFinishForm{ChallengeID: request.ChallengeID, Credential: *assertion}
+	var passkeyToken model.LoginToken
+	e.POST(baseAPI + "/login/passkey/finish").WithJSON(loginForm).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &passkeyToken})
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", "Bearer "+passkeyToken.AccessToken).
+		Expect().Status(http.StatusOK).JSON().Path("$.data.id").IsEqual(user.ID)
+
+	// The assertion can't be replayed
+	e.POST(baseAPI + "/login/passkey/finish").WithJSON(loginForm).
+		Expect().Status(http.StatusBadRequest).JSON().Path("$.error.id").IsEqual(config.ErrInvalidPasskey)
+
+	// Unknown emails are not revealed
+	assert.Empty(beginLogin("unknown-passkey@example.com").Options.PublicKey.AllowCredentials)
+
+	// Login with the discoverable passkey
+	request = beginLogin("")
+	assertion, err = authenticator.Get(&request.Options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.POST(baseAPI + "/login/passkey/finish").WithJSON(model.PasskeyLoginFinishForm{ChallengeID: request.ChallengeID, Credential: *assertion}).
+		Expect().Status(http.StatusOK).JSON().Path("$.data.access_token").String().NotEmpty()
+
+	e.GET(baseAPI+"/current/passkeys").WithHeader("Authorization", bearer).
+		Expect().Status(http.StatusOK).JSON().Path("$.data[0].last_used_at").NotNull()
+
+	// Deleted passkeys can't login
+	e.DELETE(baseAPI+"/current/passkeys/"+passkey.ID).WithHeader("Authorization", bearer).Expect().Status(http.StatusOK)
+	e.DELETE(baseAPI+"/current/passkeys/"+passkey.ID).WithHeader("Authorization", bearer).Expect().Status(http.StatusNotFound)
+	request = beginLogin("")
+	assertion, err = authenticator.Get(&request.Options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.POST(baseAPI + "/login/passkey/finish").WithJSON(model.PasskeyLoginFinishForm{ChallengeID: request.ChallengeID, Credential: *assertion}).
+		Expect().Status(http.StatusBadRequest).JSON().Path("$.error.id").IsEqual(config.ErrInvalidPasskey)
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordRehash(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+
+	userFormItem := model.UserForm{
+		Email:     "rehash@example.com",
+		FirstName: "Re",
+		LastName:  "Hash",
+		Password:  hash.MD5String("rehash"),
+		Status:    model.UserStatusActive,
+	}
+	var user model.User
+	tester(t).POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+
+	storedPassword := func() string {
+		var stored model.User
+		assert.Nil(injector.DB.Select("password").Where("id = ?", user.ID).First(&stored).Error)
+		return stored.Password
+	}
+	login := func(password string) *httpexpect.Response {
+		return loginWithPassword(t, e, userFormItem.Email, password)
+	}
+
+	bcryptPassword := storedPassword()
+	assert.Equal(hash.AlgorithmBcrypt, hash.PasswordAlgorithm(bcryptPassword))
+
+	defaultHasher := hash.DefaultPasswordHasher()
+	defer hash.SetDefaultPasswordHasher(defaultHasher)
+	hash.SetDefaultPasswordHasher(&hash.Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1})
+
+	// A failed login keeps the stored hash
+	login(hash.MD5String("wrong")).Status(http.StatusBadRequest)
+	assert.Equal(bcryptPassword, storedPassword())
+
+	// The hash is upgraded by the next login
+	login(userFormItem.Password).Status(http.StatusOK)
+	argon2Password := storedPassword()
+	assert.Equal(hash.AlgorithmArgon2id, hash.PasswordAlgorithm(argon2Password))
+	login(userFormItem.Password).Status(http.StatusOK)
+	assert.Equal(argon2Password, storedPassword())
+
+	// The upgraded hash is still verified after switching back
+	hash.SetDefaultPasswordHasher(defaultHasher)
+	login(userFormItem.Password).Status(http.StatusOK)
+	assert.Equal(hash.AlgorithmBcrypt, hash.PasswordAlgorithm(storedPassword()))
+}
+
+func TestRootPasswordFormats(t *testing.T) {
+	e := authTester(t)
+
+	rootPassword := config.C.General.Root.Password
+	defer func() {
+		config.C.General.Root.Password = rootPassword
+	}()
+
+	argon2Password, err := (&hash.Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1}).Hash("root-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, encoded := range []string{
+		hash.MD5String("root-secret"), // legacy digest
+		argon2Password,
+	} {
+		config.C.General.Root.Password = encoded
+		loginWithPassword(t, e, config.C.General.Root.Email, "root-secret").Status(http.StatusOK).
+			JSON().Path("$.data.access_token").String().NotEmpty()
+		loginWithPassword(t, e, config.C.General.Root.Email, "wrong-secret").Status(http.StatusBadRequest)
+	}
+}
+
+func loginWithPassword(t *testing.T, e *httpexpect.Expect, email, password string) *httpexpect.Response {
+	captchaID, captchaCode := solveCaptcha(t, e)
+	return e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+		Email:       email,
+		Password:    password,
+		CaptchaID:   captchaID,
+		CaptchaCode: captchaCode,
+	}).Expect()
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+
+	policy := &config.C.General.PasswordPolicy
+	oldPolicy := *policy
+	defer func() { *policy = oldPolicy }()
+	policy.Enable = true
+	policy.MinLength = 8
+	policy.RequireUpper = true
+	policy.RequireLower = true
+	policy.RequireDigit = true
+	policy.HistorySize = 2
+
+	userFormItem := model.UserForm{
+		Email:     "policy@example.com",
+		FirstName: "Password",
+		LastName:  "Policy",
+		Password:  "abc",
+		Status:    model.UserStatusActive,
+	}
+	tester(t).POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusBadRequest).
+		JSON().Path("$.error.id").IsEqual(config.ErrInvalidPassword)
+
+	userFormItem.Password = "Policy-Pass-1"
+	var user model.User
+	tester(t).POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+
+	login := func(password string) *model.LoginToken {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		var token model.LoginToken
+		e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+			Email:       userFormItem.Email,
+			Password:    password,
+			CaptchaID:   captchaID,
+			CaptchaCode: captchaCode,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+		return &token
+	}
+	updatePassword := func(token *model.LoginToken, oldPassword, newPassword string, status int) {
+		e.PUT(baseAPI+"/current/password").WithHeader("Authorization", "Bearer "+token.AccessToken).
+			WithJSON(model.UpdateLoginPassword{OldPassword: oldPassword, NewPassword: newPassword}).
+			Expect().Status(status)
+	}
+
+	token := login("Policy-Pass-1")
+	updatePassword(token, "Policy-Pass-1", "policy@example.com", http.StatusBadRequest)
+	updatePassword(token, "Policy-Pass-1", "Policy-Pass-1", http.StatusBadRequest)
+	updatePassword(token, "Policy-Pass-1", "Policy-Pass-2", http.StatusOK)
+	updatePassword(token, "Policy-Pass-2", "Policy-Pass-3", http.StatusOK)
+	// The last two previous passwords can't be reused, older ones can
+	updatePassword(token, "Policy-Pass-3", "Policy-Pass-1", http.StatusBadRequest)
+	updatePassword(token, "Policy-Pass-3", "Policy-Pass-4", http.StatusOK)
+	updatePassword(token, "Policy-Pass-4", "Policy-Pass-1", http.StatusOK)
+	updatePassword(token, "Policy-Pass-1", "Policy-Pass-3", http.StatusBadRequest)
+
+	// Expired passwords must be changed before the login completes
+	policy.MaxAge = 30
+	changedAt := time.Now().AddDate(0, 0, -31)
+	err := injector.DB.Model(new(model.User)).Where("id=?", user.ID).Update("pwd_changed_at", changedAt).Error
+	assert.Nil(err)
+
+	token = login("Policy-Pass-1")
+	assert.True(token.PasswordExpired)
+	assert.NotEmpty(token.PasswordToken)
+	assert.Empty(token.AccessToken)
+
+	e.POST(baseAPI + "/login/change-password").
+		WithJSON(model.LoginChangePasswordForm{PasswordToken: token.PasswordToken, NewPassword: "Policy-Pass-3"}).
+		Expect().Status(http.StatusBadRequest).JSON().Path("$.error.id").IsEqual(config.ErrPasswordReused)
+
+	var loginToken model.LoginToken
+	e.POST(baseAPI + "/login/change-password").
+		WithJSON(model.LoginChangePasswordForm{PasswordToken: token.PasswordToken, NewPassword: "Policy-Pass-5"}).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &loginToken})
+	assert.NotEmpty(loginToken.AccessToken)
+
+	e.POST(baseAPI + "/login/change-password").
+		WithJSON(model.LoginChangePasswordForm{PasswordToken: token.PasswordToken, NewPassword: "Policy-Pass-6"}).
+		Expect().Status(http.StatusBadRequest).JSON().Path("$.error.id").IsEqual(config.ErrInvalidChangePwdToken)
+
+	token = login("Policy-Pass-5")
+	assert.False(token.PasswordExpired)
+	assert.NotEmpty(token.AccessToken)
+}
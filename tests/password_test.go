@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResetPassword(t *testing.T) {
+	e := tester(t)
+	assert := assert.New(t)
+	mailbox := getMailbox(t)
+
+	userFormItem := model.UserForm{
+		Email:     "forgot@example.com",
+		FirstName: "Forgot",
+		LastName:  "Password",
+		Password:  hash.MD5String("old-password"),
+		Status:    model.UserStatusActive,
+	}
+	var user model.User
+	e.POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	assert.NotEmpty(user.ID)
+
+	var loginToken model.LoginToken
+	loginWithPassword(t, e, user.Email, userFormItem.Password).Status(http.StatusOK).
+		JSON().Decode(&util.ResponseResult{Data: &loginToken})
+
+	// Unknown emails are accepted without sending anything
+	e.POST(baseAPI + "/forgot-password").WithJSON(model.ForgotPasswordForm{Email: "nobody@example.com"}).
+		Expect().Status(http.StatusOK)
+	assert.Equal(0, mailbox.Count("nobody@example.com"))
+
+	// Only the token of the latest email is valid
+	e.POST(baseAPI + "/forgot-password").WithJSON(model.ForgotPasswordForm{Email: user.Email}).
+		Expect().Status(http.StatusOK)
+	oldToken := extractLinkToken(t, mailbox.WaitFor(t, user.Email))
+	e.POST(baseAPI + "/forgot-password").WithJSON(model.ForgotPasswordForm{Email: user.Email}).
+		Expect().Status(http.StatusOK)
+	token := extractLinkToken(t, mailbox.WaitFor(t, user.Email))
+	assert.NotEqual(oldToken, token)
+
+	newPassword := hash.MD5String("new-password")
+	e.POST(baseAPI + "/reset-password").WithJSON(model.ResetPasswordForm{Token: oldToken, Password: newPassword}).
+		Expect().Status(http.StatusBadRequest).JSON().Object().
+		Value("error").Object().Value("id").IsEqual(config.ErrInvalidResetPwdToken)
+
+	e.POST(baseAPI + "/reset-password").WithJSON(model.ResetPasswordForm{Token: token, Password: newPassword}).
+		Expect().Status(http.StatusOK)
+
+	// The sessions logged in with the old password are revoked
+	e.POST(baseAPI + "/refresh-token").WithJSON(model.RefreshTokenForm{RefreshToken: loginToken.RefreshToken}).
+		Expect().Status(http.StatusUnauthorized)
+
+	// The token can only be used once
+	e.POST(baseAPI + "/reset-password").WithJSON(model.ResetPasswordForm{Token: token, Password: newPassword}).
+		Expect().Status(http.StatusBadRequest)
+
+	dbUser, err := injector.Mods.Auth.UserAPI.UserService.UserRepo.Get(context.Background(), user.ID)
+	assert.Nil(err)
+	assert.Nil(hash.CompareHashAndPassword(dbUser.Password, newPassword))
+
+	e.DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/middleware"
+	"go-admin/pkg/util"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermission(t *testing.T) {
+	e := tester(t)
+
+	permissionFormItem := model.PermissionForm{
+		Code:        "user_read_write",
+		Name:        "Read and create users",
+		Description: "Read and create users",
+		HttpMethod:  "GET, post",
+		HttpPath:    "/api/v1/users/{id}",
+	}
+
+	var permission model.Permission
+	e.POST(baseAPI + "/permissions").WithJSON(permissionFormItem).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &permission})
+
+	assert := assert.New(t)
+	assert.NotEmpty(permission.ID)
+	assert.Equal(permissionFormItem.Code, permission.Code)
+	assert.Equal("GET,POST", permission.HttpMethod)
+	assert.Equal(permissionFormItem.HttpPath, permission.HttpPath)
+
+	roleFormItem := model.RoleForm{
+		Code: "user_operator",
+		Name: "User operator",
+		Permissions: model.RolePermissions{
+			{PermissionID: permission.ID},
+		},
+		Status: model.RoleStatusEnabled,
+	}
+
+	var role model.Role
+	e.POST(baseAPI + "/roles").WithJSON(roleFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &role})
+	assert.NotEmpty(role.ID)
+	assert.Equal(len(roleFormItem.Permissions), len(role.Permissions))
+
+	var getRole model.Role
+	e.GET(baseAPI + "/roles/" + role.ID).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &getRole})
+	assert.Equal(len(roleFormItem.Permissions), len(getRole.Permissions))
+
+	// Wait for casbin to reload the policy with the new role
+	casbinx := injector.Mods.Auth.Casbinx
+	assert.Eventually(func() bool {
+		enforcer := casbinx.GetEnforcer()
+		if enforcer == nil {
+			return false
+		}
+		ok, _ := enforcer.Enforce(role.ID, "", "/api/v1/users/1", http.MethodGet)
+		return ok
+	}, 10*time.Second, 100*time.Millisecond)
+
+	r := gin.New()
+	r.Use(middleware.CasbinWithConfig(middleware.CasbinConfig{
+		GetEnforcer: func(c *gin.Context) *casbin.SyncedEnforcer {
+			return casbinx.GetEnforcer()
+		},
+		GetSubjects: func(c *gin.Context) []string {
+			return []string{role.ID}
+		},
+		GetDomain: func(c *gin.Context) string {
+			return ""
+		},
+	}))
+	r.Any(baseAPI+"/users/:id", func(c *gin.Context) {
+		util.ResOK(c)
+	})
+	ce := httpexpect.WithConfig(httpexpect.Config{
+		Client: &http.Client{
+			Transport: httpexpect.NewBinder(r),
+		},
+		Reporter: httpexpect.NewAssertReporter(t),
+	})
+	ce.GET(baseAPI + "/users/1").Expect().Status(http.StatusOK)
+	ce.POST(baseAPI + "/users/1").Expect().Status(http.StatusOK)
+	ce.DELETE(baseAPI + "/users/1").Expect().Status(http.StatusUnauthorized)
+
+	e.DELETE(baseAPI + "/roles/" + role.ID).Expect().Status(http.StatusOK)
+	e.GET(baseAPI + "/roles/" + role.ID).Expect().Status(http.StatusNotFound)
+
+	e.DELETE(baseAPI + "/permissions/" + permission.ID).Expect().Status(http.StatusOK)
+	e.GET(baseAPI + "/permissions/" + permission.ID).Expect().Status(http.StatusNotFound)
+}
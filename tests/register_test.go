@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterVerification(t *testing.T) {
+	e := tester(t)
+	assert := assert.New(t)
+	mailbox := getMailbox(t)
+
+	cfg := &config.C.General.RegisterVerification
+	origin := *cfg
+	defer func() { *cfg = origin }()
+	cfg.Enable = true
+	cfg.ResendInterval = 1
+	cfg.MaxSendsPerDay = 3
+
+	register := func(email string) {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		e.POST(baseAPI + "/register").WithJSON(model.RegisterForm{
+			FirstName:   "Verify",
+			LastName:    "Email",
+			Email:       email,
+			Password:    hash.MD5String("verify-email"),
+			CaptchaID:   captchaID,
+			CaptchaCode: captchaCode,
+		}).Expect().Status(http.StatusOK).JSON().Object().NotContainsKey("data")
+	}
+	login := func(email string) *httpexpect.Response {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		return e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+			Email:       email,
+			Password:    hash.MD5String("verify-email"),
+			CaptchaID:   captchaID,
+			CaptchaCode: captchaCode,
+		}).Expect()
+	}
+	resend := func(email string) *httpexpect.Response {
+		return e.POST(baseAPI + "/resend-verification").WithJSON(model.ResendVerificationForm{Email: email}).Expect()
+	}
+
+	// Registered users are inactive and can not login until the email is verified
+	email := "verify@example.com"
+	register(email)
+	oldToken := extractLinkToken(t, mailbox.WaitFor(t, email))
+	login(email).Status(http.StatusBadRequest).JSON().Object().
+		Value("error").Object().Value("id").IsEqual(config.ErrEmailNotVerified)
+
+	// Resending is limited by the interval, and only the latest token is valid
+	resend(email).Status(http.StatusTooManyRequests).JSON().Object().
+		Value("error").Object().Value("id").IsEqual(config.ErrTooManyVerifyMails)
+	time.Sleep(time.Second)
+	resend(email).Status(http.StatusOK)
+	token := extractLinkToken(t, mailbox.WaitFor(t, email))
+	assert.NotEqual(oldToken, token)
+
+	e.POST(baseAPI + "/verify-email").WithJSON(model.VerifyEmailForm{Token: oldToken}).
+		Expect().Status(http.StatusBadRequest).JSON().Object().
+		Value("error").Object().Value("id").IsEqual(config.ErrInvalidVerifyToken)
+
+	var loginToken model.LoginToken
+	e.POST(baseAPI + "/verify-email").WithJSON(model.VerifyEmailForm{Token: token}).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &loginToken})
+	assert.NotEmpty(loginToken.AccessToken)
+	// The login with the verification link is recorded
+	var histories model.LoginHistories
+	authTester(t).GET(baseAPI+"/current/login-history").WithHeader("Authorization", "Bearer "+loginToken.AccessToken).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &histories})
+	if assert.NotEmpty(histories) {
+		assert.Equal(model.LoginMethodEmail, histories[0].Method)
+		assert.True(histories[0].Success)
+	}
+	e.POST(baseAPI + "/verify-email").WithJSON(model.VerifyEmailForm{Token: token}).
+		Expect().Status(http.StatusBadRequest)
+	login(email).Status(http.StatusOK)
+
+	// Verified and unknown emails are ignored silently
+	resend(email).Status(http.StatusOK)
+	resend("nobody@example.com").Status(http.StatusOK)
+	assert.Equal(0, mailbox.Count(email))
+
+	// The daily limit counts every email sent to the address
+	cfg.ResendInterval = 0
+	email2 := "verify2@example.com"
+	register(email2)
+	_ = mailbox.WaitFor(t, email2)
+	resend(email2).Status(http.StatusOK)
+	_ = mailbox.WaitFor(t, email2)
+	resend(email2).Status(http.StatusOK)
+	_ = mailbox.WaitFor(t, email2)
+	resend(email2).Status(http.StatusTooManyRequests)
+
+	var users []*model.User
+	e.GET(baseAPI+"/users").WithQuery("email", "verify").Expect().Status(http.StatusOK).
+		JSON().Decode(&util.ResponseResult{Data: &users})
+	for _, user := range users {
+		e.DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}
+}
+
+func TestRegisterVerificationTenant(t *testing.T) {
+	e := tester(t)
+	assert := assert.New(t)
+	mailbox := getMailbox(t)
+
+	cfg := &config.C.General.RegisterVerification
+	origin := *cfg
+	defer func() { *cfg = origin }()
+	cfg.Enable = true
+	cfg.ResendInterval = 0
+
+	var tenant model.Tenant
+	e.POST(baseAPI + "/tenants").WithJSON(model.TenantForm{
+		Code:   "verify-tenant",
+		Name:   "Verify Tenant",
+		Status: model.TenantStatusEnabled,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &tenant})
+	defer func() {
+		e.DELETE(baseAPI + "/tenants/" + tenant.ID).Expect().Status(http.StatusOK)
+	}()
+
+	user := &model.User{
+		ID:         util.NewXID(),
+		TenantID:   tenant.ID,
+		Email:      "verify-tenant@example.com",
+		FullName:   "Verify Tenant",
+		Status:     model.UserStatusInactive,
+		Unverified: true,
+		CreatedAt:  time.Now(),
+	}
+	assert.Nil(injector.DB.Create(user).Error)
+	defer func() {
+		assert.Nil(injector.DB.Delete(user).Error)
+	}()
+
+	ctx := util.NewTenantID(context.Background(), tenant.ID)
+	err := injector.Mods.Auth.AuthAPI.AuthService.ResendVerification(ctx, &model.ResendVerificationForm{Email: user.Email})
+	assert.Nil(err)
+	token := extractLinkToken(t, mailbox.WaitFor(t, user.Email))
+
+	// The link is opened in the default tenant, the user is loaded under the tenant of the token
+	loginToken, err := injector.Mods.Auth.AuthAPI.AuthService.VerifyEmail(util.NewTenantID(context.Background(), ""),
+		&model.VerifyEmailForm{Token: token})
+	if assert.Nil(err) {
+		assert.NotEmpty(loginToken.AccessToken)
+	}
+
+	var dbUser model.User
+	assert.Nil(injector.DB.Where("id = ?", user.ID).First(&dbUser).Error)
+	assert.Equal(model.UserStatusActive, dbUser.Status)
+	assert.False(dbUser.Unverified)
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleAssignment(t *testing.T) {
+	e := tester(t)
+	assert := assert.New(t)
+
+	createRole := func(code string) (*model.Role, *model.Menu) {
+		var menu model.Menu
+		e.POST(baseAPI + "/menus").WithJSON(model.MenuForm{
+			Code:   code,
+			Name:   code,
+			Type:   "page",
+			Status: model.MenuStatusEnabled,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &menu})
+		var role model.Role
+		e.POST(baseAPI + "/roles").WithJSON(model.RoleForm{
+			Code:   code,
+			Name:   code,
+			Status: model.RoleStatusEnabled,
+			Menus:  model.RoleMenus{{MenuID: menu.ID}},
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &role})
+		return &role, &menu
+	}
+	endingRole, endingMenu := createRole("assignment-ending")
+	startingRole, startingMenu := createRole("assignment-starting")
+	defer func() {
+		for _, item := range []*model.Role{endingRole, startingRole} {
+			e.DELETE(baseAPI + "/roles/" + item.ID).Expect().Status(http.StatusOK)
+		}
+		for _, item := range []*model.Menu{endingMenu, startingMenu} {
+			e.DELETE(baseAPI + "/menus/" + item.ID).Expect().Status(http.StatusOK)
+		}
+	}()
+
+	now := time.Now()
+	boundary := now.Add(2 * time.Second)
+	userForm := model.UserForm{
+		Email:     "assignment-user@example.com",
+		FirstName: "Assignment",
+		LastName:  "User",
+		Password:  hash.MD5String("assignment"),
+		Status:    model.UserStatusActive,
+		Roles: model.UserRoles{
+			{RoleID: endingRole.ID, ValidFrom: &boundary, ValidUntil: &now},
+		},
+	}
+	e.POST(baseAPI + "/users").WithJSON(userForm).Expect().Status(http.StatusBadRequest)
+
+	userForm.Roles = model.UserRoles{
+		{RoleID: endingRole.ID, ValidUntil: &boundary},
+		{RoleID: startingRole.ID, ValidFrom: &boundary},
+	}
+	var user model.User
+	e.POST(baseAPI + "/users").WithJSON(userForm).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		e.DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+
+	var getUser model.User
+	e.GET(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &getUser})
+	for _, userRole := range getUser.Roles {
+		if userRole.RoleID == endingRole.ID && assert.NotNil(userRole.ValidUntil) {
+			assert.True(boundary.Equal(*userRole.ValidUntil))
+		}
+	}
+
+	ae := authTester(t)
+	captchaID, captchaCode := solveCaptcha(t, ae)
+	var token model.LoginToken
+	ae.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+		Email:       userForm.Email,
+		Password:    userForm.Password,
+		CaptchaID:   captchaID,
+		CaptchaCode: captchaCode,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+	authorization := "Bearer " + token.AccessToken
+
+	queryMenuIDs := func() []string {
+		var menus model.Menus
+		ae.GET(baseAPI+"/current/menus").WithHeader("Authorization", authorization).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &menus})
+		var ids []string
+		for _, item := range menus {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+	assert.Equal([]string{endingMenu.ID}, queryMenuIDs())
+
+	// The roles of the user are switched by the sweeper once the boundary has passed
+	time.Sleep(time.Until(boundary) + 100*time.Millisecond)
+	assert.NoError(injector.Mods.Auth.RoleSweeper.Sweep(context.Background(), time.Now()))
+	assert.Equal([]string{startingMenu.ID}, queryMenuIDs())
+
+	// The user is only cached until its next boundary, even if the sweeper misses it
+	boundary = time.Now().Add(2 * time.Second)
+	userForm.Roles = model.UserRoles{
+		{RoleID: startingRole.ID, ValidUntil: &boundary},
+	}
+	e.PUT(baseAPI + "/users/" + user.ID).WithJSON(userForm).Expect().Status(http.StatusOK)
+	assert.Equal([]string{startingMenu.ID}, queryMenuIDs())
+	time.Sleep(time.Until(boundary) + 100*time.Millisecond)
+	assert.Empty(queryMenuIDs())
+}
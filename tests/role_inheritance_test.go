@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleInheritance(t *testing.T) {
+	e := tester(t)
+	assert := assert.New(t)
+
+	createMenu := func(code, path string) *model.Menu {
+		var menu model.Menu
+		e.POST(baseAPI + "/menus").WithJSON(model.MenuForm{
+			Code:      code,
+			Name:      code,
+			Type:      "page",
+			Status:    model.MenuStatusEnabled,
+			Resources: model.MenuResources{{Method: http.MethodGet, Path: path}},
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &menu})
+		return &menu
+	}
+	baseMenu := createMenu("inherit-base", "/api/v1/inherit-base")
+	childMenu := createMenu("inherit-child", "/api/v1/inherit-child")
+	defer func() {
+		for _, item := range []*model.Menu{baseMenu, childMenu} {
+			e.DELETE(baseAPI + "/menus/" + item.ID).Expect().Status(http.StatusOK)
+		}
+	}()
+
+	baseForm := model.RoleForm{
+		Code:   "inherit-base",
+		Name:   "Inherit base",
+		Status: model.RoleStatusEnabled,
+		Menus:  model.RoleMenus{{MenuID: baseMenu.ID}},
+	}
+	var base model.Role
+	e.POST(baseAPI + "/roles").WithJSON(baseForm).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &base})
+
+	e.POST(baseAPI + "/roles").WithJSON(model.RoleForm{
+		Code:      "inherit-unknown",
+		Name:      "Inherit unknown",
+		Status:    model.RoleStatusEnabled,
+		ParentIDs: []string{util.NewXID()},
+	}).Expect().Status(http.StatusBadRequest)
+
+	var child model.Role
+	e.POST(baseAPI + "/roles").WithJSON(model.RoleForm{
+		Code:      "inherit-child",
+		Name:      "Inherit child",
+		Status:    model.RoleStatusEnabled,
+		Menus:     model.RoleMenus{{MenuID: childMenu.ID}},
+		ParentIDs: []string{base.ID},
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &child})
+	assert.Equal([]string{base.ID}, child.ParentIDs)
+	defer func() {
+		e.DELETE(baseAPI + "/roles/" + child.ID).Expect().Status(http.StatusOK)
+	}()
+
+	// The inheritance can't be circular
+	baseForm.ParentIDs = []string{base.ID}
+	e.PUT(baseAPI + "/roles/" + base.ID).WithJSON(baseForm).Expect().Status(http.StatusBadRequest)
+	baseForm.ParentIDs = []string{child.ID}
+	e.PUT(baseAPI + "/roles/" + base.ID).WithJSON(baseForm).Expect().Status(http.StatusBadRequest)
+	baseForm.ParentIDs = nil
+
+	// The child role is allowed the resources of its parent, but not the other way around
+	casbinx := injector.Mods.Auth.Casbinx
+	enforce := func(sub, obj string) func() bool {
+		return func() bool {
+			ok, _ := casbinx.GetEnforcer().Enforce(sub, "", obj, http.MethodGet)
+			return ok
+		}
+	}
+	eventually := func(cond func() bool) {
+		assert.Eventually(cond, 10*time.Second, 100*time.Millisecond)
+	}
+	eventually(enforce(child.ID, "/api/v1/inherit-base"))
+	eventually(enforce(child.ID, "/api/v1/inherit-child"))
+	assert.False(enforce(base.ID, "/api/v1/inherit-child")())
+
+	// The user of the child role gets the menus of the parent
+	userForm := model.UserForm{
+		Email:     "inherit-user@example.com",
+		FirstName: "Inherit",
+		LastName:  "User",
+		Password:  hash.MD5String("inherit"),
+		Status:    model.UserStatusActive,
+		Roles:     model.UserRoles{{RoleID: child.ID}},
+	}
+	var user model.User
+	e.POST(baseAPI + "/users").WithJSON(userForm).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		e.DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+
+	ae := authTester(t)
+	captchaID, captchaCode := solveCaptcha(t, ae)
+	var token model.LoginToken
+	ae.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+		Email:       userForm.Email,
+		Password:    userForm.Password,
+		CaptchaID:   captchaID,
+		CaptchaCode: captchaCode,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+	authorization := "Bearer " + token.AccessToken
+
+	queryMenuIDs := func() []string {
+		var menus model.Menus
+		ae.GET(baseAPI+"/current/menus").WithHeader("Authorization", authorization).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &menus})
+		var ids []string
+		for _, item := range menus {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+	assert.ElementsMatch([]string{baseMenu.ID, childMenu.ID}, queryMenuIDs())
+
+	var userInfo model.User
+	ae.GET(baseAPI+"/current/user").WithHeader("Authorization", authorization).
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &userInfo})
+	if assert.Len(userInfo.InheritedRoles, 1) {
+		assert.Equal(base.ID, userInfo.InheritedRoles[0].RoleID)
+		assert.Equal(base.Name, userInfo.InheritedRoles[0].RoleName)
+	}
+
+	// Disabled parents are not inherited
+	baseForm.Status = model.RoleStatusDisabled
+	e.PUT(baseAPI + "/roles/" + base.ID).WithJSON(baseForm).Expect().Status(http.StatusOK)
+	eventually(func() bool { return !enforce(child.ID, "/api/v1/inherit-base")() })
+	assert.ElementsMatch([]string{childMenu.ID}, queryMenuIDs())
+
+	// Deleting the parent removes it from its children
+	e.DELETE(baseAPI + "/roles/" + base.ID).Expect().Status(http.StatusOK)
+	e.GET(baseAPI + "/roles/" + child.ID).Expect().Status(http.StatusOK).JSON().Path("$.data.parent_ids").Array().IsEmpty()
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+
+	userFormItem := model.UserForm{
+		Email:     "session@example.com",
+		FirstName: "Session",
+		LastName:  "User",
+		Password:  hash.MD5String("session"),
+		Status:    model.UserStatusActive,
+	}
+	var user model.User
+	tester(t).POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+
+	login := func(userAgent string) *model.LoginToken {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		var token model.LoginToken
+		e.POST(baseAPI+"/login").WithHeader("User-Agent", userAgent).WithJSON(model.LoginForm{
+			Email:       userFormItem.Email,
+			Password:    userFormItem.Password,
+			CaptchaID:   captchaID,
+			CaptchaCode: captchaCode,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+		return &token
+	}
+	querySessions := func(token *model.LoginToken) model.Sessions {
+		var sessions model.Sessions
+		e.GET(baseAPI+"/current/sessions").WithHeader("Authorization", "Bearer "+token.AccessToken).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &sessions})
+		return sessions
+	}
+	checkToken := func(token *model.LoginToken, status int) {
+		e.GET(baseAPI+"/current/user").WithHeader("Authorization", "Bearer "+token.AccessToken).
+			Expect().Status(status)
+	}
+
+	firefox := login("Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/115.0")
+	chrome := login("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/116.0.0.0 Safari/537.36")
+
+	sessions := querySessions(firefox)
+	assert.Len(sessions, 2)
+	devices := make(map[string]bool)
+	for _, session := range sessions {
+		devices[session.Device] = session.Current
+	}
+	assert.Equal(map[string]bool{"Firefox on Linux": true, "Chrome on Windows": false}, devices)
+
+	// Revoke all other sessions
+	e.DELETE(baseAPI+"/current/sessions").WithHeader("Authorization", "Bearer "+firefox.AccessToken).
+		Expect().Status(http.StatusOK)
+	checkToken(chrome, http.StatusUnauthorized)
+	e.POST(baseAPI + "/refresh-token").WithJSON(model.RefreshTokenForm{RefreshToken: chrome.RefreshToken}).
+		Expect().Status(http.StatusUnauthorized)
+	checkToken(firefox, http.StatusOK)
+
+	// Revoke one session
+	curl := login("curl/8.0.1")
+	sessions = querySessions(firefox)
+	assert.Len(sessions, 2)
+	for _, session := range sessions {
+		if !session.Current {
+			assert.Equal("curl", session.Device)
+			e.DELETE(baseAPI+"/current/sessions/"+session.ID).WithHeader("Authorization", "Bearer "+firefox.AccessToken).
+				Expect().Status(http.StatusOK)
+		}
+	}
+	checkToken(curl, http.StatusUnauthorized)
+	e.DELETE(baseAPI+"/current/sessions/unknown").WithHeader("Authorization", "Bearer "+firefox.AccessToken).
+		Expect().Status(http.StatusNotFound)
+
+	// Admins can list and revoke the sessions of users
+	var userSessions model.Sessions
+	tester(t).GET(baseAPI + "/users/" + user.ID + "/sessions").Expect().Status(http.StatusOK).
+		JSON().Decode(&util.ResponseResult{Data: &userSessions})
+	assert.Len(userSessions, 1)
+	tester(t).DELETE(baseAPI + "/users/" + user.ID + "/sessions/" + userSessions[0].ID).Expect().Status(http.StatusOK)
+	checkToken(firefox, http.StatusUnauthorized)
+
+	// Deactivating and deleting the user revokes all sessions
+	token := login("curl/8.0.1")
+	userFormItem.Status = model.UserStatusInactive
+	tester(t).PUT(baseAPI + "/users/" + user.ID).WithJSON(userFormItem).Expect().Status(http.StatusOK)
+	checkToken(token, http.StatusUnauthorized)
+	tester(t).GET(baseAPI + "/users/" + user.ID + "/sessions").Expect().Status(http.StatusOK).
+		JSON().Object().Value("data").IsNull()
+
+	userFormItem.Status = model.UserStatusActive
+	tester(t).PUT(baseAPI + "/users/" + user.ID).WithJSON(userFormItem).Expect().Status(http.StatusOK)
+	token = login("curl/8.0.1")
+	tester(t).DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	checkToken(token, http.StatusUnauthorized)
+	e.POST(baseAPI + "/refresh-token").WithJSON(model.RefreshTokenForm{RefreshToken: token.RefreshToken}).
+		Expect().Status(http.StatusUnauthorized)
+}
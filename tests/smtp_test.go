@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pkgmail "go-admin/pkg/mail"
+)
+
+var (
+	mailbox     *smtpStub
+	mailboxOnce sync.Once
+)
+
+// A local SMTP stand-in that accepts every message and keeps it in memory,
+// it is registered as the global mail sender the first time it is used.
+type smtpStub struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	messages []*mail.Message
+	bodies   []string
+}
+
+func getMailbox(t *testing.T) *smtpStub {
+	mailboxOnce.Do(func() {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		mailbox = &smtpStub{ln: ln}
+		go mailbox.serve()
+
+		pkgmail.SetSender(&pkgmail.SmtpSender{
+			SmtpHost: "127.0.0.1",
+			Port:     ln.Addr().(*net.TCPAddr).Port,
+			FromName: "go-admin",
+			FromMail: "noreply@example.com",
+		})
+	})
+	return mailbox
+}
+
+func (s *smtpStub) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStub) handle(conn net.Conn) {
+	defer conn.Close()
+	tc := textproto.NewConn(conn)
+	_ = tc.PrintfLine("220 localhost ESMTP stub")
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+		case "EHLO", "HELO":
+			_ = tc.PrintfLine("250 localhost")
+		case "DATA":
+			_ = tc.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tc.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.store(string(data))
+			_ = tc.PrintfLine("250 OK")
+		case "QUIT":
+			_ = tc.PrintfLine("221 Bye")
+			return
+		default:
+			_ = tc.PrintfLine("250 OK")
+		}
+	}
+}
+
+func (s *smtpStub) store(raw string) {
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		return
+	}
+
+	var body []byte
+	if strings.EqualFold(msg.Header.Get("Content-Transfer-Encoding"), "base64") {
+		body, _ = io.ReadAll(base64.NewDecoder(base64.StdEncoding, bufio.NewReader(msg.Body)))
+	} else {
+		body, _ = io.ReadAll(msg.Body)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+	s.bodies = append(s.bodies, string(body))
+}
+
+// Wait for the next message sent to the address and return its decoded body.
+func (s *smtpStub) WaitFor(t *testing.T, to string) string {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		for i, msg := range s.messages {
+			if strings.Contains(msg.Header.Get("To"), to) {
+				body := s.bodies[i]
+				s.messages = append(s.messages[:i], s.messages[i+1:]...)
+				s.bodies = append(s.bodies[:i], s.bodies[i+1:]...)
+				s.mu.Unlock()
+				return body
+			}
+		}
+		s.mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("No email sent to %s", to)
+	return ""
+}
+
+// Count the messages sent to the address that were not consumed yet.
+func (s *smtpStub) Count(to string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int
+	for _, msg := range s.messages {
+		if strings.Contains(msg.Header.Get("To"), to) {
+			n++
+		}
+	}
+	return n
+}
+
+var linkTokenRegexp = regexp.MustCompile(`href="([^"]+)"`)
+
+// Extract the query parameter `token` of the first link in the email body.
+func extractLinkToken(t *testing.T, body string) string {
+	matches := linkTokenRegexp.FindStringSubmatch(body)
+	if len(matches) != 2 {
+		t.Fatalf("No link in email body: %s", body)
+	}
+	link, err := url.Parse(matches[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The page may be a hash route, the query is part of the fragment
+	query := link.RawQuery
+	if query == "" {
+		if _, q, ok := strings.Cut(link.Fragment, "?"); ok {
+			query = q
+		}
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := values.Get("token")
+	if token == "" {
+		t.Fatalf("No token in link: %s", matches[1])
+	}
+	return token
+}
@@ -0,0 +1,256 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go-admin/internal/config"
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/logging"
+	"go-admin/pkg/middleware"
+	"go-admin/pkg/util"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+
+	createTenant := func(code string) *model.Tenant {
+		var tenant model.Tenant
+		tester(t).POST(baseAPI + "/tenants").WithJSON(model.TenantForm{
+			Code:   code,
+			Name:   code,
+			Status: model.TenantStatusEnabled,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &tenant})
+		return &tenant
+	}
+	tenantA := createTenant("tenant-a")
+	tenantB := createTenant("tenant-b")
+	defer func() {
+		for _, item := range []*model.Tenant{tenantA, tenantB} {
+			tester(t).DELETE(baseAPI + "/tenants/" + item.ID).Expect().Status(http.StatusOK)
+		}
+	}()
+	tester(t).POST(baseAPI + "/tenants").WithJSON(model.TenantForm{
+		Code:   tenantA.Code,
+		Name:   "Duplicated",
+		Status: model.TenantStatusEnabled,
+	}).Expect().Status(http.StatusBadRequest)
+
+	login := func(tenantID, email, password string) string {
+		captchaID, captchaCode := solveCaptcha(t, e)
+		var token model.LoginToken
+		e.POST(baseAPI+"/login").WithHeader("X-Tenant-ID", tenantID).WithJSON(model.LoginForm{
+			Email:       email,
+			Password:    password,
+			CaptchaID:   captchaID,
+			CaptchaCode: captchaCode,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+		return "Bearer " + token.AccessToken
+	}
+
+	rootConfig := &config.C.General.Root
+	oldRootPassword := rootConfig.Password
+	defer func() { rootConfig.Password = oldRootPassword }()
+	rootConfig.Password = hash.MD5String("tenant-root")
+	root := login("", rootConfig.Email, "tenant-root")
+
+	// Root manages the data of a tenant with the tenant header
+	var menu model.Menu
+	e.POST(baseAPI+"/menus").WithHeader("Authorization", root).WithHeader("X-Tenant-ID", tenantA.ID).WithJSON(model.MenuForm{
+		Code:      "tenant-menu",
+		Name:      "Tenant menu",
+		Type:      "page",
+		Status:    model.MenuStatusEnabled,
+		Resources: model.MenuResources{{Method: http.MethodGet, Path: "/api/v1/tenant-resources"}},
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &menu})
+	assert.Equal(tenantA.ID, menu.TenantID)
+
+	var role model.Role
+	e.POST(baseAPI+"/roles").WithHeader("Authorization", root).WithHeader("X-Tenant-ID", tenantA.ID).WithJSON(model.RoleForm{
+		Code:   "tenant-role",
+		Name:   "Tenant role",
+		Status: model.RoleStatusEnabled,
+		Menus:  model.RoleMenus{{MenuID: menu.ID}},
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &role})
+	assert.Equal(tenantA.ID, role.TenantID)
+
+	createUser := func(tenantID string, roles model.UserRoles) *model.User {
+		var user model.User
+		e.POST(baseAPI+"/users").WithHeader("Authorization", root).WithHeader("X-Tenant-ID", tenantID).WithJSON(model.UserForm{
+			Email:     "tenant-user@example.com",
+			FirstName: "Tenant",
+			LastName:  "User",
+			Password:  hash.MD5String("tenant"),
+			Status:    model.UserStatusActive,
+			Roles:     roles,
+		}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+		return &user
+	}
+	// The same email can be used in different tenants
+	userA := createUser(tenantA.ID, model.UserRoles{{RoleID: role.ID}})
+	userB := createUser(tenantB.ID, nil)
+	defer func() {
+		for _, item := range []*model.User{userA, userB} {
+			tester(t).DELETE(baseAPI + "/users/" + item.ID).Expect().Status(http.StatusOK)
+		}
+		tester(t).DELETE(baseAPI + "/roles/" + role.ID).Expect().Status(http.StatusOK)
+		tester(t).DELETE(baseAPI + "/menus/" + menu.ID).Expect().Status(http.StatusOK)
+	}()
+
+	// The roles of another tenant can't be assigned
+	e.POST(baseAPI+"/users").WithHeader("Authorization", root).WithHeader("X-Tenant-ID", tenantB.ID).WithJSON(model.UserForm{
+		Email:     "tenant-foreign-role@example.com",
+		FirstName: "Tenant",
+		LastName:  "User",
+		Password:  hash.MD5String("tenant"),
+		Status:    model.UserStatusActive,
+		Roles:     model.UserRoles{{RoleID: role.ID}},
+	}).Expect().Status(http.StatusBadRequest)
+	e.PUT(baseAPI+"/users/"+userB.ID).WithHeader("Authorization", root).WithHeader("X-Tenant-ID", tenantB.ID).WithJSON(model.UserForm{
+		Email:     userB.Email,
+		FirstName: "Tenant",
+		LastName:  "User",
+		Password:  hash.MD5String("tenant"),
+		Status:    model.UserStatusActive,
+		Roles:     model.UserRoles{{RoleID: role.ID}},
+	}).Expect().Status(http.StatusBadRequest)
+
+	queryUserIDs := func(authorization, tenantID string) []string {
+		var users model.Users
+		e.GET(baseAPI+"/users").WithHeader("Authorization", authorization).WithHeader("X-Tenant-ID", tenantID).
+			WithQuery("email", "tenant-user@").
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &users})
+		return users.ToIDs()
+	}
+	assert.Equal([]string{userA.ID}, queryUserIDs(root, tenantA.ID))
+	assert.Equal([]string{userB.ID}, queryUserIDs(root, tenantB.ID))
+	assert.ElementsMatch([]string{userA.ID, userB.ID}, queryUserIDs(root, ""))
+	e.GET(baseAPI+"/roles/"+role.ID).WithHeader("Authorization", root).WithHeader("X-Tenant-ID", tenantB.ID).
+		Expect().Status(http.StatusNotFound)
+
+	// Users log in to their tenant and can't switch to another one with the header
+	captchaID, captchaCode := solveCaptcha(t, e)
+	e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+		Email:       userA.Email,
+		Password:    hash.MD5String("tenant"),
+		CaptchaID:   captchaID,
+		CaptchaCode: captchaCode,
+	}).Expect().Status(http.StatusBadRequest)
+	tokenA := login(tenantA.ID, userA.Email, hash.MD5String("tenant"))
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", tokenA).
+		Expect().Status(http.StatusOK).JSON().Path("$.data.id").IsEqual(userA.ID)
+	assert.Equal([]string{userA.ID}, queryUserIDs(tokenA, tenantB.ID))
+
+	// Tenants are managed across tenants only
+	e.GET(baseAPI+"/tenants").WithHeader("Authorization", tokenA).Expect().Status(http.StatusForbidden)
+	e.GET(baseAPI+"/tenants").WithHeader("Authorization", root).WithHeader("X-Tenant-ID", tenantA.ID).
+		Expect().Status(http.StatusForbidden)
+	e.GET(baseAPI+"/tenants/"+tenantA.ID).WithHeader("Authorization", root).Expect().Status(http.StatusOK)
+
+	// The menus of the default tenant are shared, but only changed in the default tenant
+	var sharedMenu model.Menu
+	tester(t).POST(baseAPI + "/menus").WithJSON(model.MenuForm{
+		Code:   "tenant-shared",
+		Name:   "Shared menu",
+		Type:   "page",
+		Status: model.MenuStatusEnabled,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &sharedMenu})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/menus/" + sharedMenu.ID).Expect().Status(http.StatusOK)
+	}()
+	e.GET(baseAPI+"/menus/"+sharedMenu.ID).WithHeader("Authorization", tokenA).Expect().Status(http.StatusOK)
+	e.DELETE(baseAPI+"/menus/"+sharedMenu.ID).WithHeader("Authorization", tokenA).Expect().Status(http.StatusForbidden)
+	tester(t).GET(baseAPI + "/menus/" + menu.ID).Expect().Status(http.StatusOK)
+
+	// The policies of the role are in the domain of its tenant
+	casbinx := injector.Mods.Auth.Casbinx
+	assert.Eventually(func() bool {
+		ok, _ := casbinx.GetEnforcer().Enforce(role.ID, tenantA.ID, "/api/v1/tenant-resources", http.MethodGet)
+		return ok
+	}, 10*time.Second, 100*time.Millisecond)
+	ok, _ := casbinx.GetEnforcer().Enforce(role.ID, "", "/api/v1/tenant-resources", http.MethodGet)
+	assert.False(ok)
+
+	// Tenants with users can't be deleted
+	tester(t).DELETE(baseAPI + "/tenants/" + tenantA.ID).Expect().Status(http.StatusBadRequest)
+
+	// Disabling the tenant logs out its users
+	tester(t).PUT(baseAPI + "/tenants/" + tenantA.ID).WithJSON(model.TenantForm{
+		Code:   tenantA.Code,
+		Name:   tenantA.Name,
+		Status: model.TenantStatusDisabled,
+	}).Expect().Status(http.StatusOK)
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", tokenA).Expect().Status(http.StatusUnauthorized)
+	captchaID, captchaCode = solveCaptcha(t, e)
+	e.POST(baseAPI+"/login").WithHeader("X-Tenant-ID", tenantA.ID).WithJSON(model.LoginForm{
+		Email:       userA.Email,
+		Password:    hash.MD5String("tenant"),
+		CaptchaID:   captchaID,
+		CaptchaCode: captchaCode,
+	}).Expect().Status(http.StatusBadRequest)
+}
+
+func TestTenantHeaderAfterAuth(t *testing.T) {
+	engine := gin.New()
+	engine.Use(middleware.Tenant())
+	engine.Use(middleware.AuthWithConfig(middleware.AuthConfig{
+		SkippedPathPrefixes: []string{"/public"},
+		ParseUserID:         injector.Mods.Auth.AuthAPI.AuthService.ParseUserID,
+		RootID:              config.C.General.Root.ID,
+	}))
+	probe := func(c *gin.Context) {
+		ctx := c.Request.Context()
+		tenantID, _ := util.FromTenantID(ctx)
+		c.JSON(http.StatusOK, gin.H{"tenant": tenantID, "log": logging.FromTenantID(ctx)})
+	}
+	engine.GET("/probe", probe)
+	engine.GET("/public", probe)
+	e := httpexpect.WithConfig(httpexpect.Config{
+		Client:   &http.Client{Transport: httpexpect.NewBinder(engine)},
+		Reporter: httpexpect.NewAssertReporter(t),
+	})
+
+	var user model.User
+	tester(t).POST(baseAPI + "/users").WithJSON(model.UserForm{
+		Email:     "tenant-header@example.com",
+		FirstName: "Tenant",
+		LastName:  "Header",
+		Password:  hash.MD5String("tenant-header"),
+		Status:    model.UserStatusActive,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+	var token model.LoginToken
+	loginWithPassword(t, authTester(t), user.Email, hash.MD5String("tenant-header")).
+		Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+
+	// The user of the default tenant can't switch the tenant of its data or its logs with the header
+	probed := e.GET("/probe").WithHeader("Authorization", "Bearer "+token.AccessToken).WithHeader("X-Tenant-ID", "forged").
+		Expect().Status(http.StatusOK).JSON().Object()
+	probed.Value("tenant").IsEqual("")
+	probed.Value("log").IsEqual("")
+
+	// The header is honored for root and the routes without authentication
+	rootConfig := &config.C.General.Root
+	oldRootPassword := rootConfig.Password
+	defer func() { rootConfig.Password = oldRootPassword }()
+	rootConfig.Password = hash.MD5String("tenant-header-root")
+	var rootToken model.LoginToken
+	loginWithPassword(t, authTester(t), rootConfig.Email, "tenant-header-root").
+		Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &rootToken})
+	probed = e.GET("/probe").WithHeader("Authorization", "Bearer "+rootToken.AccessToken).WithHeader("X-Tenant-ID", "tenant-x").
+		Expect().Status(http.StatusOK).JSON().Object()
+	probed.Value("tenant").IsEqual("tenant-x")
+	probed.Value("log").IsEqual("tenant-x")
+	probed = e.GET("/public").WithHeader("X-Tenant-ID", "tenant-x").Expect().Status(http.StatusOK).JSON().Object()
+	probed.Value("tenant").IsEqual("tenant-x")
+	probed.Value("log").IsEqual("tenant-x")
+}
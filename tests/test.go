@@ -4,10 +4,12 @@ import (
 	"context"
 	"net/http"
 	"os"
+	"sync"
 	"testing"
 
 	"go-admin/internal/config"
 	"go-admin/internal/wirex"
+	"go-admin/pkg/middleware"
 
 	"github.com/gavv/httpexpect/v2"
 	"github.com/gin-gonic/gin"
@@ -18,15 +20,21 @@ const (
 )
 
 var (
-	app *gin.Engine
+	app      *gin.Engine
+	injector *wirex.Injector
+
+	authApp     *gin.Engine
+	authAppOnce sync.Once
 )
 
 func init() {
 	config.MustLoad("")
+	config.C.General.WorkDir = "../configs"
 
 	_ = os.RemoveAll(config.C.Storage.DB.DSN)
 	ctx := context.Background()
-	injector, _, err := wirex.BuildInjector(ctx)
+	var err error
+	injector, _, err = wirex.BuildInjector(ctx)
 	if err != nil {
 		panic(err)
 	}
@@ -54,3 +62,31 @@ func tester(t *testing.T) *httpexpect.Expect {
 		},
 	})
 }
+
+// Same as tester, but the routes require an access token like in the server,
+// use it to test the APIs of the current user.
+func authTester(t *testing.T) *httpexpect.Expect {
+	authAppOnce.Do(func() {
+		authApp = gin.New()
+		authApp.Use(middleware.Tenant())
+		authApp.Use(middleware.AuthWithConfig(middleware.AuthConfig{
+			SkippedPathPrefixes: []string{baseAPI + "/captcha/", baseAPI + "/login", baseAPI + "/refresh-token", baseAPI + "/accept-invitation", baseAPI + "/oidc/"},
+			ParseUserID:         injector.Mods.Auth.AuthAPI.AuthService.ParseUserID,
+			RootID:              config.C.General.Root.ID,
+		}))
+		if err := injector.Mods.RegisterRouters(context.Background(), authApp); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	return httpexpect.WithConfig(httpexpect.Config{
+		Client: &http.Client{
+			Transport: httpexpect.NewBinder(authApp),
+			Jar:       httpexpect.NewCookieJar(),
+		},
+		Reporter: httpexpect.NewAssertReporter(t),
+		Printers: []httpexpect.Printer{
+			httpexpect.NewDebugPrinter(t, true),
+		},
+	})
+}
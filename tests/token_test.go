@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/crypto/hash"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshToken(t *testing.T) {
+	e := authTester(t)
+	assert := assert.New(t)
+
+	userFormItem := model.UserForm{
+		Email:     "refresh@example.com",
+		FirstName: "Refresh",
+		LastName:  "Token",
+		Password:  hash.MD5String("refresh-token"),
+		Status:    model.UserStatusActive,
+	}
+	var user model.User
+	tester(t).POST(baseAPI + "/users").WithJSON(userFormItem).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &user})
+	defer func() {
+		tester(t).DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}()
+
+	captchaID, captchaCode := solveCaptcha(t, e)
+	var token model.LoginToken
+	e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+		Email:       userFormItem.Email,
+		Password:    userFormItem.Password,
+		CaptchaID:   captchaID,
+		CaptchaCode: captchaCode,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+	assert.NotEmpty(token.RefreshToken)
+
+	refresh := func(refreshToken string) *model.LoginToken {
+		var newToken model.LoginToken
+		e.POST(baseAPI + "/refresh-token").WithJSON(model.RefreshTokenForm{RefreshToken: refreshToken}).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &newToken})
+		return &newToken
+	}
+
+	// Every refresh rotates the refresh token
+	token2 := refresh(token.RefreshToken)
+	assert.NotEqual(token.RefreshToken, token2.RefreshToken)
+	token3 := refresh(token2.RefreshToken)
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", "Bearer "+token3.AccessToken).
+		Expect().Status(http.StatusOK)
+
+	// Reusing a rotated refresh token revokes the whole family
+	e.POST(baseAPI + "/refresh-token").WithJSON(model.RefreshTokenForm{RefreshToken: token2.RefreshToken}).
+		Expect().Status(http.StatusUnauthorized)
+	e.POST(baseAPI + "/refresh-token").WithJSON(model.RefreshTokenForm{RefreshToken: token3.RefreshToken}).
+		Expect().Status(http.StatusUnauthorized)
+	e.GET(baseAPI+"/current/user").WithHeader("Authorization", "Bearer "+token3.AccessToken).
+		Expect().Status(http.StatusUnauthorized)
+
+	// Logout revokes the refresh token as well
+	captchaID, captchaCode = solveCaptcha(t, e)
+	e.POST(baseAPI + "/login").WithJSON(model.LoginForm{
+		Email:       userFormItem.Email,
+		Password:    userFormItem.Password,
+		CaptchaID:   captchaID,
+		CaptchaCode: captchaCode,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &token})
+	e.POST(baseAPI+"/current/logout").WithHeader("Authorization", "Bearer "+token.AccessToken).
+		Expect().Status(http.StatusOK)
+	e.POST(baseAPI + "/refresh-token").WithJSON(model.RefreshTokenForm{RefreshToken: token.RefreshToken}).
+		Expect().Status(http.StatusUnauthorized)
+}
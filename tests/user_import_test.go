@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-admin/internal/modules/auth/model"
+	"go-admin/pkg/encoding/xlsx"
+	"go-admin/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserImportExport(t *testing.T) {
+	e := tester(t)
+	assert := assert.New(t)
+
+	var role model.Role
+	e.POST(baseAPI + "/roles").WithJSON(model.RoleForm{
+		Code:   "importer",
+		Name:   "Importer",
+		Status: model.RoleStatusEnabled,
+	}).Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &role})
+	defer func() {
+		e.DELETE(baseAPI + "/roles/" + role.ID).Expect().Status(http.StatusOK)
+	}()
+
+	importFile := func(filename string, content []byte, dryRun bool) *model.UserImportResult {
+		var result model.UserImportResult
+		e.POST(baseAPI+"/users/import").WithQuery("dry_run", dryRun).
+			WithMultipart().WithFileBytes("file", filename, content).
+			Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &result})
+		return &result
+	}
+
+	// Invalid rows are reported and nothing is created
+	invalid := "\ufeffemail,first_name,last_name,phone,status,roles\n" +
+		"import1@example.com,Import,One,,active,importer\n" +
+		"\n" +
+		"not-an-email,Import,Two,,,\n" +
+		"import1@example.com,Import,Three,,,\n" +
+		"import4@example.com,Import,Four,,disabled,\n" +
+		"import5@example.com,Import,Five,,,unknown\n"
+	result := importFile("users.csv", []byte(invalid), false)
+	assert.Equal(5, result.Total)
+	assert.Equal(0, result.Created)
+	if assert.Len(result.Errors, 4) {
+		assert.Equal(4, result.Errors[0].Row)
+		assert.Equal(5, result.Errors[1].Row)
+		assert.Equal("Duplicate email of row 2", result.Errors[1].Message)
+		assert.Equal(6, result.Errors[2].Row)
+		assert.Equal(7, result.Errors[3].Row)
+	}
+	var users model.Users
+	e.GET(baseAPI+"/users").WithQuery("email", "import1@").Expect().Status(http.StatusOK).
+		JSON().Decode(&util.ResponseResult{Data: &users})
+	assert.Empty(users)
+
+	// A dry run validates without creating
+	valid := "email,first_name,last_name,phone,status,roles\n" +
+		"import1@example.com,Import,One,13800000001,active,importer\n" +
+		"import2@example.com,Import,Two,,inactive,\n"
+	result = importFile("users.csv", []byte(valid), true)
+	assert.True(result.DryRun)
+	assert.Equal(2, result.Total)
+	assert.Empty(result.Errors)
+	assert.Equal(0, result.Created)
+
+	result = importFile("users.csv", []byte(valid), false)
+	assert.Equal(2, result.Created)
+
+	e.GET(baseAPI+"/users").WithQuery("email", "@example.com").WithQuery("full_name", "Import").
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &users})
+	defer func() {
+		for _, user := range users {
+			e.DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+		}
+	}()
+	assert.Len(users, 2)
+
+	// The existing emails are rejected
+	result = importFile("users.csv", []byte(valid), false)
+	if assert.Len(result.Errors, 2) {
+		assert.Equal("Email already exists", result.Errors[0].Message)
+	}
+
+	// Export with the filters of the user list
+	resp := e.GET(baseAPI+"/users/export").WithQuery("full_name", "Import").WithQuery("status", "active").
+		Expect().Status(http.StatusOK)
+	resp.Header("Content-Type").HasPrefix("text/csv")
+	resp.Header("Content-Disposition").HasSuffix(`.csv"`)
+	raw := resp.Body().Raw()
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(raw, "\ufeff"))).ReadAll()
+	assert.Nil(err)
+	if assert.Len(records, 2) {
+		assert.Equal(append(model.UserFileColumns, "created_at"), records[0])
+		assert.Equal([]string{"import1@example.com", "Import", "One", "13800000001", "active", "importer"}, records[1][:6])
+	}
+
+	raw = e.GET(baseAPI+"/users/export").WithQuery("full_name", "Import").WithQuery("format", "xlsx").
+		Expect().Status(http.StatusOK).Body().Raw()
+	rows, err := xlsx.ReadAll(bytes.NewReader([]byte(raw)), int64(len(raw)))
+	assert.Nil(err)
+	assert.Len(rows, 3)
+
+	// The exported file can be imported again as XLSX
+	for _, user := range users {
+		e.DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+	}
+	result = importFile("users.xlsx", []byte(raw), false)
+	assert.Empty(result.Errors)
+	assert.Equal(2, result.Created)
+	e.GET(baseAPI+"/users").WithQuery("email", "@example.com").WithQuery("full_name", "Import").
+		Expect().Status(http.StatusOK).JSON().Decode(&util.ResponseResult{Data: &users})
+	assert.Len(users, 2)
+
+	// Values spreadsheets would evaluate as formulas are exported with a leading quote, signed numbers are not,
+	// the imported values are kept as they are
+	result = importFile("users.csv", []byte("email,first_name,last_name,phone,status,roles\n"+
+		"formula@example.com,=1+2,@SUM(A1),+8613800000009,active,\n"+
+		"formula-quoted@example.com,'=foo,-1+1,-42,active,\n"), false)
+	assert.Equal(2, result.Created)
+	var formulaUsers model.Users
+	e.GET(baseAPI+"/users").WithQuery("email", "formula").Expect().Status(http.StatusOK).
+		JSON().Decode(&util.ResponseResult{Data: &formulaUsers})
+	defer func() {
+		for _, user := range formulaUsers {
+			e.DELETE(baseAPI + "/users/" + user.ID).Expect().Status(http.StatusOK)
+		}
+	}()
+	for _, user := range formulaUsers {
+		if user.Email == "formula-quoted@example.com" {
+			assert.Equal("'=foo", user.FirstName)
+			assert.Equal("-42", user.Phone)
+		}
+	}
+	raw = e.GET(baseAPI+"/users/export").WithQuery("email", "formula").Expect().Status(http.StatusOK).Body().Raw()
+	records, err = csv.NewReader(strings.NewReader(strings.TrimPrefix(raw, "\ufeff"))).ReadAll()
+	assert.Nil(err)
+	if assert.Len(records, 3) {
+		exported := map[string][]string{records[1][0]: records[1][:4], records[2][0]: records[2][:4]}
+		assert.Equal([]string{"formula@example.com", "'=1+2", "'@SUM(A1)", "+8613800000009"}, exported["formula@example.com"])
+		assert.Equal([]string{"formula-quoted@example.com", "'=foo", "'-1+1", "-42"}, exported["formula-quoted@example.com"])
+	}
+
+	e.POST(baseAPI+"/users/import").WithMultipart().WithFileBytes("file", "users.txt", []byte(valid)).
+		Expect().Status(http.StatusBadRequest)
+	e.GET(baseAPI+"/users/export").WithQuery("format", "pdf").Expect().Status(http.StatusBadRequest)
+}